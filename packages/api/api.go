@@ -64,6 +64,8 @@ type apiData struct {
 	vde         bool
 	vm          *script.VM
 	token       *jwt.Token
+	service     *model.APIToken // the scoped service token of the request
+	route       string          // "METHOD pattern" of the route
 }
 
 // ParamString reaturs string value of the api params
@@ -172,25 +174,32 @@ func getHeader(txName string, data *apiData) (tx.Header, error) {
 // DefaultHandler is a common handle function for api requests
 func DefaultHandler(method, pattern string, params map[string]int, handlers ...apiHandle) hr.Handle {
 
-	return hr.Handle(func(w http.ResponseWriter, r *http.Request, ps hr.Params) {
+	group := routeGroup(method, pattern)
+	return hr.Handle(func(rw http.ResponseWriter, r *http.Request, ps hr.Params) {
 		counterName := statsd.APIRouteCounterName(method, pattern)
-		statsd.Client.Inc(counterName+statsd.Count, 1, 1.0)
+		statsd.Client().Inc(counterName+statsd.Count, 1, 1.0)
 		startTime := time.Now()
+		w := &statusWriter{ResponseWriter: rw}
 		var (
 			err  error
 			data apiData
 		)
-		requestLogger := log.WithFields(log.Fields{"headers": r.Header, "path": r.URL.Path, "protocol": r.Proto, "remote": r.RemoteAddr})
+		requestID := getRequestID(r)
+		w.Header().Set(requestIDHeader, requestID)
+		requestLogger := log.WithFields(log.Fields{"headers": r.Header, "path": r.URL.Path, "protocol": r.Proto, "remote": r.RemoteAddr,
+			"request_id": requestID})
 		requestLogger.Info("received http request")
 
 		defer func() {
 			endTime := time.Now()
-			statsd.Client.TimingDuration(counterName+statsd.Time, endTime.Sub(startTime), 1.0)
+			statsd.Client().TimingDuration(counterName+statsd.Time, endTime.Sub(startTime), 1.0)
 			if r := recover(); r != nil {
 				requestLogger.WithFields(log.Fields{"type": consts.PanicRecoveredError, "error": r, "stack": string(debug.Stack())}).Error("panic recovered error")
 				fmt.Println("API Recovered", fmt.Sprintf("%s: %s", r, debug.Stack()))
 				errorAPI(w, `E_RECOVERED`, http.StatusInternalServerError)
 			}
+			apiRequests.With(method, pattern, w.code()).Inc()
+			apiDuration.With(method, pattern).Observe(endTime.Sub(startTime).Seconds())
 		}()
 
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -207,8 +216,17 @@ func DefaultHandler(method, pattern string, params map[string]int, handlers ...a
 			}
 		}
 
-		token, err := jwtToken(r)
-		if err != nil {
+		data.route = method + ` ` + pattern
+		if data.service, err = serviceToken(r); err != nil {
+			requestLogger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Error("checking service token")
+			errorAPI(w, `E_UNAUTHORIZED`, http.StatusUnauthorized)
+			return
+		}
+		var token *jwt.Token
+		if data.service != nil {
+			data.ecosystemId = data.service.EcosystemID
+			data.keyId = data.service.KeyID
+		} else if token, err = jwtToken(r); err != nil {
 			requestLogger.WithFields(log.Fields{"type": consts.JWTError, "params": params, "error": err}).Error("starting session")
 			errmsg := err.Error()
 			expired := `token is expired by`
@@ -226,12 +244,19 @@ func DefaultHandler(method, pattern string, params map[string]int, handlers ...a
 				data.keyId = converter.StrToInt64(claims.KeyID)
 			}
 		}
+		if checkRateLimit(w, r, group, &data, requestLogger) != nil {
+			return
+		}
 		// Getting and validating request parameters
 		r.ParseForm()
 		data.params = make(map[string]interface{})
 		for _, par := range ps {
 			data.params[par.Key] = par.Value
 		}
+		// the service token is checked for every route because it sets the account of the request
+		if data.service != nil && checkServiceToken(w, &data, requestLogger) != nil {
+			return
+		}
 		vde := r.FormValue(`vde`)
 		if vde == `1` || vde == `true` {
 			data.vm = smart.GetVM(true, data.ecosystemId)
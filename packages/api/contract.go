@@ -40,8 +40,9 @@ import (
 type contractResult struct {
 	Hash string `json:"hash"`
 	// These fields are used for VDE
-	Message *txstatusError `json:"errmsg,omitempty"`
-	Result  string         `json:"result,omitempty"`
+	Message *txstatusError     `json:"errmsg,omitempty"`
+	Result  string             `json:"result,omitempty"`
+	HTTP    []model.HTTPRecord `json:"http,omitempty"`
 }
 
 func contract(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
@@ -162,6 +163,7 @@ func contract(w http.ResponseWriter, r *http.Request, data *apiData, logger *log
 		append([]byte{128}, serializedData...)); err != nil {
 		return errorAPI(w, err, http.StatusInternalServerError)
 	}
+	logger.WithFields(log.Fields{"tx_hash": hex.EncodeToString(hash)}).Info("transaction has been sent")
 	data.result = &contractResult{Hash: hex.EncodeToString(hash)}
 	return nil
 }
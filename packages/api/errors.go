@@ -24,33 +24,38 @@ package api
 
 var (
 	apiErrors = map[string]string{
-		`E_CONTRACT`:      `There is not %s contract`,
-		`E_DBNIL`:         `DB is nil`,
-		`E_ECOSYSTEM`:     `Ecosystem %d doesn't exist`,
-		`E_EMPTYPUBLIC`:   `Public key is undefined`,
-		`E_EMPTYSIGN`:     `Signature is undefined`,
-		`E_HASHWRONG`:     `Hash is incorrect`,
-		`E_HASHNOTFOUND`:  `Hash has not been found`,
-		`E_HEAVYPAGE`:     `This page is heavy`,
-		`E_INSTALLED`:     `Apla is already installed`,
-		`E_INVALIDWALLET`: `Wallet %s is not valid`,
-		`E_NOTFOUND`:      `Page not found`,
-		`E_NOTINSTALLED`:  `Apla is not installed`,
-		`E_PERMISSION`:    `Permission denied`,
-		`E_QUERY`:         `DB query is wrong`,
-		`E_RECOVERED`:     `API recovered`,
-		`E_REFRESHTOKEN`:  `Refresh token is not valid`,
-		`E_SERVER`:        `Server error`,
-		`E_SIGNATURE`:     `Signature is incorrect`,
-		`E_UNKNOWNSIGN`:   `Unknown signature`,
-		`E_STATELOGIN`:    `%s is not a membership of ecosystem %s`,
-		`E_TABLENOTFOUND`: `Table %s has not been found`,
-		`E_TOKEN`:         `Token is not valid`,
-		`E_TOKENEXPIRED`:  `Token is expired by %s`,
-		`E_UNAUTHORIZED`:  `Unauthorized`,
-		`E_UNDEFINEVAL`:   `Value %s is undefined`,
-		`E_UNKNOWNUID`:    `Unknown uid`,
-		`E_VDE`:           `Virtual Dedicated Ecosystem %d doesn't exist`,
-		`E_VDECREATED`:    `Virtual Dedicated Ecosystem is already created`,
+		`E_COLUMNNOTFOUND`: `Column %s has not been found`,
+		`E_CONTRACT`:       `There is not %s contract`,
+		`E_DBNIL`:          `DB is nil`,
+		`E_ECOSYSTEM`:      `Ecosystem %d doesn't exist`,
+		`E_EMPTYPUBLIC`:    `Public key is undefined`,
+		`E_EMPTYSIGN`:      `Signature is undefined`,
+		`E_FILTER`:         `Query of list is not valid: %s`,
+		`E_HASHWRONG`:      `Hash is incorrect`,
+		`E_HASHNOTFOUND`:   `Hash has not been found`,
+		`E_HEAVYPAGE`:      `This page is heavy`,
+		`E_INSTALLED`:      `Apla is already installed`,
+		`E_INVALIDWALLET`:  `Wallet %s is not valid`,
+		`E_LIMIT`:          `Too many requests, retry after %d seconds`,
+		`E_NOTFOUND`:       `Page not found`,
+		`E_NOTINSTALLED`:   `Apla is not installed`,
+		`E_PERMISSION`:     `Permission denied`,
+		`E_PRUNED`:         `State of block %d has been pruned`,
+		`E_QUERY`:          `DB query is wrong`,
+		`E_RECOVERED`:      `API recovered`,
+		`E_REFRESHTOKEN`:   `Refresh token is not valid`,
+		`E_SERVER`:         `Server error`,
+		`E_SCOPE`:          `Service token does not allow %s`,
+		`E_SIGNATURE`:      `Signature is incorrect`,
+		`E_UNKNOWNSIGN`:    `Unknown signature`,
+		`E_STATELOGIN`:     `%s is not a membership of ecosystem %s`,
+		`E_TABLENOTFOUND`:  `Table %s has not been found`,
+		`E_TOKEN`:          `Token is not valid`,
+		`E_TOKENEXPIRED`:   `Token is expired by %s`,
+		`E_UNAUTHORIZED`:   `Unauthorized`,
+		`E_UNDEFINEVAL`:    `Value %s is undefined`,
+		`E_UNKNOWNUID`:     `Unknown uid`,
+		`E_VDE`:            `Virtual Dedicated Ecosystem %d doesn't exist`,
+		`E_VDECREATED`:     `Virtual Dedicated Ecosystem is already created`,
 	}
 )
@@ -0,0 +1,255 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/parser"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type explorerChange struct {
+	Table string `json:"table"`
+	ID    string `json:"id"`
+	// Prev contains the previous values of the changed columns, it is empty for the inserted row
+	Prev json.RawMessage `json:"prev,omitempty"`
+}
+
+type explorerTx struct {
+	Hash      string           `json:"hash"`
+	Type      int64            `json:"type"`
+	Contract  string           `json:"contract"`
+	Params    interface{}      `json:"params,omitempty"`
+	Time      int64            `json:"time"`
+	KeyID     string           `json:"key_id"`
+	Address   string           `json:"address"`
+	PublicKey string           `json:"public_key,omitempty"`
+	Ecosystem int64            `json:"ecosystem"`
+	Fuel      string           `json:"fuel,omitempty"`
+	Result    string           `json:"result,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Changes   []explorerChange `json:"changes"`
+}
+
+type explorerBlock struct {
+	ID            int64        `json:"id"`
+	Hash          string       `json:"hash"`
+	RollbacksHash string       `json:"rollbacks_hash"`
+	Time          int64        `json:"time"`
+	Version       int          `json:"version"`
+	EcosystemID   int64        `json:"ecosystem_id"`
+	KeyID         string       `json:"key_id"`
+	Address       string       `json:"address"`
+	NodePosition  int64        `json:"node_position"`
+	Sign          string       `json:"sign"`
+	Tx            []explorerTx `json:"tx"`
+}
+
+// splitBlockData returns the header and the binary transactions of the block
+func splitBlockData(data []byte) (utils.BlockData, [][]byte, error) {
+	buf := bytes.NewBuffer(data)
+	header, err := parser.ParseBlockHeader(buf)
+	if err != nil {
+		return header, nil, err
+	}
+	txs := make([][]byte, 0)
+	for buf.Len() > 0 {
+		size, err := converter.DecodeLengthBuf(buf)
+		if err != nil {
+			return header, nil, err
+		}
+		if size == 0 || buf.Len() < int(size) {
+			return header, nil, fmt.Errorf(`bad block format (transaction length %d)`, size)
+		}
+		txs = append(txs, buf.Next(int(size)))
+	}
+	return header, txs, nil
+}
+
+// decodeTx decodes the binary transaction like the parser does it when the block is played
+func decodeTx(data []byte) explorerTx {
+	var item explorerTx
+	p, err := parser.ParseTransaction(bytes.NewBuffer(data))
+	if p == nil {
+		// the hash is calculated the same way as ParseTransaction does
+		if hash, herr := crypto.Hash(data); herr == nil {
+			item.Hash = hex.EncodeToString(hash)
+		}
+		if len(data) > 0 {
+			item.Type = int64(data[0])
+		}
+		item.Error = err.Error()
+		return item
+	}
+	item.Hash = hex.EncodeToString(p.TxHash)
+	if err != nil {
+		item.Error = err.Error()
+	}
+	if p.TxSmart != nil {
+		item.Type = int64(p.TxSmart.Type)
+		item.Ecosystem = p.TxSmart.EcosystemID
+		item.PublicKey = hex.EncodeToString(p.TxSmart.PublicKey)
+		if p.TxContract != nil {
+			item.Contract = p.TxContract.Name
+		}
+		params := make(map[string]interface{})
+		for key, val := range p.TxData {
+			if key != `forsign` {
+				params[key] = val
+			}
+		}
+		item.Params = params
+	} else {
+		item.Type = p.TxType
+		item.Ecosystem = p.TxEcosystemID
+		item.Contract = consts.TxTypes[int(p.TxType)]
+		item.Params = p.TxPtr
+	}
+	item.Time = p.TxTime
+	item.KeyID = converter.Int64ToStr(p.TxKeyID)
+	item.Address = converter.AddressToString(p.TxKeyID)
+	return item
+}
+
+// explorerChanges groups the rollback records of the block by the transaction hash
+func explorerChanges(list []model.RollbackTx) map[string][]explorerChange {
+	changes := make(map[string][]explorerChange)
+	for _, rtx := range list {
+		change := explorerChange{Table: rtx.NameTable, ID: rtx.TableID}
+		if len(rtx.Data) > 0 {
+			change.Prev = json.RawMessage(rtx.Data)
+		}
+		hash := hex.EncodeToString(rtx.TxHash)
+		changes[hash] = append(changes[hash], change)
+	}
+	return changes
+}
+
+func decodeBlock(blockID int64, logger *log.Entry) (*explorerBlock, error) {
+	block := &model.Block{}
+	found, err := block.Get(blockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": blockID}).Error("getting block")
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	header, txs, err := splitBlockData(block.Data)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err, "block_id": blockID}).Error("parsing block data")
+		return nil, err
+	}
+	rollbacks, err := (&model.RollbackTx{}).GetBlockRollbackTransactions(nil, blockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": blockID}).Error("getting rollback transactions of block")
+		return nil, err
+	}
+	changes := explorerChanges(rollbacks)
+	result := &explorerBlock{ID: block.ID, Hash: hex.EncodeToString(block.Hash),
+		RollbacksHash: hex.EncodeToString(block.RollbacksHash), Time: block.Time,
+		Version: header.Version, EcosystemID: block.EcosystemID, KeyID: converter.Int64ToStr(block.KeyID),
+		Address: converter.AddressToString(block.KeyID), NodePosition: block.NodePosition,
+		Sign: hex.EncodeToString(header.Sign), Tx: make([]explorerTx, len(txs))}
+	for i, data := range txs {
+		item := decodeTx(data)
+		th := &model.TxHistory{}
+		if hash, herr := hex.DecodeString(item.Hash); herr == nil {
+			if found, err := th.GetByHash(hash); err != nil {
+				logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting transaction history by hash")
+				return nil, err
+			} else if found {
+				if th.Fuel.Sign() > 0 {
+					item.Fuel = th.Fuel.String()
+				}
+				item.Result = th.Result
+				if len(th.Error) > 0 {
+					item.Error = th.Error
+				}
+			}
+		}
+		item.Changes = changes[item.Hash]
+		if item.Changes == nil {
+			item.Changes = []explorerChange{}
+		}
+		result.Tx[i] = item
+	}
+	return result, nil
+}
+
+// explorerBlockInfo returns the block with the decoded transactions
+func explorerBlockInfo(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	blockID := converter.StrToInt64(data.ParamString(`id`))
+	block, err := decodeBlock(blockID, logger)
+	if err != nil {
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	if block == nil {
+		return errorAPI(w, `E_NOTFOUND`, http.StatusNotFound)
+	}
+	data.result = block
+	return nil
+}
+
+// explorerTxInfo returns the decoded transaction which has been written in the block
+func explorerTxInfo(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	hash, err := hex.DecodeString(data.ParamString(`hash`))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding tx hash from hex")
+		return errorAPI(w, `E_HASHWRONG`, http.StatusBadRequest)
+	}
+	ts := &model.TransactionStatus{}
+	found, err := ts.Get(hash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting transaction status by hash")
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+	}
+	if !found || ts.BlockID == 0 {
+		return errorAPI(w, `E_HASHNOTFOUND`, http.StatusNotFound)
+	}
+	block, err := decodeBlock(ts.BlockID, logger)
+	if err != nil {
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	if block != nil {
+		txHash := hex.EncodeToString(hash)
+		for i := range block.Tx {
+			if block.Tx[i].Hash == txHash {
+				data.result = &block.Tx[i]
+				return nil
+			}
+		}
+	}
+	return errorAPI(w, `E_HASHNOTFOUND`, http.StatusNotFound)
+}
@@ -0,0 +1,50 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/model"
+)
+
+func TestExplorerChanges(t *testing.T) {
+	changes := explorerChanges([]model.RollbackTx{
+		{TxHash: []byte{1}, NameTable: `1_pages`, TableID: `5`, Data: `{"value":"old"}`},
+		{TxHash: []byte{1}, NameTable: `1_keys`, TableID: `-1`},
+		{TxHash: []byte{2}, NameTable: `1_menu`, TableID: `3`},
+	})
+	if len(changes) != 2 || len(changes[`01`]) != 2 || len(changes[`02`]) != 1 {
+		t.Fatalf(`wrong changes %v`, changes)
+	}
+	if string(changes[`01`][0].Prev) != `{"value":"old"}` || changes[`01`][1].Prev != nil {
+		t.Errorf(`wrong previous values %+v`, changes[`01`])
+	}
+}
+
+func TestDecodeBadTx(t *testing.T) {
+	item := decodeTx([]byte{200, 1})
+	if item.Type != 200 || len(item.Hash) != 64 || len(item.Error) == 0 {
+		t.Errorf(`wrong decoded tx %+v`, item)
+	}
+}
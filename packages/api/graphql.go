@@ -0,0 +1,510 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	"github.com/graphql-go/graphql"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	gqlDefaultLimit = 25
+	gqlMaxLimit     = 1000
+)
+
+var regGraphQLName = regexp.MustCompile(`^[_a-z][_a-z0-9]*$`)
+
+// graphqlResult is the answer of graphql route, it is used for OpenAPI specification
+type graphqlResult struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []graphqlError         `json:"errors,omitempty"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+type graphqlQuery struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+	Operation string                 `json:"operationName"`
+}
+
+// gqlRef is the column which refers to the id of other table
+type gqlRef struct {
+	column string
+	table  string
+}
+
+// gqlTable is the table of the ecosystem in GraphQL schema
+type gqlTable struct {
+	name    string
+	columns map[string]string // the data types of the columns
+	refs    map[string]gqlRef // the fields of nested lookups
+	object  *graphql.Object
+}
+
+type gqlRow struct {
+	values  map[string]string
+	allowed map[string]bool
+}
+
+type gqlPage struct {
+	table  *gqlTable
+	query  *listQuery
+	cursor string
+	list   []*gqlRow
+}
+
+type gqlContextKey struct{}
+
+// gqlRequest is the context of GraphQL request, it caches the permissions and the rows
+type gqlRequest struct {
+	sync.Mutex
+	prefix  string
+	sc      *smart.SmartContract
+	logger  *log.Entry
+	allowed map[string]map[string]bool
+	rows    map[string]*gqlRow
+}
+
+// gqlSchema is the cached schema of the ecosystem with the hash of the tables it has been built from
+type gqlSchema struct {
+	hash   string
+	schema *graphql.Schema
+}
+
+var gqlSchemas = struct {
+	sync.Mutex
+	schemas map[string]gqlSchema
+}{schemas: make(map[string]gqlSchema)}
+
+// tablesHash returns the hash of the committed declarations of tables of the ecosystem.
+// It is changed when tables or columns are created and when they are rolled back
+func tablesHash(prefix string) (string, error) {
+	row, err := model.GetOneRow(`select md5(coalesce(string_agg(name || ':' || coalesce(columns::text, ''), ','
+		order by name), '')) as hash from "` + prefix + `_tables"`).String()
+	if err != nil {
+		return ``, err
+	}
+	return row[`hash`], nil
+}
+
+// graphqlSchema returns the schema of the ecosystem, the schema is generated again
+// when the declarations of tables have been changed
+func graphqlSchema(prefix string) (*graphql.Schema, error) {
+	hash, err := tablesHash(prefix)
+	if err != nil {
+		return nil, err
+	}
+	gqlSchemas.Lock()
+	defer gqlSchemas.Unlock()
+	if cached, ok := gqlSchemas.schemas[prefix]; ok && cached.hash == hash {
+		return cached.schema, nil
+	}
+	tables, err := loadGraphQLTables(prefix)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := buildGraphQLSchema(tables)
+	if err != nil {
+		return nil, err
+	}
+	gqlSchemas.schemas[prefix] = gqlSchema{hash: hash, schema: schema}
+	return schema, nil
+}
+
+// loadGraphQLTables reads the tables of the ecosystem and the types of the columns
+func loadGraphQLTables(prefix string) ([]*gqlTable, error) {
+	rows, err := model.GetAll(`select name, columns from "`+prefix+`_tables" order by name`, -1)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]*gqlTable, 0, len(rows))
+	for _, row := range rows {
+		if !regGraphQLName.MatchString(row[`name`]) {
+			continue
+		}
+		var perm map[string]string
+		if err = json.Unmarshal([]byte(row[`columns`]), &perm); err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err, "table": row[`name`]}).Error("unmarshalling columns of table")
+			continue
+		}
+		types, err := model.GetTableColumns(prefix + `_` + row[`name`])
+		if err != nil {
+			return nil, err
+		}
+		table := &gqlTable{name: row[`name`], columns: map[string]string{`id`: types[`id`]}}
+		for column := range perm {
+			if dataType, ok := types[column]; ok && regGraphQLName.MatchString(column) {
+				table.columns[column] = dataType
+			}
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// findRefs finds the columns which refer to the ids of tables, e.g. member_id refers to members
+func findRefs(tables []*gqlTable) {
+	names := make(map[string]bool)
+	for _, table := range tables {
+		names[table.name] = true
+	}
+	for _, table := range tables {
+		table.refs = make(map[string]gqlRef)
+		for column, dataType := range table.columns {
+			if dataType != `bigint` || !strings.HasSuffix(column, `_id`) || column == `id` {
+				continue
+			}
+			base := strings.TrimSuffix(column, `_id`)
+			field := base
+			if _, ok := table.columns[field]; ok {
+				field += `_ref`
+			}
+			if _, ok := table.columns[field]; ok {
+				continue
+			}
+			for _, name := range []string{base, base + `s`, base + `es`} {
+				if names[name] {
+					table.refs[field] = gqlRef{column: column, table: name}
+					break
+				}
+			}
+		}
+	}
+}
+
+func gqlColumnType(dataType string) graphql.Output {
+	switch dataType {
+	case `integer`, `smallint`:
+		return graphql.Int
+	case `double precision`, `real`:
+		return graphql.Float
+	case `boolean`:
+		return graphql.Boolean
+	}
+	return graphql.String
+}
+
+func gqlValue(dataType, value string) interface{} {
+	switch gqlColumnType(dataType) {
+	case graphql.Int:
+		v, _ := strconv.Atoi(value)
+		return v
+	case graphql.Float:
+		v, _ := strconv.ParseFloat(value, 64)
+		return v
+	case graphql.Boolean:
+		return value == `true`
+	}
+	return value
+}
+
+func gqlContext(p graphql.ResolveParams) *gqlRequest {
+	return p.Context.Value(gqlContextKey{}).(*gqlRequest)
+}
+
+func columnResolver(column, dataType string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		row := p.Source.(*gqlRow)
+		if !row.allowed[column] {
+			return nil, fmt.Errorf(`access denied to column %s`, column)
+		}
+		value, ok := row.values[column]
+		if !ok || value == `NULL` {
+			return nil, nil
+		}
+		return gqlValue(dataType, value), nil
+	}
+}
+
+func refResolver(ref gqlRef, tables map[string]*gqlTable) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		row := p.Source.(*gqlRow)
+		if !row.allowed[ref.column] {
+			return nil, fmt.Errorf(`access denied to column %s`, ref.column)
+		}
+		id := row.values[ref.column]
+		if len(id) == 0 || id == `0` || id == `NULL` {
+			return nil, nil
+		}
+		return gqlContext(p).row(tables[ref.table], id)
+	}
+}
+
+var gqlListArgs = graphql.FieldConfigArgument{
+	`where`:  &graphql.ArgumentConfig{Type: graphql.String, Description: `filter in JSON as in list route`},
+	`order`:  &graphql.ArgumentConfig{Type: graphql.String, Description: `comma separated columns, -column is descending`},
+	`cursor`: &graphql.ArgumentConfig{Type: graphql.String},
+	`limit`:  &graphql.ArgumentConfig{Type: graphql.Int},
+	`offset`: &graphql.ArgumentConfig{Type: graphql.Int},
+}
+
+// listField returns the field of the paged list of the table
+func listField(table *gqlTable, tables map[string]*gqlTable) *graphql.Field {
+	page := graphql.NewObject(graphql.ObjectConfig{
+		Name: `List_` + table.name,
+		Fields: graphql.Fields{
+			`count`: &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return gqlContext(p).count(p.Source.(*gqlPage))
+			}},
+			`cursor`: &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*gqlPage).cursor, nil
+			}},
+			`list`: &graphql.Field{Type: graphql.NewList(table.object), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*gqlPage).list, nil
+			}},
+		},
+	})
+	return &graphql.Field{Type: page, Args: gqlListArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return gqlContext(p).list(table, p.Args)
+	}}
+}
+
+// buildGraphQLSchema generates the schema with the list and the row fields of the tables
+func buildGraphQLSchema(list []*gqlTable) (*graphql.Schema, error) {
+	findRefs(list)
+	tables := make(map[string]*gqlTable)
+	for _, table := range list {
+		tables[table.name] = table
+	}
+	for _, table := range list {
+		table := table
+		table.object = graphql.NewObject(graphql.ObjectConfig{
+			Name: table.name,
+			Fields: graphql.FieldsThunk(func() graphql.Fields {
+				fields := graphql.Fields{}
+				for column, dataType := range table.columns {
+					fields[column] = &graphql.Field{Type: gqlColumnType(dataType), Resolve: columnResolver(column, dataType)}
+				}
+				for field, ref := range table.refs {
+					fields[field] = &graphql.Field{Type: tables[ref.table].object, Resolve: refResolver(ref, tables)}
+				}
+				return fields
+			}),
+		})
+	}
+	fields := graphql.Fields{}
+	for _, table := range list {
+		table := table
+		fields[table.name] = listField(table, tables)
+		if _, ok := tables[table.name+`_by_id`]; !ok {
+			fields[table.name+`_by_id`] = &graphql.Field{Type: table.object,
+				Args: graphql.FieldConfigArgument{`id`: &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return gqlContext(p).row(table, p.Args[`id`].(string))
+				}}
+		}
+	}
+	if len(fields) == 0 {
+		fields[`tables`] = &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return 0, nil
+		}}
+	}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: `Query`, Fields: fields}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// allowedColumns checks the read permissions of the table and returns the columns which can be read
+func (req *gqlRequest) allowedColumns(table string) (map[string]bool, error) {
+	req.Lock()
+	defer req.Unlock()
+	if allowed, ok := req.allowed[table]; ok {
+		return allowed, nil
+	}
+	name := req.prefix + `_` + table
+	if _, err := req.sc.AccessTablePerm(name, `read`); err != nil {
+		req.logger.WithFields(log.Fields{"type": consts.AccessDenied, "table": name, "error": err}).Warning("reading table is denied")
+		return nil, fmt.Errorf(`access denied to table %s`, table)
+	}
+	columns := []string{`*`}
+	if err := req.sc.AccessColumns(name, &columns, false); err != nil {
+		req.logger.WithFields(log.Fields{"type": consts.AccessDenied, "table": name, "error": err}).Warning("reading columns is denied")
+		return nil, fmt.Errorf(`access denied to table %s`, table)
+	}
+	allowed := map[string]bool{`id`: true}
+	for _, column := range columns {
+		allowed[column] = true
+	}
+	req.allowed[table] = allowed
+	return allowed, nil
+}
+
+func selectColumns(table *gqlTable, allowed map[string]bool) string {
+	columns := make([]string, 0, len(allowed))
+	for column := range allowed {
+		if _, ok := table.columns[column]; ok {
+			columns = append(columns, `"`+column+`"`)
+		}
+	}
+	sort.Strings(columns)
+	return strings.Join(columns, `,`)
+}
+
+func (req *gqlRequest) list(table *gqlTable, args map[string]interface{}) (*gqlPage, error) {
+	where, _ := args[`where`].(string)
+	order, _ := args[`order`].(string)
+	cursor, _ := args[`cursor`].(string)
+	query, err := newListQuery(where, order, cursor)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := req.allowedColumns(table.name)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range query.columns() {
+		if _, ok := table.columns[column]; !ok {
+			return nil, fmt.Errorf(`column %s has not been found`, column)
+		}
+		if !allowed[column] {
+			return nil, fmt.Errorf(`access denied to column %s`, column)
+		}
+	}
+	limit, _ := args[`limit`].(int)
+	if limit <= 0 {
+		limit = gqlDefaultLimit
+	} else if limit > gqlMaxLimit {
+		limit = gqlMaxLimit
+	}
+	cond, values := query.where(true)
+	sql := `select ` + selectColumns(table, allowed) + ` from "` + req.prefix + `_` + table.name + `"` + cond + query.orderBy()
+	if offset, _ := args[`offset`].(int); offset > 0 && len(query.cursor) == 0 {
+		sql += fmt.Sprintf(` offset %d`, offset)
+	}
+	rows, err := model.GetAll(sql, limit, values...)
+	if err != nil {
+		req.logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table.name}).Error("selecting rows for graphql")
+		return nil, fmt.Errorf(`DB query is wrong`)
+	}
+	page := &gqlPage{table: table, query: query, list: make([]*gqlRow, len(rows))}
+	for i, row := range rows {
+		page.list[i] = &gqlRow{values: row, allowed: allowed}
+	}
+	if len(rows) == limit {
+		page.cursor = query.nextCursor(rows[len(rows)-1])
+	}
+	return page, nil
+}
+
+func (req *gqlRequest) count(page *gqlPage) (string, error) {
+	cond, values := page.query.where(false)
+	count, err := model.Single(`select count(*) from "`+req.prefix+`_`+page.table.name+`"`+cond, values...).Int64()
+	if err != nil {
+		req.logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": page.table.name}).Error("counting rows for graphql")
+		return ``, fmt.Errorf(`DB query is wrong`)
+	}
+	return converter.Int64ToStr(count), nil
+}
+
+func (req *gqlRequest) row(table *gqlTable, id string) (*gqlRow, error) {
+	allowed, err := req.allowedColumns(table.name)
+	if err != nil {
+		return nil, err
+	}
+	key := table.name + `/` + id
+	req.Lock()
+	row, ok := req.rows[key]
+	req.Unlock()
+	if ok {
+		return row, nil
+	}
+	values, err := model.GetOneRow(`select `+selectColumns(table, allowed)+` from "`+req.prefix+`_`+table.name+
+		`" where id = ?`, converter.StrToInt64(id)).String()
+	if err != nil {
+		req.logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table.name}).Error("selecting row for graphql")
+		return nil, fmt.Errorf(`DB query is wrong`)
+	}
+	if len(values) > 0 {
+		row = &gqlRow{values: values, allowed: allowed}
+	}
+	req.Lock()
+	req.rows[key] = row
+	req.Unlock()
+	return row, nil
+}
+
+// graphQL executes GraphQL query over the tables of the ecosystem. The query can be passed
+// by the parameters or in JSON body
+func graphQL(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	query := graphqlQuery{Query: data.ParamString(`query`), Operation: data.ParamString(`operation`)}
+	if variables := data.ParamString(`variables`); len(variables) > 0 {
+		if err := json.Unmarshal([]byte(variables), &query.Variables); err != nil {
+			logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling graphql variables")
+			return errorAPI(w, err, http.StatusBadRequest)
+		}
+	}
+	if len(query.Query) == 0 && strings.HasPrefix(r.Header.Get(`Content-Type`), `application/json`) {
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling graphql request")
+			return errorAPI(w, err, http.StatusBadRequest)
+		}
+	}
+	if len(query.Query) == 0 {
+		return errorAPI(w, `E_UNDEFINEVAL`, http.StatusBadRequest, `query`)
+	}
+	prefix := getPrefix(data)
+	schema, err := graphqlSchema(prefix)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "prefix": prefix}).Error("generating graphql schema")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	req := &gqlRequest{
+		prefix: prefix,
+		sc: &smart.SmartContract{VDE: data.vde, VM: data.vm,
+			TxSmart: tx.SmartContract{Header: tx.Header{EcosystemID: data.ecosystemId, KeyID: data.keyId}}},
+		logger:  logger,
+		allowed: make(map[string]map[string]bool),
+		rows:    make(map[string]*gqlRow),
+	}
+	data.result = graphql.Do(graphql.Params{
+		Schema:         *schema,
+		RequestString:  query.Query,
+		VariableValues: query.Variables,
+		OperationName:  query.Operation,
+		Context:        context.WithValue(r.Context(), gqlContextKey{}, req),
+	})
+	return nil
+}
@@ -0,0 +1,79 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestGraphQLSchema(t *testing.T) {
+	tables := []*gqlTable{
+		{name: `members`, columns: map[string]string{`id`: `bigint`, `name`: `character varying`, `rating`: `double precision`}},
+		{name: `orders`, columns: map[string]string{`id`: `bigint`, `member_id`: `bigint`, `key_id`: `bigint`,
+			`amount`: `numeric`, `paid`: `boolean`}},
+		{name: `keys`, columns: map[string]string{`id`: `bigint`, `amount`: `numeric`}},
+	}
+	schema, err := buildGraphQLSchema(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := tables[1].refs[`member`]; ref.column != `member_id` || ref.table != `members` {
+		t.Errorf(`wrong reference %v`, tables[1].refs)
+	}
+	if ref := tables[1].refs[`key`]; ref.table != `keys` {
+		t.Errorf(`wrong reference %v`, tables[1].refs)
+	}
+	result := graphql.Do(graphql.Params{Schema: *schema,
+		RequestString: `{ __type(name: "orders") { fields { name type { name } } } }`})
+	if len(result.Errors) > 0 {
+		t.Fatal(result.Errors)
+	}
+	out, _ := json.Marshal(result.Data)
+	var data struct {
+		Type struct {
+			Fields []struct {
+				Name string
+				Type struct{ Name string }
+			}
+		} `json:"__type"`
+	}
+	json.Unmarshal(out, &data)
+	fields := make([]string, 0)
+	for _, field := range data.Type.Fields {
+		fields = append(fields, field.Name+`:`+field.Type.Name)
+	}
+	sort.Strings(fields)
+	want := `[amount:String id:String key:keys key_id:String member:members member_id:String paid:Boolean]`
+	if got := fmt.Sprint(fields); got != want {
+		t.Errorf(`wrong fields %s`, got)
+	}
+	result = graphql.Do(graphql.Params{Schema: *schema, RequestString: `{ members(where: 1) { count } }`})
+	if len(result.Errors) == 0 {
+		t.Error(`wrong argument must be rejected`)
+	}
+}
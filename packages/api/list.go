@@ -30,28 +30,81 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type listResult struct {
-	Count string              `json:"count"`
-	List  []map[string]string `json:"list"`
+	Count     string              `json:"count,omitempty"`
+	Estimated bool                `json:"estimated,omitempty"`
+	Cursor    string              `json:"cursor,omitempty"` // the cursor of the next page
+	List      []map[string]string `json:"list"`
+}
+
+// checkListColumns checks that the columns of the filters and the sorting exist and can be read
+func checkListColumns(w http.ResponseWriter, data *apiData, table string, columns []string, logger *log.Entry) error {
+	if len(columns) == 1 && columns[0] == `id` {
+		return nil
+	}
+	tableColumns, err := model.GetTableColumns(table)
+	if err != nil {
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+	}
+	if len(tableColumns) == 0 {
+		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, data.params[`name`].(string))
+	}
+	for _, column := range columns {
+		if _, ok := tableColumns[column]; !ok {
+			return errorAPI(w, `E_COLUMNNOTFOUND`, http.StatusBadRequest, column)
+		}
+	}
+	sc := smart.SmartContract{VDE: data.vde, VM: data.vm,
+		TxSmart: tx.SmartContract{Header: tx.Header{EcosystemID: data.ecosystemId, KeyID: data.keyId}}}
+	allowed := append([]string{}, columns...)
+	if err = sc.AccessColumns(table, &allowed, false); err != nil {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": err, "table": table}).Error("checking read access of columns")
+		return errorAPI(w, `E_PERMISSION`, http.StatusForbidden)
+	}
+	if len(allowed) < len(columns) {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "table": table, "columns": columns}).Error("reading columns is denied")
+		return errorAPI(w, `E_PERMISSION`, http.StatusForbidden)
+	}
+	return nil
 }
 
 func list(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) (err error) {
 	var limit int
 
 	table := converter.EscapeName(getPrefix(data) + `_` + data.params[`name`].(string))
+	query, err := newListQuery(data.ParamString(`where`), data.ParamString(`order`), data.ParamString(`cursor`))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("parsing list query")
+		return errorAPI(w, `E_FILTER`, http.StatusBadRequest, err.Error())
+	}
+	if err = checkListColumns(w, data, strings.Trim(table, `"`), query.columns(), logger); err != nil {
+		return err
+	}
+	blockID := data.ParamInt64(`block`)
+	if blockID > 0 {
+		if err = checkPrunedBlock(w, blockID, logger); err != nil {
+			return err
+		}
+		// the rows are restored after selecting so only id can be used for filtering and sorting
+		for _, column := range query.columns() {
+			if column != `id` {
+				return errorAPI(w, `E_FILTER`, http.StatusBadRequest, `only id can be used in where and order with block`)
+			}
+		}
+		query.addCondition(model.NotInsertedAfterBlock(strings.Trim(table, `"`), blockID))
+	}
 	cols := `*`
 	if len(data.params[`columns`].(string)) > 0 {
 		cols = `id,` + converter.EscapeName(data.params[`columns`].(string))
-	}
-
-	count, err := model.GetNextID(nil, strings.Trim(table, `"`))
-	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("Getting next table id")
-		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, data.params[`name`].(string))
+		for _, o := range query.orders {
+			cols += `,"` + o.Column + `"`
+		}
 	}
 
 	if data.params[`limit`].(int64) > 0 {
@@ -59,14 +112,58 @@ func list(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Ent
 	} else {
 		limit = 25
 	}
-	list, err := model.GetAll(`select `+cols+` from `+table+` order by id desc`+
-		fmt.Sprintf(` offset %d `, data.params[`offset`].(int64)), limit)
+	where, args := query.where(true)
+	sql := `select ` + cols + ` from ` + table + where + query.orderBy()
+	if len(query.cursor) == 0 {
+		sql += fmt.Sprintf(` offset %d `, data.params[`offset`].(int64))
+	}
+	var tr *model.DbTransaction
+	if blockID > 0 {
+		// the rows and their rollback records are read from the same snapshot
+		if tr, err = model.StartReadTransaction(); err != nil {
+			return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+		}
+		defer tr.Rollback()
+	}
+	list, err := model.GetAllTransaction(tr, sql, limit, args...)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("Getting rows from table")
-		return errorAPI(w, err.Error(), http.StatusInternalServerError)
+		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, data.params[`name`].(string))
+	}
+	result := &listResult{List: list}
+	if len(list) == limit {
+		result.Cursor = query.nextCursor(list[len(list)-1])
+	}
+	if blockID > 0 {
+		if result.List, err = model.RestoreRows(tr, strings.Trim(table, `"`), list, blockID); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table, "block_id": blockID}).Error("restoring rows as of block")
+			return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+		}
+	}
+
+	where, args = query.where(false)
+	var count int64
+	switch data.ParamString(`count`) {
+	case countNone:
+	case countEstimate:
+		result.Estimated = true
+		if len(where) == 0 {
+			count, err = model.EstimateCount(strings.Trim(table, `"`))
+		} else {
+			count, err = model.EstimateQueryCount(`select id from `+table+where, args...)
+		}
+	case ``, countExact:
+		err = model.DBConn.Raw(`select count(*) from `+table+where, args...).Row().Scan(&count)
+	default:
+		return errorAPI(w, `E_FILTER`, http.StatusBadRequest, `count must be exact, estimate or none`)
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("Getting count of rows")
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
 	}
-	data.result = &listResult{
-		Count: converter.Int64ToStr(count - 1), List: list,
+	if data.ParamString(`count`) != countNone {
+		result.Count = converter.Int64ToStr(count)
 	}
+	data.result = result
 	return
 }
@@ -0,0 +1,324 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// operators of the filters of list
+var listOperators = map[string]string{
+	`eq`:   `=`,
+	`neq`:  `<>`,
+	`gt`:   `>`,
+	`gte`:  `>=`,
+	`lt`:   `<`,
+	`lte`:  `<=`,
+	`in`:   `in`,
+	`like`: `like`,
+}
+
+// the modes of the total count of list
+const (
+	countExact    = `exact`
+	countEstimate = `estimate`
+	countNone     = `none`
+)
+
+var regColumnName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+type listFilter struct {
+	Column string
+	Op     string
+	Values []string
+}
+
+type listOrder struct {
+	Column string
+	Desc   bool
+}
+
+// listQuery is the filter, the sorting and the cursor of list request
+type listQuery struct {
+	filters []listFilter
+	orders  []listOrder
+	cursor  []string
+	// conds are the additional conditions which are not specified by the user
+	conds    []string
+	condArgs []interface{}
+}
+
+func checkColumnName(name string) error {
+	if !regColumnName.MatchString(name) {
+		return fmt.Errorf(`wrong column name %q`, name)
+	}
+	return nil
+}
+
+func decodeJSON(input []byte, v interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(string(input)))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func scalarValue(column string, v interface{}) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	}
+	return ``, fmt.Errorf(`value of %s must be string, number or boolean`, column)
+}
+
+func filterValues(column, op string, value interface{}) ([]string, error) {
+	if op != `in` {
+		val, err := scalarValue(column, value)
+		return []string{val}, err
+	}
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf(`in of %s requires non-empty array`, column)
+	}
+	values := make([]string, len(list))
+	for i, item := range list {
+		val, err := scalarValue(column, item)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = val
+	}
+	return values, nil
+}
+
+// parseWhere parses the filter in JSON, e.g. {"name": "John", "amount": {"gt": 10, "lt": 100}, "id": {"in": [1, 2]}}.
+// The value without the operator is compared by eq
+func parseWhere(where string) ([]listFilter, error) {
+	if len(strings.TrimSpace(where)) == 0 {
+		return nil, nil
+	}
+	var conds map[string]json.RawMessage
+	if err := decodeJSON([]byte(where), &conds); err != nil {
+		return nil, fmt.Errorf(`where must be JSON object`)
+	}
+	columns := make([]string, 0, len(conds))
+	for column := range conds {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var filters []listFilter
+	for _, column := range columns {
+		if err := checkColumnName(column); err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := decodeJSON(conds[column], &value); err != nil {
+			return nil, err
+		}
+		ops, ok := value.(map[string]interface{})
+		if !ok {
+			ops = map[string]interface{}{`eq`: value}
+		}
+		names := make([]string, 0, len(ops))
+		for op := range ops {
+			names = append(names, op)
+		}
+		sort.Strings(names)
+		for _, op := range names {
+			if _, ok := listOperators[op]; !ok {
+				return nil, fmt.Errorf(`unknown operator %s`, op)
+			}
+			values, err := filterValues(column, op, ops[op])
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, listFilter{Column: column, Op: op, Values: values})
+		}
+	}
+	return filters, nil
+}
+
+// parseOrder parses the comma separated list of columns, the column with minus is sorted descending.
+// The id is appended to the end to make the order unique
+func parseOrder(order string) ([]listOrder, error) {
+	var orders []listOrder
+	used := make(map[string]bool)
+	for _, item := range strings.Split(order, `,`) {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		var desc bool
+		if item[0] == '-' || item[0] == '+' {
+			desc = item[0] == '-'
+			item = item[1:]
+		}
+		if err := checkColumnName(item); err != nil {
+			return nil, err
+		}
+		if used[item] {
+			return nil, fmt.Errorf(`column %s is sorted twice`, item)
+		}
+		used[item] = true
+		orders = append(orders, listOrder{Column: item, Desc: desc})
+	}
+	if !used[`id`] {
+		desc := len(orders) == 0 || orders[0].Desc
+		orders = append(orders, listOrder{Column: `id`, Desc: desc})
+	}
+	return orders, nil
+}
+
+// encodeCursor returns the cursor with the values of the sorted columns of the last row
+func encodeCursor(values []string) string {
+	out, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(out)
+}
+
+func decodeCursor(cursor string, count int) ([]string, error) {
+	var values []string
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &values)
+	}
+	if err != nil || len(values) != count {
+		return nil, fmt.Errorf(`cursor does not match the order`)
+	}
+	return values, nil
+}
+
+func newListQuery(where, order, cursor string) (*listQuery, error) {
+	var (
+		q   listQuery
+		err error
+	)
+	if q.filters, err = parseWhere(where); err != nil {
+		return nil, err
+	}
+	if q.orders, err = parseOrder(order); err != nil {
+		return nil, err
+	}
+	if len(cursor) > 0 {
+		if q.cursor, err = decodeCursor(cursor, len(q.orders)); err != nil {
+			return nil, err
+		}
+	}
+	return &q, nil
+}
+
+// columns returns the columns of the filters and the sorting
+func (q *listQuery) columns() []string {
+	var columns []string
+	used := make(map[string]bool)
+	add := func(column string) {
+		if !used[column] {
+			used[column] = true
+			columns = append(columns, column)
+		}
+	}
+	for _, f := range q.filters {
+		add(f.Column)
+	}
+	for _, o := range q.orders {
+		add(o.Column)
+	}
+	return columns
+}
+
+// addCondition adds the condition which is always used in the query
+func (q *listQuery) addCondition(cond string, args []interface{}) {
+	q.conds = append(q.conds, cond)
+	q.condArgs = append(q.condArgs, args...)
+}
+
+// where returns the conditions of the filters and the cursor if withCursor is true.
+// The cursor condition selects the rows after the cursor in the sorting order
+func (q *listQuery) where(withCursor bool) (string, []interface{}) {
+	conds := append([]string{}, q.conds...)
+	args := append([]interface{}{}, q.condArgs...)
+	for _, f := range q.filters {
+		column := `"` + f.Column + `"`
+		switch f.Op {
+		case `in`:
+			conds = append(conds, column+` in (?`+strings.Repeat(`, ?`, len(f.Values)-1)+`)`)
+		case `like`:
+			conds = append(conds, column+`::text like ?`)
+		default:
+			conds = append(conds, column+` `+listOperators[f.Op]+` ?`)
+		}
+		for _, val := range f.Values {
+			args = append(args, val)
+		}
+	}
+	if withCursor && len(q.cursor) > 0 {
+		ors := make([]string, len(q.orders))
+		for i, o := range q.orders {
+			ands := make([]string, 0, i+1)
+			for j := 0; j < i; j++ {
+				ands = append(ands, `"`+q.orders[j].Column+`" = ?`)
+				args = append(args, q.cursor[j])
+			}
+			op := `>`
+			if o.Desc {
+				op = `<`
+			}
+			ands = append(ands, `"`+o.Column+`" `+op+` ?`)
+			args = append(args, q.cursor[i])
+			ors[i] = `(` + strings.Join(ands, ` AND `) + `)`
+		}
+		conds = append(conds, `(`+strings.Join(ors, ` OR `)+`)`)
+	}
+	if len(conds) == 0 {
+		return ``, nil
+	}
+	return ` WHERE ` + strings.Join(conds, ` AND `), args
+}
+
+func (q *listQuery) orderBy() string {
+	list := make([]string, len(q.orders))
+	for i, o := range q.orders {
+		list[i] = `"` + o.Column + `"`
+		if o.Desc {
+			list[i] += ` desc`
+		}
+	}
+	return ` ORDER BY ` + strings.Join(list, `, `)
+}
+
+// nextCursor returns the cursor of the row
+func (q *listQuery) nextCursor(row map[string]string) string {
+	values := make([]string, len(q.orders))
+	for i, o := range q.orders {
+		values[i] = row[o.Column]
+	}
+	return encodeCursor(values)
+}
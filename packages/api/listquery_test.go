@@ -0,0 +1,94 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestListQuery(t *testing.T) {
+	q, err := newListQuery(`{"name": "John", "amount": {"gt": 10, "lte": 100}, "id": {"in": [1, 2]}, "title": {"like": "a%"}}`,
+		`-amount`, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	where, args := q.where(true)
+	if where != ` WHERE "amount" > ? AND "amount" <= ? AND "id" in (?, ?) AND "name" = ? AND "title"::text like ?` {
+		t.Errorf(`wrong where %s`, where)
+	}
+	if !reflect.DeepEqual(args, []interface{}{`10`, `100`, `1`, `2`, `John`, `a%`}) {
+		t.Errorf(`wrong args %v`, args)
+	}
+	if q.orderBy() != ` ORDER BY "amount" desc, "id" desc` {
+		t.Errorf(`wrong order %s`, q.orderBy())
+	}
+	if !reflect.DeepEqual(q.columns(), []string{`amount`, `id`, `name`, `title`}) {
+		t.Errorf(`wrong columns %v`, q.columns())
+	}
+
+	cursor := q.nextCursor(map[string]string{`id`: `7`, `amount`: `50`})
+	if q, err = newListQuery(``, `amount,-name`, ``); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newListQuery(``, `amount,-name`, cursor); err == nil {
+		t.Error(`cursor of the other order must be rejected`)
+	}
+	if q, err = newListQuery(``, `-amount`, cursor); err != nil {
+		t.Fatal(err)
+	}
+	where, args = q.where(true)
+	if where != ` WHERE (("amount" < ?) OR ("amount" = ? AND "id" < ?))` ||
+		!reflect.DeepEqual(args, []interface{}{`50`, `50`, `7`}) {
+		t.Errorf(`wrong cursor condition %s %v`, where, args)
+	}
+	if where, _ = q.where(false); len(where) != 0 {
+		t.Errorf(`count must not use cursor %s`, where)
+	}
+
+	for _, item := range []struct{ where, order string }{
+		{`[1]`, ``},
+		{`{"name": {"between": 1}}`, ``},
+		{`{"name\"": 1}`, ``},
+		{`{"id": {"in": []}}`, ``},
+		{`{"id": {"eq": {"a": 1}}}`, ``},
+		{``, `id,id`},
+		{``, `name;drop`},
+	} {
+		if _, err = newListQuery(item.where, item.order, ``); err == nil {
+			t.Errorf(`query %s %s must be rejected`, item.where, item.order)
+		}
+	}
+}
+
+func TestListQueryCondition(t *testing.T) {
+	q, err := newListQuery(`{"id": {"gt": 5}}`, ``, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.addCondition(`id NOT IN (SELECT ?)`, []interface{}{`x`})
+	where, args := q.where(false)
+	if where != ` WHERE id NOT IN (SELECT ?) AND "id" > ?` || !reflect.DeepEqual(args, []interface{}{`x`, `5`}) {
+		t.Errorf(`wrong condition %s %v`, where, args)
+	}
+}
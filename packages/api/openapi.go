@@ -0,0 +1,262 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type object map[string]interface{}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// schemaGen generates JSON schemas of Go types and collects the named ones in components
+type schemaGen struct {
+	schemas object
+}
+
+// schemaName returns the name of the schema in components, it is empty for anonymous types
+func schemaName(t reflect.Type) string {
+	name := []rune(t.Name())
+	if len(name) == 0 {
+		return ``
+	}
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
+
+func (g *schemaGen) schema(t reflect.Type) object {
+	if t == rawMessageType {
+		return object{}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return object{`type`: `string`}
+	case reflect.Bool:
+		return object{`type`: `boolean`}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return object{`type`: `integer`, `format`: `int32`}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return object{`type`: `integer`, `format`: `int64`}
+	case reflect.Float32, reflect.Float64:
+		return object{`type`: `number`}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return object{`type`: `string`, `format`: `byte`}
+		}
+		return object{`type`: `array`, `items`: g.schema(t.Elem())}
+	case reflect.Map:
+		return object{`type`: `object`, `additionalProperties`: g.schema(t.Elem())}
+	case reflect.Struct:
+		name := schemaName(t)
+		if len(name) == 0 {
+			return g.structSchema(t)
+		}
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = object{}
+			g.schemas[name] = g.structSchema(t)
+		}
+		return object{`$ref`: `#/components/schemas/` + name}
+	}
+	return object{}
+}
+
+func (g *schemaGen) structSchema(t reflect.Type) object {
+	properties := object{}
+	required := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get(`json`), `,`)
+		if tag[0] == `-` {
+			continue
+		}
+		name := tag[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+		if len(tag) == 1 || tag[1] != `omitempty` {
+			required = append(required, name)
+		}
+	}
+	return object{`type`: `object`, `properties`: properties, `required`: required}
+}
+
+func paramSchema(par int) object {
+	switch par & 0xff {
+	case pInt64:
+		return object{`type`: `integer`, `format`: `int64`}
+	case pHex:
+		return object{`type`: `string`, `pattern`: `^([0-9a-fA-F]{2})*$`}
+	}
+	return object{`type`: `string`}
+}
+
+// operationID converts "GET content/page/:name" to "getContentPage"
+func operationID(method, pattern string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.Split(pattern, `/`) {
+		if len(part) == 0 || part[0] == ':' {
+			continue
+		}
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+func errorResponse(description string) object {
+	return object{`description`: description, `content`: object{`application/json`: object{
+		`schema`: object{`$ref`: `#/components/schemas/Error`}}}}
+}
+
+func (g *schemaGen) operation(r routeInfo) object {
+	parameters := []interface{}{object{`$ref`: `#/components/parameters/vde`}}
+	for _, part := range strings.Split(r.pattern, `/`) {
+		if len(part) > 0 && part[0] == ':' {
+			parameters = append(parameters, object{`name`: part[1:], `in`: `path`, `required`: true,
+				`schema`: object{`type`: `string`}})
+		}
+	}
+	names := make([]string, 0, len(r.params))
+	for name := range r.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	op := object{`operationId`: operationID(r.method, r.pattern)}
+	if r.method == `GET` {
+		for _, name := range names {
+			parameters = append(parameters, object{`name`: name, `in`: `query`,
+				`required`: r.params[name]&pOptional == 0, `schema`: paramSchema(r.params[name])})
+		}
+	} else if len(names) > 0 || r.fields {
+		properties := object{}
+		required := make([]string, 0)
+		for _, name := range names {
+			properties[name] = paramSchema(r.params[name])
+			if r.params[name]&pOptional == 0 {
+				required = append(required, name)
+			}
+		}
+		schema := object{`type`: `object`, `properties`: properties, `required`: required}
+		if r.fields {
+			schema[`additionalProperties`] = object{`type`: `string`}
+		}
+		form := object{`schema`: schema}
+		op[`requestBody`] = object{`required`: len(required) > 0, `content`: object{
+			`application/x-www-form-urlencoded`: form, `multipart/form-data`: form}}
+	}
+	op[`parameters`] = parameters
+
+	result := object{}
+	if r.result != nil {
+		result = g.schema(reflect.TypeOf(r.result))
+	}
+	responses := object{
+		`200`: object{`description`: `OK`, `content`: object{`application/json`: object{`schema`: result}}},
+		`400`: errorResponse(`Bad request`),
+		`429`: errorResponse(`Too many requests`),
+		`500`: errorResponse(`Server error`),
+	}
+	if r.auth {
+		op[`security`] = []interface{}{object{`bearer`: []string{}}}
+		responses[`401`] = errorResponse(`Unauthorized`)
+	}
+	op[`responses`] = responses
+	return op
+}
+
+// openAPI returns OpenAPI 3 specification of the routes which are declared in Route
+func openAPI() object {
+	g := &schemaGen{schemas: object{}}
+	paths := object{}
+	for _, r := range apiRoutes {
+		parts := strings.Split(r.pattern, `/`)
+		for i, part := range parts {
+			if strings.HasPrefix(part, `:`) {
+				parts[i] = `{` + part[1:] + `}`
+			}
+		}
+		path := `/` + strings.Join(parts, `/`)
+		if _, ok := paths[path]; !ok {
+			paths[path] = object{}
+		}
+		paths[path].(object)[strings.ToLower(r.method)] = g.operation(r)
+	}
+
+	codes := make([]string, 0, len(apiErrors))
+	for code := range apiErrors {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	errCodes := object{}
+	for _, code := range codes {
+		errCodes[code] = apiErrors[code]
+	}
+	g.schemas[`Error`] = object{
+		`type`: `object`,
+		`properties`: object{
+			`error`:  object{`type`: `string`, `description`: `error code, see x-error-codes`},
+			`msg`:    object{`type`: `string`},
+			`params`: object{`type`: `array`, `items`: object{`type`: `string`}},
+		},
+		`required`:      []string{`error`, `msg`},
+		`x-error-codes`: errCodes,
+	}
+
+	return object{
+		`openapi`: `3.0.0`,
+		`info`:    object{`title`: `Genesis API`, `version`: consts.VERSION},
+		`servers`: []interface{}{object{`url`: strings.TrimSuffix(consts.ApiPath, `/`)}},
+		`paths`:   paths,
+		`components`: object{
+			`schemas`: g.schemas,
+			`parameters`: object{`vde`: object{`name`: `vde`, `in`: `query`, `required`: false,
+				`description`: `the request is sent to the virtual dedicated ecosystem if it is 1 or true`,
+				`schema`:      object{`type`: `string`}}},
+			`securitySchemes`: object{`bearer`: object{`type`: `http`, `scheme`: `bearer`, `bearerFormat`: `JWT`}},
+		},
+	}
+}
+
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(openAPI()); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling openapi specification")
+	}
+}
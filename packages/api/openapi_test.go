@@ -0,0 +1,100 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	hr "github.com/julienschmidt/httprouter"
+)
+
+func TestOpenAPI(t *testing.T) {
+	Route(hr.New())
+
+	ids := make(map[string]bool)
+	for _, r := range apiRoutes {
+		if r.result == nil {
+			t.Errorf(`result of %s %s is not defined`, r.method, r.pattern)
+		}
+		id := operationID(r.method, r.pattern)
+		if ids[id] {
+			t.Errorf(`duplicate operation id %s`, id)
+		}
+		ids[id] = true
+	}
+
+	out, err := json.Marshal(openAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name     string `json:"name"`
+				In       string `json:"in"`
+				Required bool   `json:"required"`
+			} `json:"parameters"`
+			Security []interface{} `json:"security"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err = json.Unmarshal(out, &spec); err != nil {
+		t.Fatal(err)
+	}
+	list, ok := spec.Paths[`/list/{name}`][`get`]
+	if !ok || len(list.Security) != 1 {
+		t.Fatalf(`wrong list operation %+v`, list)
+	}
+	params := make(map[string]string)
+	for _, par := range list.Parameters {
+		params[par.Name] = par.In
+		if par.Name == `name` && !par.Required || par.Name == `limit` && par.Required {
+			t.Errorf(`wrong required flag of %s`, par.Name)
+		}
+	}
+	if params[`name`] != `path` || params[`limit`] != `query` || params[`columns`] != `query` {
+		t.Errorf(`wrong list parameters %v`, params)
+	}
+	if len(spec.Paths[`/getuid`][`get`].Security) != 0 {
+		t.Error(`getuid must not require authorization`)
+	}
+	for _, name := range []string{`Error`, `ListResult`, `PrepareResult`, `TxSignJSON`} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf(`schema %s is missing`, name)
+		}
+	}
+}
+
+func TestSchemaAnonymous(t *testing.T) {
+	g := &schemaGen{schemas: object{}}
+	schema := g.schema(reflect.TypeOf(struct {
+		Count int64 `json:"count"`
+	}{}))
+	if schema[`type`] != `object` || len(g.schemas) != 0 {
+		t.Errorf(`wrong schema of anonymous struct %v`, schema)
+	}
+}
@@ -0,0 +1,288 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/metrics"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// route groups of rate limits
+const (
+	groupDefault  = `default`
+	groupContent  = `content`
+	groupList     = `list`
+	groupContract = `contract`
+)
+
+const (
+	// ecosystemLimitPrefix is the prefix of ecosystem parameters which override the limits,
+	// e.g. api_rate_limit_content = "60,10" is 60 requests per minute with the burst 10
+	ecosystemLimitPrefix = `api_rate_limit_`
+	ecosystemLimitTTL    = time.Minute
+	bucketsCleanup       = time.Minute
+)
+
+var apiRateLimited = metrics.NewCounter(`genesis_api_rate_limited_total`,
+	`Number of API requests rejected by rate limits`, `group`)
+
+// routeGroup returns the group of rate limits of the route pattern
+func routeGroup(method, pattern string) string {
+	switch strings.SplitN(pattern, `/`, 2)[0] {
+	case `content`:
+		return groupContent
+	case `list`, `row`, `tables`, `history`, `graphql`, `txhistory`, `explorer`, `tablehash`:
+		return groupList
+	case `prepare`, `contract`, `node`:
+		if method == `POST` {
+			return groupContract
+		}
+	}
+	return groupDefault
+}
+
+func groupLimit(group string) conf.RateLimit {
+	cfg := conf.Config.RateLimit
+	switch group {
+	case groupContent:
+		return cfg.Content
+	case groupList:
+		return cfg.List
+	case groupContract:
+		return cfg.Contract
+	}
+	return cfg.Default
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // the time when the bucket is refilled
+}
+
+// limiter contains token buckets by keys
+type limiter struct {
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+	cleaned time.Time
+}
+
+// allow takes the token from the bucket. It returns false and the time to wait if the bucket is empty
+func (l *limiter) allow(key string, limit conf.RateLimit, now time.Time) (bool, time.Duration) {
+	rate := float64(limit.Rate) / 60
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = float64(limit.Rate)
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if now.Sub(l.cleaned) > bucketsCleanup {
+		l.cleanup(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	b.full = now.Add(time.Duration((burst - b.tokens) / rate * float64(time.Second)))
+	return true, 0
+}
+
+// cleanup removes the buckets which have been refilled
+func (l *limiter) cleanup(now time.Time) {
+	for key, b := range l.buckets {
+		if now.After(b.full) {
+			delete(l.buckets, key)
+		}
+	}
+	l.cleaned = now
+}
+
+type ecosystemLimit struct {
+	limit   conf.RateLimit
+	found   bool
+	expires time.Time
+}
+
+var (
+	apiLimiter = &limiter{buckets: make(map[string]*bucket)}
+
+	ecosystemLimitsMutex sync.Mutex
+	ecosystemLimits      = make(map[string]ecosystemLimit)
+)
+
+// parseRateLimit parses "rate,burst" value of the ecosystem parameter
+func parseRateLimit(value string) (conf.RateLimit, error) {
+	var limit conf.RateLimit
+	pars := strings.Split(value, `,`)
+	if len(pars) > 2 {
+		return limit, fmt.Errorf(`wrong rate limit %s`, value)
+	}
+	for i, par := range pars {
+		val, err := strconv.ParseInt(strings.TrimSpace(par), 10, 64)
+		if err != nil || val < 0 {
+			return limit, fmt.Errorf(`wrong rate limit %s`, value)
+		}
+		if i == 0 {
+			limit.Rate = val
+		} else {
+			limit.Burst = val
+		}
+	}
+	return limit, nil
+}
+
+// getEcosystemLimit returns the limit of the ecosystem parameter, the parameters are cached for a minute
+func getEcosystemLimit(ecosystemID int64, group string, logger *log.Entry) (conf.RateLimit, bool) {
+	key := converter.Int64ToStr(ecosystemID) + `_` + group
+	now := time.Now()
+	ecosystemLimitsMutex.Lock()
+	cached, ok := ecosystemLimits[key]
+	ecosystemLimitsMutex.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.limit, cached.found
+	}
+
+	cached = ecosystemLimit{expires: now.Add(ecosystemLimitTTL)}
+	sp := &model.StateParameter{}
+	sp.SetTablePrefix(converter.Int64ToStr(ecosystemID))
+	if found, err := sp.Get(nil, ecosystemLimitPrefix+group); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting rate limit parameter")
+	} else if found {
+		if cached.limit, err = parseRateLimit(sp.Value); err != nil {
+			logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "error": err}).Warning("wrong rate limit parameter")
+		} else {
+			cached.found = true
+		}
+	}
+	ecosystemLimitsMutex.Lock()
+	ecosystemLimits[key] = cached
+	ecosystemLimitsMutex.Unlock()
+	return cached.limit, cached.found
+}
+
+// effectiveLimit returns the limit of the ecosystem parameter, it can't exceed the limit of the node
+func effectiveLimit(node, ecosystem conf.RateLimit) conf.RateLimit {
+	if node.Rate == 0 || ecosystem.Rate > 0 && ecosystem.Rate < node.Rate {
+		return ecosystem
+	}
+	return node
+}
+
+// clientIP returns the address of the client. RealIPHeader is used only if the request
+// has come from the trusted proxy, the last address which is not the trusted proxy is taken
+// from the header, so the addresses which have been added by the client are ignored
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	cfg := conf.Config.RateLimit
+	if len(cfg.RealIPHeader) == 0 || !inIPList(cfg.TrustedProxies, host) {
+		return host
+	}
+	list := strings.Split(r.Header.Get(cfg.RealIPHeader), `,`)
+	for i := len(list) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(list[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+		host = ip
+		if !inIPList(cfg.TrustedProxies, ip) {
+			break
+		}
+	}
+	return host
+}
+
+func isExempt(ip string) bool {
+	return inIPList(conf.Config.RateLimit.Exempt, ip)
+}
+
+// inIPList checks if ip is in the comma separated list of IPs and CIDRs
+func inIPList(list, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, item := range strings.Split(list, `,`) {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, `/`) {
+			if _, ipnet, err := net.ParseCIDR(item); err == nil && ipnet.Contains(addr) {
+				return true
+			}
+		} else if exempt := net.ParseIP(item); exempt != nil && exempt.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRateLimit rejects the request with 429 status if the limit of its group is exceeded.
+// The requests are counted by the ecosystem and the key of the token or by the client IP
+func checkRateLimit(w http.ResponseWriter, r *http.Request, group string, data *apiData, logger *log.Entry) error {
+	ip := clientIP(r)
+	if isExempt(ip) {
+		return nil
+	}
+	limit := groupLimit(group)
+	key := group + `:ip:` + ip
+	if data.keyId != 0 {
+		key = fmt.Sprintf(`%s:key:%d:%d`, group, data.ecosystemId, data.keyId)
+		if ecosystem, ok := getEcosystemLimit(data.ecosystemId, group, logger); ok {
+			limit = effectiveLimit(limit, ecosystem)
+		}
+	}
+	if limit.Rate == 0 {
+		return nil
+	}
+	ok, wait := apiLimiter.allow(key, limit, time.Now())
+	if ok {
+		return nil
+	}
+	apiRateLimited.With(group).Inc()
+	retry := int64(math.Ceil(wait.Seconds()))
+	logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "group": group, "key": key, "retry": retry}).Warning("api rate limit is exceeded")
+	w.Header().Set(`Retry-After`, strconv.FormatInt(retry, 10))
+	return errorAPI(w, `E_LIMIT`, http.StatusTooManyRequests, retry)
+}
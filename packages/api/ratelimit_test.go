@@ -0,0 +1,103 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := &limiter{buckets: make(map[string]*bucket)}
+	limit := conf.RateLimit{Rate: 60, Burst: 2}
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow(`key`, limit, now); !ok {
+			t.Fatalf(`request %d must be allowed`, i)
+		}
+	}
+	ok, wait := l.allow(`key`, limit, now)
+	if ok || wait != time.Second {
+		t.Errorf(`request must be limited %v %v`, ok, wait)
+	}
+	if ok, _ = l.allow(`other`, limit, now); !ok {
+		t.Error(`buckets of keys must be independent`)
+	}
+	if ok, _ = l.allow(`key`, limit, now.Add(time.Second)); !ok {
+		t.Error(`bucket must be refilled`)
+	}
+	l.cleanup(now.Add(time.Hour))
+	if len(l.buckets) != 0 {
+		t.Errorf(`full buckets must be removed %d`, len(l.buckets))
+	}
+}
+
+func TestRateLimitParams(t *testing.T) {
+	if limit, err := parseRateLimit(`60, 10`); err != nil || limit.Rate != 60 || limit.Burst != 10 {
+		t.Errorf(`wrong limit %v %v`, limit, err)
+	}
+	if _, err := parseRateLimit(`60,a`); err == nil {
+		t.Error(`wrong limit must fail`)
+	}
+	node := conf.RateLimit{Rate: 100}
+	if limit := effectiveLimit(node, conf.RateLimit{Rate: 500}); limit.Rate != 100 {
+		t.Error(`ecosystem limit must not exceed node limit`)
+	}
+	if limit := effectiveLimit(node, conf.RateLimit{Rate: 10}); limit.Rate != 10 {
+		t.Error(`ecosystem limit must be applied`)
+	}
+	for pattern, group := range map[string]string{`content/page/:name`: groupContent, `list/:name`: groupList,
+		`contract/:name`: groupContract, `contracts`: groupDefault} {
+		if g := routeGroup(`POST`, pattern); g != group {
+			t.Errorf(`wrong group %s of %s`, g, pattern)
+		}
+	}
+	conf.Config.RateLimit.Exempt = `127.0.0.1/8, 10.1.1.1`
+	if !isExempt(`127.0.0.2`) || !isExempt(`10.1.1.1`) || isExempt(`10.1.1.2`) {
+		t.Error(`wrong exempt addresses`)
+	}
+
+	conf.Config.RateLimit.RealIPHeader = `X-Forwarded-For`
+	conf.Config.RateLimit.TrustedProxies = `10.0.0.1`
+	defer func() { conf.Config.RateLimit.RealIPHeader, conf.Config.RateLimit.TrustedProxies = ``, `` }()
+	for _, item := range []struct {
+		remote, header, ip string
+	}{
+		{`1.2.3.4:5000`, `127.0.0.1`, `1.2.3.4`},
+		{`10.0.0.1:5000`, `5.6.7.8`, `5.6.7.8`},
+		{`10.0.0.1:5000`, `127.0.0.1, 5.6.7.8`, `5.6.7.8`},
+		{`10.0.0.1:5000`, `5.6.7.8, 10.0.0.1`, `5.6.7.8`},
+		{`10.0.0.1:5000`, `wrong`, `10.0.0.1`},
+		{`10.0.0.1:5000`, ``, `10.0.0.1`},
+	} {
+		r := httptest.NewRequest(`GET`, `/api/v2/contracts`, nil)
+		r.RemoteAddr = item.remote
+		r.Header.Set(`X-Forwarded-For`, item.header)
+		if ip := clientIP(r); ip != item.ip {
+			t.Errorf(`wrong client ip %s of %s %s`, ip, item.remote, item.header)
+		}
+	}
+}
@@ -23,6 +23,7 @@
 package api
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
@@ -30,64 +31,102 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func methodRoute(route *hr.Router, method, pattern, pars string, handler ...apiHandle) {
-	route.Handle(method, consts.ApiPath+pattern, DefaultHandler(method, pattern, processParams(pars), handler...))
+// routeInfo is the declaration of api route, it is used for OpenAPI specification
+type routeInfo struct {
+	method  string
+	pattern string
+	params  map[string]int
+	result  interface{} // the value of the result type
+	auth    bool
+	fields  bool // the route accepts the parameters of contract
+}
+
+var apiRoutes []routeInfo
+
+func methodRoute(route *hr.Router, method, pattern, pars string, result interface{}, handler ...apiHandle) {
+	params := processParams(pars)
+	apiRoutes = append(apiRoutes, routeInfo{method: method, pattern: pattern, params: params, result: result,
+		auth:   hasHandler(handler, authWallet),
+		fields: hasHandler(handler, prepareContract) || hasHandler(handler, contract) || hasHandler(handler, nodeContract)})
+	route.Handle(method, consts.ApiPath+pattern, DefaultHandler(method, pattern, params, handler...))
+}
+
+func hasHandler(handlers []apiHandle, handler apiHandle) bool {
+	for _, h := range handlers {
+		if reflect.ValueOf(h).Pointer() == reflect.ValueOf(handler).Pointer() {
+			return true
+		}
+	}
+	return false
 }
 
 // Route sets routing pathes
 func Route(route *hr.Router) {
-	get := func(pattern, params string, handler ...apiHandle) {
-		methodRoute(route, `GET`, pattern, params, handler...)
+	get := func(pattern, params string, result interface{}, handler ...apiHandle) {
+		methodRoute(route, `GET`, pattern, params, result, handler...)
 	}
-	post := func(pattern, params string, handler ...apiHandle) {
-		methodRoute(route, `POST`, pattern, params, handler...)
+	post := func(pattern, params string, result interface{}, handler ...apiHandle) {
+		methodRoute(route, `POST`, pattern, params, result, handler...)
 	}
 	anyTx := func(method, pattern, pars string, preHandle, handle apiHandle) {
-		methodRoute(route, method, `prepare/`+pattern, pars, authWallet, preHandle)
+		methodRoute(route, method, `prepare/`+pattern, pars, prepareResult{}, authWallet, preHandle)
 		if len(pars) > 0 {
 			pars = `,` + pars
 		}
-		methodRoute(route, method, `contract/`+pattern, `?pubkey signature:hex, time:string`+pars, authWallet, handle)
+		methodRoute(route, method, `contract/`+pattern, `?pubkey signature:hex, time:string`+pars, contractResult{}, authWallet, handle)
 	}
 	postTx := func(url string, params string, preHandle, handle apiHandle) {
 		anyTx(`POST`, url, params, preHandle, handle)
 	}
 
+	apiRoutes = nil
 	route.Handle(`OPTIONS`, consts.ApiPath+`*name`, optionsHandler())
+	route.HandlerFunc(`GET`, consts.ApiPath+`openapi.json`, openAPIHandler)
 	route.Handle(`GET`, consts.ApiPath+`data/:table/:id/:column/:hash`, dataHandler())
 
-	get(`balance/:wallet`, `?ecosystem:int64`, authWallet, balance)
-	get(`contract/:name`, ``, authWallet, getContract)
-	get(`contracts`, `?limit ?offset:int64`, authWallet, getContracts)
-	get(`ecosystemparam/:name`, `?ecosystem:int64`, authWallet, ecosystemParam)
-	get(`ecosystemparams`, `?ecosystem:int64,?names:string`, authWallet, ecosystemParams)
-	get(`ecosystems`, ``, authWallet, ecosystems)
-	get(`getuid`, ``, getUID)
-	get(`list/:name`, `?limit ?offset:int64,?columns:string`, authWallet, list)
-	get(`row/:name/:id`, `?columns:string`, authWallet, row)
-	get(`systemparams`, `?names:string`, authWallet, systemParams)
-	get(`table/:name`, ``, authWallet, table)
-	get(`tables`, `?limit ?offset:int64`, authWallet, tables)
-	get(`txstatus/:hash`, ``, authWallet, txstatus)
-	get(`test/:name`, ``, getTest)
-	get(`history/:table/:id`, ``, authWallet, getHistory)
-	get(`block/:id`, ``, getBlockInfo)
-	get(`maxblockid`, ``, getMaxBlockID)
+	get(`balance/:wallet`, `?ecosystem:int64`, balanceResult{}, authWallet, balance)
+	get(`contract/:name`, ``, getContractResult{}, authWallet, getContract)
+	get(`contracts`, `?limit ?offset:int64`, listResult{}, authWallet, getContracts)
+	get(`ecosystemparam/:name`, `?ecosystem:int64`, paramValue{}, authWallet, ecosystemParam)
+	get(`ecosystemparams`, `?ecosystem:int64,?names:string`, ecosystemParamsResult{}, authWallet, ecosystemParams)
+	get(`ecosystems`, ``, ecosystemsResult{}, authWallet, ecosystems)
+	get(`getuid`, ``, getUIDResult{}, getUID)
+	get(`graphql`, `?query ?variables ?operation:string`, graphqlResult{}, authWallet, graphQL)
+	get(`list/:name`, `?limit ?offset ?block:int64,?columns ?where ?order ?cursor ?count:string`, listResult{}, authWallet, list)
+	get(`row/:name/:id`, `?block:int64,?columns:string`, rowResult{}, authWallet, row)
+	get(`systemparams`, `?names:string`, ecosystemParamsResult{}, authWallet, systemParams)
+	get(`table/:name`, ``, tableResult{}, authWallet, table)
+	get(`tables`, `?limit ?offset:int64`, tablesResult{}, authWallet, tables)
+	get(`txstatus/:hash`, ``, txstatusResult{}, authWallet, txstatus)
+	get(`txhistory/key/:key`, `?limit ?ecosystem:int64,?contract ?cursor:string`, txHistoryResult{}, authWallet, txHistoryByKey)
+	get(`txhistory/contract/:name`, `?limit ?ecosystem:int64,?key ?cursor:string`, txHistoryResult{}, authWallet, txHistoryByContract)
+	get(`txhistory/tx/:hash`, ``, txHistoryItem{}, authWallet, txHistoryByHash)
+	get(`explorer/block/:id`, ``, explorerBlock{}, authWallet, explorerBlockInfo)
+	get(`explorer/tx/:hash`, ``, explorerTx{}, authWallet, explorerTxInfo)
+	get(`test/:name`, ``, getTestResult{}, getTest)
+	get(`history/:table/:id`, ``, historyResult{}, authWallet, getHistory)
+	get(`block/:id`, ``, GetBlockInfoResult{}, getBlockInfo)
+	get(`maxblockid`, ``, GetMaxBlockIDResult{}, getMaxBlockID)
+	get(`tablehash/:name`, `?block ?ecosystem:int64`, TableHashResult{}, authWallet, tableHash)
+	get(`tokens`, ``, apiTokensResult{}, authWallet, getAPITokens)
 
-	post(`content/page/:name`, ``, authWallet, getPage)
-	post(`content/menu/:name`, ``, authWallet, getMenu)
-	post(`content/hash/:name`, ``, authWallet, getPageHash)
+	post(`content/page/:name`, ``, contentResult{}, authWallet, getPage)
+	post(`content/menu/:name`, ``, contentResult{}, authWallet, getMenu)
+	post(`content/hash/:name`, ``, hashResult{}, authWallet, getPageHash)
 	post(`install`, `?first_load_blockchain_url ?first_block_dir log_level type db_host db_port 
-	db_name db_pass db_user ?centrifugo_url ?centrifugo_secret:string,?generate_first_block:int64`, doInstall)
-	post(`vde/create`, ``, authWallet, vdeCreate)
-	post(`login`, `?pubkey signature:hex,?key_id:string,?ecosystem ?expire:int64`, login)
+	db_name db_pass db_user ?centrifugo_url ?centrifugo_secret:string,?generate_first_block:int64`, installResult{}, doInstall)
+	post(`vde/create`, ``, vdeCreateResult{}, authWallet, vdeCreate)
+	post(`login`, `?pubkey signature:hex,?key_id:string,?ecosystem ?expire:int64`, loginResult{}, login)
 	postTx(`:name`, `?token_ecosystem:int64,?max_sum ?payover:string`, prepareContract, contract)
-	post(`refresh`, `token:string,?expire:int64`, refresh)
-	post(`signtest/`, `forsign private:string`, signTest)
-	post(`test/:name`, ``, getTest)
-	post(`content`, `template:string`, jsonContent)
+	post(`refresh`, `token:string,?expire:int64`, refreshResult{}, refresh)
+	post(`signtest/`, `forsign private:string`, signTestResult{}, signTest)
+	post(`test/:name`, ``, getTestResult{}, getTest)
+	post(`content`, `template:string`, contentResult{}, jsonContent)
+	post(`graphql`, `?query ?variables ?operation:string`, graphqlResult{}, authWallet, graphQL)
+	post(`tokens`, `name time:string,?access ?contracts ?tables:string,?expire:int64,signature:hex`, apiTokenInfo{}, authWallet, createAPIToken)
+	post(`tokens/:id/revoke`, ``, revokeResult{}, authWallet, revokeAPIToken)
 
-	methodRoute(route, `POST`, `node/:name`, `?token_ecosystem:int64,?max_sum ?payover:string`, nodeContract)
+	methodRoute(route, `POST`, `node/:name`, `?token_ecosystem:int64,?max_sum ?payover:string`, contractResult{}, nodeContract)
 }
 
 func processParams(input string) (params map[string]int) {
@@ -24,6 +24,7 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
@@ -42,7 +43,23 @@ func row(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entr
 		cols = converter.EscapeName(data.params[`columns`].(string))
 	}
 	table := converter.EscapeName(getPrefix(data) + `_` + data.params[`name`].(string))
-	row, err := model.GetOneRow(`SELECT `+cols+` FROM `+table+` WHERE id = ?`, data.params[`id`].(string)).String()
+	var row map[string]string
+	if blockID := data.ParamInt64(`block`); blockID > 0 {
+		if err = checkPrunedBlock(w, blockID, logger); err != nil {
+			return err
+		}
+		var tr *model.DbTransaction
+		if tr, err = model.StartReadTransaction(); err != nil {
+			return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+		}
+		row, err = model.GetRowAsOf(tr, strings.Trim(table, `"`), cols, data.params[`id`].(string), blockID)
+		tr.Rollback()
+		if row == nil && err == nil {
+			row = map[string]string{}
+		}
+	} else {
+		row, err = model.GetOneRow(`SELECT `+cols+` FROM `+table+` WHERE id = ?`, data.params[`id`].(string)).String()
+	}
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": data.params["name"].(string), "id": data.params["id"].(string)}).Error("getting one row")
 		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
@@ -51,3 +68,17 @@ func row(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entr
 	data.result = &rowResult{Value: row}
 	return
 }
+
+// checkPrunedBlock checks that the rollback records which are required to restore the state of the block
+// have not been pruned
+func checkPrunedBlock(w http.ResponseWriter, blockID int64, logger *log.Entry) error {
+	info := &model.PruneInfo{}
+	if _, err := info.Get(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting prune info")
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+	}
+	if blockID < info.RollbackBlockID {
+		return errorAPI(w, `E_PRUNED`, http.StatusBadRequest, blockID)
+	}
+	return nil
+}
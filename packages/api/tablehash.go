@@ -0,0 +1,172 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// tableHashBatch is the count of rows which are read at once
+	tableHashBatch = 1000
+	// tableHashCacheSize limits the count of the cached hashes
+	tableHashCacheSize = 100
+)
+
+// tableHashes caches the hashes by the table, the block and its hash. The state of the table
+// as of the block doesn't change, so the hash is calculated once
+var tableHashes = struct {
+	sync.Mutex
+	cache map[string]*TableHashResult
+}{cache: make(map[string]*TableHashResult)}
+
+// TableHashResult is the hash of the content of the table
+type TableHashResult struct {
+	Table   string `json:"table"`
+	BlockID int64  `json:"block_id"`
+	Count   int64  `json:"count"`
+	Hash    string `json:"hash"`
+}
+
+// hashRow writes the columns of the row sorted by names, so the hash doesn't depend on the order of columns
+func hashRow(buf []byte, row map[string]string) []byte {
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buf = append(buf, fmt.Sprintf("%d:%s%d:%s", len(name), name, len(row[name]), row[name])...)
+	}
+	return append(buf, '\n')
+}
+
+// tableHash returns the hash of the content of the ecosystem table as of the block.
+// The nodes with the same state return the same hash
+func tableHash(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) (err error) {
+	ecosystem := data.ParamInt64(`ecosystem`)
+	if ecosystem == 0 {
+		ecosystem = 1
+	}
+	name := converter.Int64ToStr(ecosystem) + `_` + data.params[`name`].(string)
+	logger = logger.WithFields(log.Fields{"table": name})
+	columns, err := model.GetTableColumns(name)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting columns of table")
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+	}
+	if len(columns) == 0 {
+		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, data.params[`name`].(string))
+	}
+
+	// all batches are read from the same snapshot, so the block applied during the scan doesn't change them
+	tr, err := model.StartReadTransaction()
+	if err != nil {
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+	}
+	defer tr.Rollback()
+
+	last, err := model.GetOneRowTransaction(tr, `SELECT id, hash FROM block_chain ORDER BY id DESC LIMIT 1`).Bytes()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting max block")
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+	}
+	lastBlockID := converter.StrToInt64(string(last[`id`]))
+	blockID := data.ParamInt64(`block`)
+	if blockID > lastBlockID {
+		return errorAPI(w, `E_NOTFOUND`, http.StatusNotFound)
+	}
+	blockHash := last[`hash`]
+	if blockID > 0 && blockID < lastBlockID {
+		if err = checkPrunedBlock(w, blockID, logger); err != nil {
+			return err
+		}
+		row, err := model.GetOneRowTransaction(tr, `SELECT hash FROM block_chain WHERE id = ?`, blockID).Bytes()
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": blockID}).Error("getting block hash")
+			return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+		}
+		blockHash = row[`hash`]
+	} else {
+		blockID = lastBlockID
+	}
+	key := fmt.Sprintf(`%s:%d:%x`, name, blockID, blockHash)
+	tableHashes.Lock()
+	result := tableHashes.cache[key]
+	tableHashes.Unlock()
+	if result != nil {
+		data.result = result
+		return nil
+	}
+
+	cond, args := model.NotInsertedAfterBlock(name, blockID)
+	query := `SELECT * FROM ` + converter.EscapeName(name) + ` WHERE id > ? AND ` + cond +
+		fmt.Sprintf(` ORDER BY id LIMIT %d`, tableHashBatch)
+
+	result = &TableHashResult{Table: name, BlockID: blockID}
+	hash := sha256.New()
+	var (
+		buf    []byte
+		lastID int64
+	)
+	for {
+		rows, err := model.GetAllTransaction(tr, query, -1, append([]interface{}{lastID}, args...)...)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting rows of table")
+			return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		lastID = converter.StrToInt64(rows[len(rows)-1][`id`])
+		if rows, err = model.RestoreRows(tr, name, rows, blockID); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": blockID}).Error("restoring rows as of block")
+			return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+		}
+		for _, row := range rows {
+			buf = hashRow(buf[:0], row)
+			hash.Write(buf)
+		}
+		result.Count += int64(len(rows))
+	}
+	result.Hash = fmt.Sprintf(`%x`, hash.Sum(nil))
+
+	tableHashes.Lock()
+	if len(tableHashes.cache) >= tableHashCacheSize {
+		tableHashes.cache = make(map[string]*TableHashResult)
+	}
+	tableHashes.cache[key] = result
+	tableHashes.Unlock()
+	data.result = result
+	return nil
+}
@@ -0,0 +1,39 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"testing"
+)
+
+func TestTableHashRow(t *testing.T) {
+	first := hashRow(nil, map[string]string{`id`: `1`, `amount`: `10`})
+	second := hashRow(nil, map[string]string{`amount`: `10`, `id`: `1`})
+	if string(first) != string(second) {
+		t.Errorf(`hash must not depend on the order of columns %s %s`, first, second)
+	}
+	// the lengths prevent the collisions of the shifted values
+	if string(hashRow(nil, map[string]string{`a`: `1b`, `b`: ``})) == string(hashRow(nil, map[string]string{`a`: `1`, `b`: `b`})) {
+		t.Error(`different rows must have different data`)
+	}
+}
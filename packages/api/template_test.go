@@ -197,7 +197,7 @@ func TestImage(t *testing.T) {
 		return
 	}
 	md := fmt.Sprintf(`%x`, md5.Sum([]byte(mydata)))
-	want := `[{"tag":"div","attr":{"class":"list-group-item"},"children":[{"tag":"div","attr":{"class":"panel-body"},"children":[{"tag":"dbfind","attr":{"columns":["id","name","image","leftImg"],"data":[["2","myimage","/data/1_` + name + `/2/image/` + md + `","[{"tag":"image","attr":{"src":"/data/1_` + name + `/2/image/` + md + `"}}]"]],"name":"` + name + `","source":"mysrc","types":["text","text","text","tags"],"whereid":"2"}}]},{"tag":"table","attr":{"columns":[{"Name":"leftImg","Title":"Image"}],"source":"mysrc"}}]},{"tag":"form","children":[{"tag":"imageinput","attr":{"name":"img","ratio":"2/1","width":"400"}},{"tag":"button","attr":{"contract":"UploadImage"},"children":[{"tag":"text","text":"Upload!"}]}]}]`
+	want := `[{"tag":"div","attr":{"class":"list-group-item"},"children":[{"tag":"div","attr":{"class":"panel-body"},"children":[{"tag":"dbfind","attr":{"columns":["id","name","image","leftImg"],"data":[["2","myimage","/data/1_` + name + `/2/image/` + md + `","[{"tag":"image","attr":{"src":"/data/1_` + name + `/2/image/` + md + `"}}]"]],"name":"` + name + `","source":"mysrc","types":["text","text","text","tags"],"whereid":"2"}}]},{"tag":"table","attr":{"columns":[{"Name":"leftImg","Title":"Image"}],"pager":{"offset":0,"limit":25},"source":"mysrc"}}]},{"tag":"form","children":[{"tag":"imageinput","attr":{"name":"img","ratio":"2/1","width":"400"}},{"tag":"button","attr":{"contract":"UploadImage"},"children":[{"tag":"text","text":"Upload!"}]}]}]`
 	if RawToString(ret.Tree) != want {
 		t.Errorf("Wrong image tree %s", RawToString(ret.Tree))
 	}
@@ -0,0 +1,383 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// access levels of the service tokens
+const (
+	accessRead     = `read`
+	accessContract = `contract`
+)
+
+const (
+	apiTokenCacheTTL = time.Minute
+	// apiTokenTimeGap is the allowed difference between the time of the signed request and the node time
+	apiTokenTimeGap = 5 * 60
+)
+
+// tokenScope restricts the routes of the service token
+type tokenScope struct {
+	Access    string   `json:"access"`
+	Contracts []string `json:"contracts,omitempty"`
+	Tables    []string `json:"tables,omitempty"`
+}
+
+type apiTokenInfo struct {
+	ID      int64      `json:"id"`
+	Name    string     `json:"name"`
+	Scope   tokenScope `json:"scope"`
+	Created int64      `json:"created"`
+	Expire  int64      `json:"expire"`
+	Revoked int64      `json:"revoked"`
+	Token   string     `json:"token,omitempty"` // it is returned only on creation
+}
+
+type apiTokensResult struct {
+	List []apiTokenInfo `json:"list"`
+}
+
+type revokeResult struct {
+	ID      int64 `json:"id"`
+	Revoked int64 `json:"revoked"`
+}
+
+type cachedToken struct {
+	token  *model.APIToken
+	loaded time.Time
+}
+
+var (
+	apiTokens = struct {
+		sync.Mutex
+		cache   map[string]cachedToken
+		revoked map[int64]bool // nil until the revocation list has been loaded
+	}{cache: make(map[string]cachedToken)}
+
+	// readRoutes are the routes which are allowed with the read access. They can read any table,
+	// so they are denied for the token with the table scope
+	readRoutes = map[string]bool{
+		`POST content/page/:name`:      true,
+		`POST content/menu/:name`:      true,
+		`POST content/hash/:name`:      true,
+		`POST content`:                 true,
+		`GET graphql`:                  true,
+		`POST graphql`:                 true,
+		`GET explorer/block/:id`:       true,
+		`GET explorer/tx/:hash`:        true,
+		`GET txhistory/key/:key`:       true,
+		`GET txhistory/contract/:name`: true,
+		`GET txhistory/tx/:hash`:       true,
+	}
+	// contractRoutes are the routes which require the contract access
+	contractRoutes = map[string]bool{
+		`POST prepare/:name`:  true,
+		`POST contract/:name`: true,
+	}
+	// tableRoutes are the routes and the parameters of the table name which are checked with the table scope
+	tableRoutes = map[string]string{
+		`GET list/:name`:         `name`,
+		`GET row/:name/:id`:      `name`,
+		`GET table/:name`:        `name`,
+		`GET history/:table/:id`: `table`,
+		`GET tablehash/:name`:    `name`,
+	}
+
+	regEcosystemPrefix = regexp.MustCompile(`^(@\d+|\d+_)`)
+)
+
+func hashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func generateAPIToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return ``, err
+	}
+	return consts.APITokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// serviceToken returns the service token of the request or nil if the request has JWT token
+func serviceToken(r *http.Request) (*model.APIToken, error) {
+	auth := r.Header.Get(`Authorization`)
+	if !strings.HasPrefix(auth, jwtPrefix+consts.APITokenPrefix) {
+		return nil, nil
+	}
+	hash := hashAPIToken(auth[len(jwtPrefix):])
+
+	apiTokens.Lock()
+	defer apiTokens.Unlock()
+	if item, ok := apiTokens.cache[hash]; ok && time.Since(item.loaded) < apiTokenCacheTTL {
+		return item.token, nil
+	}
+	token := &model.APIToken{}
+	found, err := token.GetByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf(`unknown service token`)
+	}
+	apiTokens.cache[hash] = cachedToken{token: token, loaded: time.Now()}
+	return token, nil
+}
+
+// isRevoked checks the revocation list, the list is loaded from DB on the first call
+func isRevoked(id int64) (bool, error) {
+	apiTokens.Lock()
+	defer apiTokens.Unlock()
+	if apiTokens.revoked == nil {
+		ids, err := model.GetRevokedAPITokens(time.Now().Unix())
+		if err != nil {
+			return false, err
+		}
+		apiTokens.revoked = make(map[int64]bool)
+		for _, id := range ids {
+			apiTokens.revoked[id] = true
+		}
+	}
+	return apiTokens.revoked[id], nil
+}
+
+func setRevoked(id int64) {
+	apiTokens.Lock()
+	defer apiTokens.Unlock()
+	if apiTokens.revoked != nil {
+		apiTokens.revoked[id] = true
+	}
+}
+
+func parseScope(value string) (scope tokenScope) {
+	if err := json.Unmarshal([]byte(value), &scope); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling scope of service token")
+	}
+	return
+}
+
+func scopeName(name string) string {
+	return strings.ToLower(regEcosystemPrefix.ReplaceAllString(strings.TrimSpace(name), ``))
+}
+
+func inScope(list []string, name string) bool {
+	name = scopeName(name)
+	for _, item := range list {
+		if scopeName(item) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// denied returns the description of the denied action or empty string if the route is allowed
+func (scope *tokenScope) denied(route string, params map[string]interface{}) string {
+	if contractRoutes[route] {
+		name, _ := params[`name`].(string)
+		if scope.Access != accessContract || (len(scope.Contracts) > 0 && !inScope(scope.Contracts, name)) {
+			return `contract ` + name
+		}
+		return ``
+	}
+	if par, ok := tableRoutes[route]; ok {
+		name, _ := params[par].(string)
+		if len(scope.Tables) > 0 && !inScope(scope.Tables, name) {
+			return `table ` + name
+		}
+		return ``
+	}
+	if readRoutes[route] {
+		// templates, GraphQL, the explorer and the history of transactions can read any table
+		if len(scope.Tables) > 0 {
+			return route
+		}
+		return ``
+	}
+	if strings.HasPrefix(route, `GET `) && route != `GET tokens` {
+		return ``
+	}
+	return route
+}
+
+// checkServiceToken checks the revocation, the expiration and the scope of the service token
+func checkServiceToken(w http.ResponseWriter, data *apiData, logger *log.Entry) error {
+	token := data.service
+	revoked, err := isRevoked(token.ID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("loading revoked service tokens")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	if revoked || token.Revoked > 0 {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "token_id": token.ID}).Warning("service token is revoked")
+		return errorAPI(w, `E_UNAUTHORIZED`, http.StatusUnauthorized)
+	}
+	if now := time.Now().Unix(); token.Expire > 0 && now > token.Expire {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "token_id": token.ID}).Warning("service token is expired")
+		return errorAPI(w, `E_TOKENEXPIRED`, http.StatusUnauthorized,
+			(time.Duration(now-token.Expire) * time.Second).String())
+	}
+	scope := parseScope(token.Scope)
+	if action := scope.denied(data.route, data.params); len(action) > 0 {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "token_id": token.ID, "action": action}).Warning("service token scope")
+		return errorAPI(w, `E_SCOPE`, http.StatusForbidden, action)
+	}
+	return nil
+}
+
+func splitList(value string) (list []string) {
+	for _, item := range strings.Split(value, `,`) {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
+func tokenInfo(token *model.APIToken) apiTokenInfo {
+	return apiTokenInfo{ID: token.ID, Name: token.Name, Scope: parseScope(token.Scope), Created: token.Created,
+		Expire: token.Expire, Revoked: token.Revoked}
+}
+
+// createAPIToken creates the service token. The request must be signed by the key of the account
+func createAPIToken(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	name := strings.TrimSpace(data.ParamString(`name`))
+	access := data.ParamString(`access`)
+	if len(access) == 0 {
+		access = accessRead
+	}
+	if len(name) == 0 || len(name) > 255 {
+		return errorAPI(w, `E_UNDEFINEVAL`, http.StatusBadRequest, `name`)
+	}
+	if access != accessRead && access != accessContract {
+		return errorAPI(w, fmt.Sprintf(`access must be %s or %s`, accessRead, accessContract), http.StatusBadRequest)
+	}
+	if access != accessContract && len(data.ParamString(`contracts`)) > 0 {
+		return errorAPI(w, `contracts require the contract access`, http.StatusBadRequest)
+	}
+	expire := data.ParamInt64(`expire`)
+	if expire < 0 {
+		return errorAPI(w, `expire must not be negative`, http.StatusBadRequest)
+	}
+	now := time.Now().Unix()
+	if diff := now - converter.StrToInt64(data.ParamString(`time`)); diff > apiTokenTimeGap || diff < -apiTokenTimeGap {
+		logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "time": data.ParamString(`time`)}).Error("time of service token request")
+		return errorAPI(w, `E_SIGNATURE`, http.StatusBadRequest)
+	}
+
+	pubkey, err := model.Single(`select pub from "`+converter.Int64ToStr(data.ecosystemId)+`_keys" where id=?`, data.keyId).Bytes()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting public key from keys")
+		return errorAPI(w, err, http.StatusBadRequest)
+	}
+	if len(pubkey) == 0 {
+		logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("public key is empty")
+		return errorAPI(w, `E_EMPTYPUBLIC`, http.StatusBadRequest)
+	}
+	msg := fmt.Sprintf(consts.APITokenSignFormat, name, access, data.ParamString(`contracts`),
+		data.ParamString(`tables`), expire, data.ParamString(`time`))
+	verify, err := crypto.CheckSign(pubkey, msg, data.params[`signature`].([]byte))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "msg": msg, "error": err}).Error("checking signature")
+		return errorAPI(w, err, http.StatusBadRequest)
+	}
+	if !verify {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "msg": msg}).Error("incorrect signature")
+		return errorAPI(w, `E_SIGNATURE`, http.StatusBadRequest)
+	}
+
+	scope, err := json.Marshal(tokenScope{Access: access, Contracts: splitList(data.ParamString(`contracts`)),
+		Tables: splitList(data.ParamString(`tables`))})
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling scope")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	secret, err := generateAPIToken()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("generating service token")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	token := &model.APIToken{EcosystemID: data.ecosystemId, KeyID: data.keyId, Name: name,
+		Hash: hashAPIToken(secret), Scope: string(scope), Created: now}
+	if expire > 0 {
+		token.Expire = now + expire
+	}
+	if err = token.Create(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating service token")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	logger.WithFields(log.Fields{"token_id": token.ID, "key_id": data.keyId, "scope": token.Scope}).Info("service token has been created")
+	info := tokenInfo(token)
+	info.Token = secret
+	data.result = &info
+	return nil
+}
+
+func getAPITokens(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	tokens, err := model.GetAPITokens(data.ecosystemId, data.keyId)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting service tokens")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	result := apiTokensResult{List: make([]apiTokenInfo, 0, len(tokens))}
+	for i := range tokens {
+		result.List = append(result.List, tokenInfo(&tokens[i]))
+	}
+	data.result = &result
+	return nil
+}
+
+func revokeAPIToken(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	id := converter.StrToInt64(data.ParamString(`id`))
+	now := time.Now().Unix()
+	found, err := model.RevokeAPIToken(id, data.ecosystemId, data.keyId, now)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("revoking service token")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	if !found {
+		logger.WithFields(log.Fields{"type": consts.NotFound, "token_id": id}).Error("service token not found")
+		return errorAPI(w, `E_NOTFOUND`, http.StatusNotFound)
+	}
+	setRevoked(id)
+	logger.WithFields(log.Fields{"token_id": id, "key_id": data.keyId}).Info("service token has been revoked")
+	data.result = &revokeResult{ID: id, Revoked: now}
+	return nil
+}
@@ -0,0 +1,83 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+)
+
+func TestTokenScope(t *testing.T) {
+	read := tokenScope{Access: accessRead, Tables: []string{`keys`, `1_pages`}}
+	cnt := tokenScope{Access: accessContract, Contracts: []string{`@1NewPage`}}
+	all := tokenScope{Access: accessRead}
+	cases := []struct {
+		scope   tokenScope
+		route   string
+		params  map[string]interface{}
+		allowed bool
+	}{
+		{read, `GET list/:name`, map[string]interface{}{`name`: `keys`}, true},
+		{read, `GET row/:name/:id`, map[string]interface{}{`name`: `pages`}, true},
+		{read, `GET history/:table/:id`, map[string]interface{}{`table`: `1_Keys`}, true},
+		{read, `GET list/:name`, map[string]interface{}{`name`: `members`}, false},
+		{read, `GET tablehash/:name`, map[string]interface{}{`name`: `keys`}, true},
+		{read, `GET tablehash/:name`, map[string]interface{}{`name`: `members`}, false},
+		{read, `POST content/page/:name`, nil, false},
+		{all, `POST content/page/:name`, nil, true},
+		{all, `GET list/:name`, map[string]interface{}{`name`: `members`}, true},
+		{all, `GET ecosystems`, nil, true},
+		{all, `GET explorer/tx/:hash`, nil, true},
+		{read, `GET explorer/block/:id`, nil, false},
+		{read, `GET txhistory/key/:key`, nil, false},
+		{all, `POST prepare/:name`, map[string]interface{}{`name`: `NewPage`}, false},
+		{all, `GET tokens`, nil, false},
+		{all, `POST tokens`, nil, false},
+		{all, `POST vde/create`, nil, false},
+		{cnt, `POST prepare/:name`, map[string]interface{}{`name`: `NewPage`}, true},
+		{cnt, `POST contract/:name`, map[string]interface{}{`name`: `@1NewPage`}, true},
+		{cnt, `POST contract/:name`, map[string]interface{}{`name`: `NewMenu`}, false},
+		{cnt, `POST tokens/:id/revoke`, nil, false},
+	}
+	for i, item := range cases {
+		if denied := item.scope.denied(item.route, item.params); (len(denied) == 0) != item.allowed {
+			t.Errorf(`%d %s must be allowed %v, denied %q`, i, item.route, item.allowed, denied)
+		}
+	}
+}
+
+func TestGenerateAPIToken(t *testing.T) {
+	first, err := generateAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := generateAPIToken()
+	if !strings.HasPrefix(first, consts.APITokenPrefix) || first == second {
+		t.Errorf(`wrong tokens %s %s`, first, second)
+	}
+	if len(hashAPIToken(first)) != 64 || hashAPIToken(first) == hashAPIToken(second) {
+		t.Error(`wrong hash of token`)
+	}
+}
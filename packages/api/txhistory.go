@@ -0,0 +1,165 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	txHistoryLimit    = 25
+	txHistoryMaxLimit = 1000
+)
+
+type txHistoryItem struct {
+	Hash      string          `json:"hash"`
+	BlockID   int64           `json:"block_id"`
+	Time      int64           `json:"time"`
+	KeyID     string          `json:"key_id"`
+	Address   string          `json:"address"`
+	Ecosystem int64           `json:"ecosystem"`
+	Contract  string          `json:"contract"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    string          `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Fuel      string          `json:"fuel,omitempty"`
+}
+
+type txHistoryResult struct {
+	List   []txHistoryItem `json:"list"`
+	Cursor string          `json:"cursor,omitempty"` // the cursor of the next page
+}
+
+func historyItem(th *model.TxHistory) txHistoryItem {
+	item := txHistoryItem{Hash: hex.EncodeToString(th.Hash), BlockID: th.BlockID, Time: th.Time,
+		KeyID: converter.Int64ToStr(th.KeyID), Address: converter.AddressToString(th.KeyID),
+		Ecosystem: th.Ecosystem, Contract: th.Contract, Result: th.Result, Error: th.Error}
+	if th.Fuel.Sign() > 0 {
+		item.Fuel = th.Fuel.String()
+	}
+	if len(th.Params) > 0 {
+		item.Params = json.RawMessage(th.Params)
+	}
+	return item
+}
+
+// contractName returns the full name of the contract with the ecosystem, e.g. @1NewPage
+func contractName(name string, ecosystem int64) string {
+	if len(name) == 0 || strings.HasPrefix(name, `@`) {
+		return name
+	}
+	return `@` + converter.Int64ToStr(ecosystem) + name
+}
+
+func txHistory(w http.ResponseWriter, data *apiData, filter model.TxHistoryFilter, logger *log.Entry) error {
+	if cursor := data.ParamString(`cursor`); len(cursor) > 0 {
+		before, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return errorAPI(w, `E_FILTER`, http.StatusBadRequest, `cursor is not valid`)
+		}
+		filter.Before = before
+	}
+	limit := int(data.ParamInt64(`limit`))
+	if limit <= 0 {
+		limit = txHistoryLimit
+	} else if limit > txHistoryMaxLimit {
+		limit = txHistoryMaxLimit
+	}
+	list, err := model.GetTxHistory(filter, limit)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting transaction history")
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+	}
+	result := txHistoryResult{List: make([]txHistoryItem, len(list))}
+	for i := range list {
+		result.List[i] = historyItem(&list[i])
+	}
+	if len(list) == limit {
+		result.Cursor = converter.Int64ToStr(list[len(list)-1].ID)
+	}
+	data.result = &result
+	return nil
+}
+
+// txHistoryByKey returns the transactions which have been sent by the key
+func txHistoryByKey(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	keyID := converter.StringToAddress(data.ParamString(`key`))
+	if keyID == 0 {
+		return errorAPI(w, `E_INVALIDWALLET`, http.StatusBadRequest, data.ParamString(`key`))
+	}
+	ecosystem := data.ParamInt64(`ecosystem`)
+	// the transactions of all ecosystems are returned without the ecosystem,
+	// but the contract name without the prefix belongs to the ecosystem of the client
+	contractEcosystem := ecosystem
+	if contractEcosystem == 0 {
+		contractEcosystem = data.ecosystemId
+	}
+	return txHistory(w, data, model.TxHistoryFilter{KeyID: keyID, Ecosystem: ecosystem,
+		Contract: contractName(data.ParamString(`contract`), contractEcosystem)}, logger)
+}
+
+// txHistoryByContract returns the calls of the contract
+func txHistoryByContract(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	ecosystem := data.ParamInt64(`ecosystem`)
+	if ecosystem == 0 {
+		ecosystem = data.ecosystemId
+	}
+	filter := model.TxHistoryFilter{Contract: contractName(data.ParamString(`name`), ecosystem)}
+	if key := data.ParamString(`key`); len(key) > 0 {
+		if filter.KeyID = converter.StringToAddress(key); filter.KeyID == 0 {
+			return errorAPI(w, `E_INVALIDWALLET`, http.StatusBadRequest, key)
+		}
+	}
+	return txHistory(w, data, filter, logger)
+}
+
+// txHistoryByHash returns the transaction from the history
+func txHistoryByHash(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	hash, err := hex.DecodeString(data.ParamString(`hash`))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding tx hash from hex")
+		return errorAPI(w, `E_HASHWRONG`, http.StatusBadRequest)
+	}
+	th := &model.TxHistory{}
+	found, err := th.GetByHash(hash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting transaction history by hash")
+		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
+	}
+	if !found {
+		return errorAPI(w, `E_HASHNOTFOUND`, http.StatusNotFound)
+	}
+	item := historyItem(th)
+	data.result = &item
+	return nil
+}
@@ -0,0 +1,108 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+)
+
+func TestTxHistoryItem(t *testing.T) {
+	if name := contractName(`NewPage`, 2); name != `@2NewPage` {
+		t.Errorf(`wrong name %s`, name)
+	}
+	if name := contractName(`@1NewPage`, 2); name != `@1NewPage` {
+		t.Errorf(`wrong name %s`, name)
+	}
+	item := historyItem(&model.TxHistory{Hash: []byte{1, 2}, KeyID: -1, Contract: `@1NewPage`,
+		Params: `{"Name":"test"}`})
+	if item.Hash != `0102` || item.KeyID != `-1` || string(item.Params) != `{"Name":"test"}` {
+		t.Errorf(`wrong item %+v`, item)
+	}
+	if item = historyItem(&model.TxHistory{}); item.Params != nil {
+		t.Errorf(`empty params must be omitted %s`, item.Params)
+	}
+}
+
+// TestTxHistoryRepeatedTx sends the same signed transaction twice, at first it fails
+// and then it is applied in the other block
+func TestTxHistoryRepeatedTx(t *testing.T) {
+	if err := keyLogin(1); err != nil {
+		t.Fatal(err)
+	}
+	rnd := `rnd` + crypto.RandSeq(6)
+	form := url.Values{`Value`: {`contract ` + rnd + ` {
+			data {
+				Par string
+			}
+			action {
+				if EcosysParam($Par) == "" {
+					error "parameter has not been found"
+				}
+			}}`}, `Conditions`: {`true`}}
+	if err := postTx(`NewContract`, &form); err != nil {
+		t.Fatal(err)
+	}
+
+	form = url.Values{`Par`: {rnd}}
+	ret := make(map[string]interface{})
+	if err := sendPost(`prepare/`+rnd, &form, &ret); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendSign(ret, &form); err != nil {
+		t.Fatal(err)
+	}
+	send := func() string {
+		ret := make(map[string]interface{})
+		if err := sendPost(`contract/`+rnd, &form, &ret); err != nil {
+			t.Fatal(err)
+		}
+		return ret[`hash`].(string)
+	}
+	hash := send()
+	if _, err := waitTx(hash); err == nil {
+		t.Fatal(`the transaction must fail without the parameter`)
+	}
+
+	if err := postTx(`NewParameter`, &url.Values{`Name`: {rnd}, `Value`: {`1`},
+		`Conditions`: {`true`}}); err != nil {
+		t.Fatal(err)
+	}
+	if again := send(); again != hash {
+		t.Fatalf(`the hash of the same transaction %s != %s`, again, hash)
+	}
+	blockID, _ := waitTx(hash)
+	if blockID == 0 {
+		t.Fatal(`the transaction must be applied`)
+	}
+	var item txHistoryItem
+	if err := sendGet(`txhistory/tx/`+hash, nil, &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.BlockID != blockID || len(item.Error) > 0 {
+		t.Errorf(`wrong history of the applied transaction %+v`, item)
+	}
+}
@@ -193,5 +193,6 @@ func VDEContract(contractData []byte, data *apiData) (result *contractResult, er
 			result.Message = &txstatusError{Type: "panic", Error: errResult.Error()}
 		}
 	}
+	result.HTTP = sc.HTTPRecords
 	return
 }
@@ -0,0 +1,354 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package archive stores the bodies of old blocks in compressed files. The records have the same
+// format as the reserved blockchain file of the node
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+Block record format:
+ block len - 5 bytes
+ block id - 5 bytes
+ block data len - variable
+ block data - block data len bytes
+ full len - 5 bytes (for read from end of file)
+*/
+
+const (
+	// WordSize is size of word in file
+	WordSize = 5
+
+	fileFormat = `blocks_%d_%d.gz`
+
+	// MaxRecordSize protects from the damaged files, the blocks are checked by max_block_size
+	// before they are archived
+	MaxRecordSize = 1 << 30
+)
+
+// gzipMagic is the beginning of compressed files. The uncompressed files begin with the size of the record
+// whose first byte is zero
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Block is the record of the block file
+type Block struct {
+	ID   int64
+	Data []byte
+}
+
+// MarshalBlock returns the binary record of the block
+func MarshalBlock(b Block) []byte {
+	data := append(converter.DecToBin(b.ID, WordSize), converter.EncodeLengthPlusData(b.Data)...)
+	sizeAndData := append(converter.DecToBin(len(data), WordSize), data...)
+	return append(sizeAndData, converter.DecToBin(len(sizeAndData), WordSize)...)
+}
+
+// UnmarshalBlock parses the record of the block without the leading size
+func UnmarshalBlock(buff []byte) (Block, error) {
+	if len(buff) < WordSize {
+		return Block{}, fmt.Errorf(`bad block record`)
+	}
+	blockID := converter.BinToDec(buff[:WordSize])
+	buff = buff[WordSize:]
+
+	// DecodeLength moves the pointer to the data field
+	blockDataLen, err := converter.DecodeLength(&buff)
+	if err != nil {
+		return Block{}, err
+	}
+	if blockDataLen > int64(len(buff)) {
+		return Block{}, fmt.Errorf(`bad length %d of block %d`, blockDataLen, blockID)
+	}
+	return Block{ID: blockID, Data: buff[:blockDataLen]}, nil
+}
+
+// ReadBlock reads the next record from r. It returns nil if there are no more records
+func ReadBlock(r io.Reader, maxSize int64) (*Block, error) {
+	buf := make([]byte, WordSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	size := converter.BinToDec(buf)
+	if size > maxSize {
+		return nil, fmt.Errorf(`block size %d is more than max size %d`, size, maxSize)
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	data := make([]byte, size+WordSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	block, err := UnmarshalBlock(data)
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// fileRange is the range of blocks of the archive file
+type fileRange struct {
+	First, Last int64
+	Name        string
+}
+
+var index struct {
+	sync.Mutex
+	dir  string
+	list []fileRange
+}
+
+// scanDir returns the ranges of the archive files of the directory sorted by blocks
+func scanDir(dir string) ([]fileRange, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	list := make([]fileRange, 0, len(files))
+	for _, file := range files {
+		var item fileRange
+		if n, _ := fmt.Sscanf(file.Name(), fileFormat, &item.First, &item.Last); n != 2 ||
+			file.Name() != fmt.Sprintf(fileFormat, item.First, item.Last) {
+			continue
+		}
+		item.Name = filepath.Join(dir, file.Name())
+		list = append(list, item)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].First < list[j].First })
+	return list, nil
+}
+
+// findFile returns the name of the archive file which contains the block
+func findFile(dir string, blockID int64) (string, error) {
+	index.Lock()
+	defer index.Unlock()
+
+	find := func() string {
+		i := sort.Search(len(index.list), func(i int) bool { return index.list[i].Last >= blockID })
+		if i < len(index.list) && index.list[i].First <= blockID {
+			return index.list[i].Name
+		}
+		return ``
+	}
+	if index.dir == dir {
+		if name := find(); len(name) > 0 {
+			return name, nil
+		}
+	}
+	list, err := scanDir(dir)
+	if err != nil {
+		return ``, err
+	}
+	index.dir, index.list = dir, list
+	return find(), nil
+}
+
+// LastBlockID returns the id of the last archived block
+func LastBlockID(dir string) (int64, error) {
+	list, err := scanDir(dir)
+	if err != nil || len(list) == 0 {
+		return 0, err
+	}
+	return list[len(list)-1].Last, nil
+}
+
+// Writer writes the block records to the file, the records are compressed by gzip optionally
+type Writer struct {
+	file *os.File
+	zw   *gzip.Writer
+	w    io.Writer
+}
+
+func newWriter(file *os.File, compress bool) *Writer {
+	w := &Writer{file: file, w: file}
+	if compress {
+		w.zw = gzip.NewWriter(file)
+		w.w = w.zw
+	}
+	return w
+}
+
+// NewWriter creates the file of block records
+func NewWriter(name string, compress bool) (*Writer, error) {
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return newWriter(file, compress), nil
+}
+
+// Write appends the record of the block
+func (w *Writer) Write(b Block) error {
+	_, err := w.w.Write(MarshalBlock(b))
+	return err
+}
+
+// Close flushes the records to the disk and closes the file
+func (w *Writer) Close() (err error) {
+	if w.zw != nil {
+		err = w.zw.Close()
+	}
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return
+}
+
+// WriteFile writes the consecutive blocks to the new compressed file of the directory
+func WriteFile(dir string, blocks []Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	logger := log.WithFields(log.Fields{"first_block_id": blocks[0].ID, "last_block_id": blocks[len(blocks)-1].ID})
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "dir": dir}).Error("creating archive directory")
+		return err
+	}
+	name := filepath.Join(dir, fmt.Sprintf(fileFormat, blocks[0].ID, blocks[len(blocks)-1].ID))
+	file, err := ioutil.TempFile(dir, `tmp_blocks_`)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "dir": dir}).Error("creating archive file")
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	w := newWriter(file, true)
+	for _, b := range blocks {
+		if err = w.Write(b); err != nil {
+			break
+		}
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), name)
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "file": name}).Error("writing archive file")
+		return err
+	}
+	return nil
+}
+
+// ReadFile calls handle for each block of the file until it returns false.
+// The file can be compressed by gzip
+func ReadFile(name string, maxSize int64, handle func(*Block) bool) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var r io.Reader = bufio.NewReader(file)
+	if magic, _ := r.(*bufio.Reader).Peek(2); bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	for {
+		block, err := ReadBlock(r, maxSize)
+		if err != nil || block == nil {
+			return err
+		}
+		if !handle(block) {
+			return nil
+		}
+	}
+}
+
+// GetBlockData returns the body of the archived block or nil if the block has not been archived
+func GetBlockData(blockID int64) ([]byte, error) {
+	data, err := GetBlocksData([]int64{blockID})
+	if err != nil {
+		return nil, err
+	}
+	return data[blockID], nil
+}
+
+// GetBlocksData returns the bodies of the archived blocks, each archive file is read once
+func GetBlocksData(ids []int64) (map[int64][]byte, error) {
+	result := make(map[int64][]byte)
+	dir := conf.Config.Prune.ArchiveDir
+	if len(dir) == 0 || len(ids) == 0 {
+		return result, nil
+	}
+	files := make(map[string]map[int64]bool)
+	for _, id := range ids {
+		name, err := findFile(dir, id)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "dir": dir}).Error("reading archive directory")
+			return nil, err
+		}
+		if len(name) == 0 {
+			continue
+		}
+		if files[name] == nil {
+			files[name] = make(map[int64]bool)
+		}
+		files[name][id] = true
+	}
+	for name, blocks := range files {
+		left := len(blocks)
+		err := ReadFile(name, MaxRecordSize, func(block *Block) bool {
+			if blocks[block.ID] {
+				result[block.ID] = block.Data
+				left--
+			}
+			return left > 0
+		})
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "file": name}).Error("reading archive file")
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+)
+
+func TestBlockRecord(t *testing.T) {
+	var buf bytes.Buffer
+	for _, b := range []Block{{ID: 1, Data: []byte(`first`)}, {ID: 2, Data: []byte(`second`)}} {
+		buf.Write(MarshalBlock(b))
+	}
+	for _, want := range []string{`first`, `second`} {
+		block, err := ReadBlock(&buf, 100)
+		if err != nil || block == nil || string(block.Data) != want {
+			t.Fatalf(`wrong block %v %v`, block, err)
+		}
+	}
+	if block, err := ReadBlock(&buf, 100); block != nil || err != nil {
+		t.Errorf(`the end must be reached %v %v`, block, err)
+	}
+	if _, err := ReadBlock(bytes.NewReader(MarshalBlock(Block{ID: 3, Data: make([]byte, 200)})), 100); err == nil {
+		t.Error(`big block must be rejected`)
+	}
+}
+
+func TestArchiveFiles(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `archive`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(prev string) { conf.Config.Prune.ArchiveDir = prev }(conf.Config.Prune.ArchiveDir)
+	conf.Config.Prune.ArchiveDir = dir
+
+	for first := int64(1); first < 7; first += 3 {
+		var blocks []Block
+		for id := first; id < first+3; id++ {
+			blocks = append(blocks, Block{ID: id, Data: []byte{byte(id)}})
+		}
+		if err = WriteFile(dir, blocks); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if last, err := LastBlockID(dir); err != nil || last != 6 {
+		t.Errorf(`wrong last block %d %v`, last, err)
+	}
+	data, err := GetBlocksData([]int64{2, 5, 6, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 3 || data[2][0] != 2 || data[5][0] != 5 || data[6][0] != 6 {
+		t.Errorf(`wrong blocks %v`, data)
+	}
+	if block, err := GetBlockData(4); err != nil || len(block) != 1 || block[0] != 4 {
+		t.Errorf(`wrong block %v %v`, block, err)
+	}
+}
+
+func TestWriterFile(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `archive`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, compress := range []bool{false, true} {
+		name := filepath.Join(dir, fmt.Sprintf(`blocks_%t`, compress))
+		w, err := NewWriter(name, compress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for id := int64(1); id <= 3; id++ {
+			if err = w.Write(Block{ID: id, Data: []byte{byte(id)}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		err = ReadFile(name, 100, func(b *Block) bool {
+			ids = append(ids, b.ID)
+			return b.ID < 2
+		})
+		if err != nil || len(ids) != 2 || ids[1] != 2 {
+			t.Errorf(`compress %t: wrong blocks %v %v`, compress, ids, err)
+		}
+	}
+}
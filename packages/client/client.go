@@ -0,0 +1,268 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package client is the client of the REST API of the node. It logs in with the signer,
+// refreshes the JWT token automatically, sends contracts and queries tables
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+)
+
+const (
+	// DefaultTokenExpire is the lifetime of the token which is requested on login, in seconds
+	DefaultTokenExpire = 3600
+	// refreshBefore is the time before the expiration of the token when it is refreshed
+	refreshBefore = time.Minute
+
+	authPrefix = `Bearer `
+)
+
+// Error is the error which has been returned by API
+type Error struct {
+	Status int      `json:"-"`
+	Code   string   `json:"error"`
+	Msg    string   `json:"msg"`
+	Params []string `json:"params"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf(`%d %s %s`, e.Status, e.Code, e.Msg)
+}
+
+// Client is the client of the node API. It is safe for concurrent use
+type Client struct {
+	URL         string // the address of the node, e.g. http://127.0.0.1:7079
+	HTTPClient  *http.Client
+	Signer      Signer
+	Ecosystem   int64
+	TokenExpire int64  // in seconds, DefaultTokenExpire is used if it is 0
+	VDE         bool   // the requests are sent to the virtual dedicated ecosystem
+	APIToken    string // the scoped service token, it is used instead of login if it is defined
+
+	mutex   sync.Mutex
+	token   string
+	refresh string
+	expire  time.Time
+	account Account
+}
+
+// Account is the account of the logged in client
+type Account struct {
+	EcosystemID string `json:"ecosystem_id"`
+	KeyID       string `json:"key_id"`
+	Address     string `json:"address"`
+	NotifyKey   string `json:"notify_key"`
+	IsNode      bool   `json:"isnode"`
+	IsOwner     bool   `json:"isowner"`
+	IsVDE       bool   `json:"vde"`
+}
+
+type tokenResult struct {
+	UID     string `json:"uid"`
+	Token   string `json:"token"`
+	Refresh string `json:"refresh"`
+	Account
+}
+
+// New returns the client of the node with the address, e.g. http://127.0.0.1:7079
+func New(nodeURL string, signer Signer) *Client {
+	return &Client{URL: strings.TrimSuffix(nodeURL, `/`), HTTPClient: http.DefaultClient, Signer: signer, Ecosystem: 1}
+}
+
+// NewWithToken returns the client which is authorized with the scoped service token
+func NewWithToken(nodeURL, apiToken string) *Client {
+	client := New(nodeURL, nil)
+	client.APIToken = apiToken
+	return client
+}
+
+// Account returns the account of the logged in client
+func (c *Client) Account() Account {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.account
+}
+
+func (c *Client) tokenExpire() int64 {
+	if c.TokenExpire > 0 {
+		return c.TokenExpire
+	}
+	return DefaultTokenExpire
+}
+
+func (c *Client) send(method, path string, form url.Values, token string, result interface{}) error {
+	var (
+		req *http.Request
+		err error
+	)
+	addr := c.URL + consts.ApiPath + path
+	if method == `GET` {
+		if len(form) > 0 {
+			addr += `?` + form.Encode()
+		}
+		req, err = http.NewRequest(method, addr, nil)
+	} else {
+		req, err = http.NewRequest(method, addr, strings.NewReader(form.Encode()))
+	}
+	if err != nil {
+		return err
+	}
+	if method != `GET` {
+		req.Header.Set(`Content-Type`, `application/x-www-form-urlencoded`)
+	}
+	if len(token) > 0 {
+		req.Header.Set(`Authorization`, authPrefix+token)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &Error{Status: resp.StatusCode}
+		if json.Unmarshal(data, apiErr) != nil || len(apiErr.Code) == 0 {
+			apiErr.Code, apiErr.Msg = `E_SERVER`, strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+// Login gets uid, signs it and logs in the ecosystem of the client
+func (c *Client) Login() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.login()
+}
+
+func (c *Client) login() error {
+	if c.Signer == nil {
+		return fmt.Errorf(`signer is not defined`)
+	}
+	var uid tokenResult
+	if err := c.send(`GET`, `getuid`, nil, ``, &uid); err != nil {
+		return err
+	}
+	if len(uid.UID) == 0 {
+		return fmt.Errorf(`getuid has returned empty uid`)
+	}
+	sign, err := c.Signer.Sign(uid.UID)
+	if err != nil {
+		return err
+	}
+	pub, err := c.Signer.PublicKey()
+	if err != nil {
+		return err
+	}
+	var ret tokenResult
+	expire := c.tokenExpire()
+	form := url.Values{`pubkey`: {hex.EncodeToString(pub)}, `signature`: {hex.EncodeToString(sign)},
+		`ecosystem`: {strconv.FormatInt(c.Ecosystem, 10)}, `expire`: {strconv.FormatInt(expire, 10)}}
+	if err = c.send(`POST`, `login`, form, uid.Token, &ret); err != nil {
+		return err
+	}
+	c.setToken(ret, expire)
+	c.account = ret.Account
+	return nil
+}
+
+func (c *Client) setToken(ret tokenResult, expire int64) {
+	c.token, c.refresh = ret.Token, ret.Refresh
+	c.expire = time.Now().Add(time.Duration(expire) * time.Second)
+}
+
+// authToken returns the valid token. It refreshes the token before the expiration
+// or logs in again if the token can't be refreshed
+func (c *Client) authToken(force bool) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.APIToken) > 0 {
+		return c.APIToken, nil
+	}
+	now := time.Now()
+	if !force && len(c.token) > 0 && now.Add(refreshBefore).Before(c.expire) {
+		return c.token, nil
+	}
+	if len(c.refresh) > 0 && now.Before(c.expire) {
+		var ret tokenResult
+		expire := c.tokenExpire()
+		form := url.Values{`token`: {c.refresh}, `expire`: {strconv.FormatInt(expire, 10)}}
+		if err := c.send(`POST`, `refresh`, form, c.token, &ret); err == nil {
+			c.setToken(ret, expire)
+			return c.token, nil
+		}
+	}
+	if err := c.login(); err != nil {
+		return ``, err
+	}
+	return c.token, nil
+}
+
+// Do sends the authorized request to API and unmarshals the answer to result.
+// The path is relative to /api/v2/, e.g. "list/keys"
+func (c *Client) Do(method, path string, form url.Values, result interface{}) error {
+	if c.VDE {
+		form = copyValues(form)
+		form.Set(`vde`, `true`)
+	}
+	token, err := c.authToken(false)
+	if err != nil {
+		return err
+	}
+	err = c.send(method, path, form, token, result)
+	if apiErr, ok := err.(*Error); ok && apiErr.Status == http.StatusUnauthorized && len(c.APIToken) == 0 {
+		if token, err = c.authToken(true); err != nil {
+			return err
+		}
+		err = c.send(method, path, form, token, result)
+	}
+	return err
+}
+
+// Get sends the authorized GET request
+func (c *Client) Get(path string, form url.Values, result interface{}) error {
+	return c.Do(`GET`, path, form, result)
+}
+
+// Post sends the authorized POST request
+func (c *Client) Post(path string, form url.Values, result interface{}) error {
+	return c.Do(`POST`, path, form, result)
+}
@@ -0,0 +1,174 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSigner is the signer which signs data with the prefix
+type testSigner struct{}
+
+func (testSigner) Sign(data string) ([]byte, error) {
+	return []byte(`sign:` + data), nil
+}
+
+func (testSigner) PublicKey() ([]byte, error) {
+	return []byte(`public`), nil
+}
+
+// testNode is the fake API which checks signatures and tokens
+type testNode struct {
+	token   string
+	logins  int
+	refresh int
+	status  int
+}
+
+func (n *testNode) reply(w http.ResponseWriter, v interface{}) {
+	json.NewEncoder(w).Encode(v)
+}
+
+func (n *testNode) checkSign(w http.ResponseWriter, data, sign string) bool {
+	if bin, _ := hex.DecodeString(sign); string(bin) != `sign:`+data {
+		w.WriteHeader(http.StatusBadRequest)
+		n.reply(w, Error{Code: `E_SIGNATURE`, Msg: `Signature is incorrect`})
+		return false
+	}
+	return true
+}
+
+func (n *testNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	path := strings.TrimPrefix(r.URL.Path, `/api/v2/`)
+	auth := strings.TrimPrefix(r.Header.Get(`Authorization`), authPrefix)
+	switch {
+	case path == `getuid`:
+		n.reply(w, tokenResult{UID: `uid123`, Token: `uidtoken`})
+		return
+	case path == `login`:
+		if auth != `uidtoken` || !n.checkSign(w, `uid123`, r.FormValue(`signature`)) {
+			return
+		}
+		n.logins++
+		n.token = fmt.Sprintf(`token%d`, n.logins)
+		n.reply(w, tokenResult{Token: n.token, Refresh: `refresh`, Account: Account{KeyID: `100`}})
+		return
+	}
+	if auth != n.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		n.reply(w, Error{Code: `E_TOKENEXPIRED`, Msg: `Token is expired`})
+		return
+	}
+	switch path {
+	case `refresh`:
+		n.refresh++
+		n.token = fmt.Sprintf(`refreshed%d`, n.refresh)
+		n.reply(w, tokenResult{Token: n.token, Refresh: `refresh`})
+	case `prepare/Test`:
+		n.reply(w, Prepared{ForSign: `forsign`, Time: `1000`, Signs: []TxSign{{ForSign: `extra`, Field: `Sign`}}})
+	case `contract/Test`:
+		if r.FormValue(`time`) != `1000` || r.FormValue(`Value`) != `1` ||
+			!n.checkSign(w, `extra`, r.FormValue(`Sign`)) ||
+			!n.checkSign(w, `forsign,`+r.FormValue(`Sign`), r.FormValue(`signature`)) {
+			return
+		}
+		n.reply(w, ContractResult{Hash: `abcd`})
+	case `txstatus/abcd`:
+		n.status++
+		if n.status < 2 {
+			n.reply(w, TxStatus{})
+			return
+		}
+		n.reply(w, TxStatus{BlockID: `5`, Result: `ok`})
+	case `list/keys`:
+		if r.FormValue(`limit`) != `10` || r.FormValue(`columns`) != `amount` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n.reply(w, map[string]interface{}{`count`: `1`, `list`: []map[string]string{{`id`: `100`, `amount`: `5`}}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		n.reply(w, Error{Code: `E_NOTFOUND`, Msg: `Page not found`})
+	}
+}
+
+func TestClient(t *testing.T) {
+	node := &testNode{}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	TxWaitInterval = time.Millisecond
+	c := New(server.URL, testSigner{})
+	list, err := c.List(`keys`, ListParams{Limit: 10, Columns: []string{`amount`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Count != 1 || list.List[0][`amount`] != `5` || c.Account().KeyID != `100` {
+		t.Errorf(`wrong list %+v`, list)
+	}
+
+	status, err := c.CallContract(`Test`, url.Values{`Value`: {`1`}}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.BlockID != `5` || node.logins != 1 {
+		t.Errorf(`wrong status %+v %d`, status, node.logins)
+	}
+
+	// the token is refreshed because it expires sooner than refreshBefore
+	c.mutex.Lock()
+	c.expire = time.Now().Add(time.Second)
+	c.mutex.Unlock()
+	if _, err = c.TxStatus(`abcd`); err != nil || node.refresh != 1 {
+		t.Errorf(`token has not been refreshed %v`, err)
+	}
+
+	// the client logs in again if the token has been rejected
+	node.token = `other`
+	if _, err = c.TxStatus(`abcd`); err != nil || node.logins != 2 {
+		t.Errorf(`client has not logged in again %v`, err)
+	}
+
+	_, err = c.Table(`unknown`)
+	if apiErr, ok := err.(*Error); !ok || apiErr.Status != http.StatusNotFound || apiErr.Code != `E_NOTFOUND` {
+		t.Errorf(`wrong error %v`, err)
+	}
+}
+
+func TestClientBadURL(t *testing.T) {
+	c := New(`http://bad host`, nil)
+	for _, method := range []string{`GET`, `POST`} {
+		if err := c.send(method, `maxblockid`, url.Values{`a`: {`1`}}, ``, nil); err == nil {
+			t.Errorf(`%s must fail with wrong url`, method)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TxWaitInterval is the interval of txstatus requests while the transaction is waited
+var TxWaitInterval = time.Second
+
+// SignParam is the parameter which is shown to the user in the additional signature
+type SignParam struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// TxSign is the additional signature which is required by the contract
+type TxSign struct {
+	ForSign string      `json:"forsign"`
+	Field   string      `json:"field"`
+	Title   string      `json:"title"`
+	Params  []SignParam `json:"params"`
+}
+
+// Prepared is the contract which has been prepared for signing
+type Prepared struct {
+	ForSign string            `json:"forsign"`
+	Signs   []TxSign          `json:"signs"`
+	Values  map[string]string `json:"values"`
+	Time    string            `json:"time"`
+}
+
+// TxError is the error of the transaction
+type TxError struct {
+	Type string `json:"type,omitempty"`
+	Text string `json:"error,omitempty"`
+}
+
+func (e *TxError) Error() string {
+	return e.Type + `: ` + e.Text
+}
+
+// ContractResult is the answer of contract request. Result is filled for VDE contracts
+type ContractResult struct {
+	Hash    string   `json:"hash"`
+	Result  string   `json:"result,omitempty"`
+	Message *TxError `json:"errmsg,omitempty"`
+}
+
+// TxStatus is the status of the transaction
+type TxStatus struct {
+	BlockID string   `json:"blockid"`
+	Result  string   `json:"result"`
+	Message *TxError `json:"errmsg,omitempty"`
+}
+
+func copyValues(params url.Values) url.Values {
+	form := url.Values{}
+	for key, val := range params {
+		form[key] = append([]string{}, val...)
+	}
+	return form
+}
+
+// PrepareContract gets the data for signing of the contract with the specified parameters
+func (c *Client) PrepareContract(name string, params url.Values) (*Prepared, error) {
+	var prepared Prepared
+	if err := c.Post(`prepare/`+name, params, &prepared); err != nil {
+		return nil, err
+	}
+	return &prepared, nil
+}
+
+// SignContract signs the prepared contract and returns the parameters with signatures
+func (c *Client) SignContract(params url.Values, prepared *Prepared) (url.Values, error) {
+	if c.Signer == nil {
+		return nil, fmt.Errorf(`signer is not defined`)
+	}
+	form := copyValues(params)
+	forSign := prepared.ForSign
+	for _, item := range prepared.Signs {
+		sign, err := c.Signer.Sign(item.ForSign)
+		if err != nil {
+			return nil, err
+		}
+		form.Set(item.Field, hex.EncodeToString(sign))
+		forSign += `,` + hex.EncodeToString(sign)
+	}
+	sign, err := c.Signer.Sign(forSign)
+	if err != nil {
+		return nil, err
+	}
+	form.Set(`time`, prepared.Time)
+	form.Set(`signature`, hex.EncodeToString(sign))
+	return form, nil
+}
+
+// SendContract prepares, signs and sends the contract
+func (c *Client) SendContract(name string, params url.Values) (*ContractResult, error) {
+	prepared, err := c.PrepareContract(name, params)
+	if err != nil {
+		return nil, err
+	}
+	form, err := c.SignContract(params, prepared)
+	if err != nil {
+		return nil, err
+	}
+	var result ContractResult
+	if err = c.Post(`contract/`+name, form, &result); err != nil {
+		return nil, err
+	}
+	if result.Message != nil {
+		return &result, result.Message
+	}
+	return &result, nil
+}
+
+// CallContract sends the contract and waits until it is added to the block
+func (c *Client) CallContract(name string, params url.Values, timeout time.Duration) (*TxStatus, error) {
+	result, err := c.SendContract(name, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Hash) == 0 {
+		// VDE contracts are executed immediately
+		return &TxStatus{Result: result.Result}, nil
+	}
+	return c.WaitTx(result.Hash, timeout)
+}
+
+// TxStatus returns the current status of the transaction
+func (c *Client) TxStatus(hash string) (*TxStatus, error) {
+	var status TxStatus
+	if err := c.Get(`txstatus/`+hash, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// WaitTx waits until the transaction is added to the block or is rejected
+func (c *Client) WaitTx(hash string, timeout time.Duration) (*TxStatus, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := c.TxStatus(hash)
+		if err != nil {
+			return nil, err
+		}
+		if status.Message != nil {
+			return status, status.Message
+		}
+		if len(status.BlockID) > 0 {
+			return status, nil
+		}
+		if time.Now().Add(TxWaitInterval).After(deadline) {
+			return status, fmt.Errorf(`transaction %s has not been processed in %v`, hash, timeout)
+		}
+		time.Sleep(TxWaitInterval)
+	}
+}
@@ -0,0 +1,374 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Balance is the balance of the wallet
+type Balance struct {
+	Amount string `json:"amount"`
+	Money  string `json:"money"`
+}
+
+// TableInfo is the name of table and the number of its rows
+type TableInfo struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count,string"`
+}
+
+// Tables is the list of tables of the ecosystem
+type Tables struct {
+	Count int64       `json:"count"`
+	List  []TableInfo `json:"list"`
+}
+
+// Column is the column of the table
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Perm string `json:"perm"`
+}
+
+// Table is the description of the table
+type Table struct {
+	Name       string   `json:"name"`
+	Insert     string   `json:"insert"`
+	NewColumn  string   `json:"new_column"`
+	Update     string   `json:"update"`
+	Read       string   `json:"read,omitempty"`
+	Filter     string   `json:"filter,omitempty"`
+	Conditions string   `json:"conditions"`
+	Columns    []Column `json:"columns"`
+}
+
+// ListParams are the parameters of List request
+type ListParams struct {
+	Limit   int64
+	Offset  int64
+	Columns []string
+	// Where is the filter, e.g. {"name": "John", "amount": {"gt": 10}}.
+	// The operators are eq, neq, gt, gte, lt, lte, in and like
+	Where map[string]interface{}
+	// Order is the list of sorted columns, the column with minus is sorted descending
+	Order []string
+	// Cursor is the cursor of the previous List, Offset is ignored if it is defined
+	Cursor string
+	// Count is exact, estimate or none
+	Count string
+	// Block returns the rows as of the block, only id can be used in Where and Order with it
+	Block int64
+}
+
+// List is the rows of the table
+type List struct {
+	Count     int64               `json:"count,string"`
+	Estimated bool                `json:"estimated"`
+	Cursor    string              `json:"cursor"` // it is empty on the last page
+	List      []map[string]string `json:"list"`
+}
+
+// Balance returns the balance of the wallet in the ecosystem of the client
+func (c *Client) Balance(wallet string) (*Balance, error) {
+	var ret Balance
+	if err := c.Get(`balance/`+wallet, nil, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Tables returns the list of tables
+func (c *Client) Tables(limit, offset int64) (*Tables, error) {
+	var ret Tables
+	form := url.Values{`limit`: {strconv.FormatInt(limit, 10)}, `offset`: {strconv.FormatInt(offset, 10)}}
+	if err := c.Get(`tables`, form, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Table returns the description of the table
+func (c *Client) Table(name string) (*Table, error) {
+	var ret Table
+	if err := c.Get(`table/`+name, nil, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Row returns the row of the table, all columns are returned if columns are not specified
+func (c *Client) Row(table string, id int64, columns ...string) (map[string]string, error) {
+	return c.RowAsOf(table, id, 0, columns...)
+}
+
+// RowAsOf returns the row of the table as of the block, the current row is returned if block is 0.
+// The returned row is empty if the row did not exist at the block
+func (c *Client) RowAsOf(table string, id, block int64, columns ...string) (map[string]string, error) {
+	var ret struct {
+		Value map[string]string `json:"value"`
+	}
+	form := url.Values{}
+	if len(columns) > 0 {
+		form.Set(`columns`, strings.Join(columns, `,`))
+	}
+	if block > 0 {
+		form.Set(`block`, strconv.FormatInt(block, 10))
+	}
+	if err := c.Get(`row/`+table+`/`+strconv.FormatInt(id, 10), form, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Value, nil
+}
+
+// List returns the rows of the table
+func (c *Client) List(table string, params ListParams) (*List, error) {
+	var ret List
+	form := url.Values{}
+	if params.Limit > 0 {
+		form.Set(`limit`, strconv.FormatInt(params.Limit, 10))
+	}
+	if params.Offset > 0 {
+		form.Set(`offset`, strconv.FormatInt(params.Offset, 10))
+	}
+	if len(params.Columns) > 0 {
+		form.Set(`columns`, strings.Join(params.Columns, `,`))
+	}
+	if len(params.Where) > 0 {
+		where, err := json.Marshal(params.Where)
+		if err != nil {
+			return nil, err
+		}
+		form.Set(`where`, string(where))
+	}
+	if len(params.Order) > 0 {
+		form.Set(`order`, strings.Join(params.Order, `,`))
+	}
+	if len(params.Cursor) > 0 {
+		form.Set(`cursor`, params.Cursor)
+	}
+	if len(params.Count) > 0 {
+		form.Set(`count`, params.Count)
+	}
+	if params.Block > 0 {
+		form.Set(`block`, strconv.FormatInt(params.Block, 10))
+	}
+	if err := c.Get(`list/`+table, form, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// MaxBlockID returns the id of the last block
+func (c *Client) MaxBlockID() (int64, error) {
+	var ret struct {
+		MaxBlockID int64 `json:"max_block_id"`
+	}
+	if err := c.send(`GET`, `maxblockid`, nil, ``, &ret); err != nil {
+		return 0, err
+	}
+	return ret.MaxBlockID, nil
+}
+
+// BlockInfo is the header of the block
+type BlockInfo struct {
+	Hash          []byte `json:"hash"`
+	EcosystemID   int64  `json:"ecosystem_id"`
+	KeyID         int64  `json:"key_id"`
+	Time          int64  `json:"time"`
+	Tx            int32  `json:"tx_count"`
+	RollbacksHash []byte `json:"rollbacks_hash"`
+}
+
+// Block returns the header of the block
+func (c *Client) Block(id int64) (*BlockInfo, error) {
+	var ret BlockInfo
+	if err := c.send(`GET`, `block/`+strconv.FormatInt(id, 10), nil, ``, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GraphQLError is the error of GraphQL query
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path"`
+}
+
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// GraphQL executes the query over the tables of the ecosystem and unmarshals the data to result.
+// The first error of the query is returned if the query has partially failed
+func (c *Client) GraphQL(query string, variables map[string]interface{}, result interface{}) error {
+	form := url.Values{`query`: {query}}
+	if len(variables) > 0 {
+		vars, err := json.Marshal(variables)
+		if err != nil {
+			return err
+		}
+		form.Set(`variables`, string(vars))
+	}
+	var ret struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}
+	if err := c.Post(`graphql`, form, &ret); err != nil {
+		return err
+	}
+	if result != nil && len(ret.Data) > 0 {
+		if err := json.Unmarshal(ret.Data, result); err != nil {
+			return err
+		}
+	}
+	if len(ret.Errors) > 0 {
+		return ret.Errors[0]
+	}
+	return nil
+}
+
+// TxHistoryItem is the transaction from the history of accounts and contracts
+type TxHistoryItem struct {
+	Hash      string          `json:"hash"`
+	BlockID   int64           `json:"block_id"`
+	Time      int64           `json:"time"`
+	KeyID     string          `json:"key_id"`
+	Address   string          `json:"address"`
+	Ecosystem int64           `json:"ecosystem"`
+	Contract  string          `json:"contract"`
+	Params    json.RawMessage `json:"params"`
+	Result    string          `json:"result"`
+	Error     string          `json:"error"`
+	Fuel      string          `json:"fuel"`
+}
+
+// TxHistory is the page of the transaction history
+type TxHistory struct {
+	List   []TxHistoryItem `json:"list"`
+	Cursor string          `json:"cursor"` // it is empty on the last page
+}
+
+// TxHistoryParams are the parameters of the history requests, Contract is used by KeyHistory
+// and Key is used by ContractHistory
+type TxHistoryParams struct {
+	Limit     int64
+	Ecosystem int64
+	Contract  string
+	Key       string
+	Cursor    string
+}
+
+func (c *Client) txHistory(path string, params TxHistoryParams) (*TxHistory, error) {
+	form := url.Values{}
+	if params.Limit > 0 {
+		form.Set(`limit`, strconv.FormatInt(params.Limit, 10))
+	}
+	if params.Ecosystem > 0 {
+		form.Set(`ecosystem`, strconv.FormatInt(params.Ecosystem, 10))
+	}
+	if len(params.Contract) > 0 {
+		form.Set(`contract`, params.Contract)
+	}
+	if len(params.Key) > 0 {
+		form.Set(`key`, params.Key)
+	}
+	if len(params.Cursor) > 0 {
+		form.Set(`cursor`, params.Cursor)
+	}
+	var ret TxHistory
+	if err := c.Get(path, form, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// KeyHistory returns the transactions which have been sent by the key, from the newest
+func (c *Client) KeyHistory(key string, params TxHistoryParams) (*TxHistory, error) {
+	return c.txHistory(`txhistory/key/`+url.PathEscape(key), params)
+}
+
+// ContractHistory returns the calls of the contract, from the newest
+func (c *Client) ContractHistory(contract string, params TxHistoryParams) (*TxHistory, error) {
+	return c.txHistory(`txhistory/contract/`+url.PathEscape(contract), params)
+}
+
+// ExplorerChange is the row which has been changed by the transaction, Prev is empty for the inserted row
+type ExplorerChange struct {
+	Table string          `json:"table"`
+	ID    string          `json:"id"`
+	Prev  json.RawMessage `json:"prev"`
+}
+
+// ExplorerTx is the decoded transaction of the block
+type ExplorerTx struct {
+	Hash      string           `json:"hash"`
+	Type      int64            `json:"type"`
+	Contract  string           `json:"contract"`
+	Params    json.RawMessage  `json:"params"`
+	Time      int64            `json:"time"`
+	KeyID     string           `json:"key_id"`
+	Address   string           `json:"address"`
+	PublicKey string           `json:"public_key"`
+	Ecosystem int64            `json:"ecosystem"`
+	Fuel      string           `json:"fuel"`
+	Result    string           `json:"result"`
+	Error     string           `json:"error"`
+	Changes   []ExplorerChange `json:"changes"`
+}
+
+// ExplorerBlock is the block with the decoded transactions
+type ExplorerBlock struct {
+	ID            int64        `json:"id"`
+	Hash          string       `json:"hash"`
+	RollbacksHash string       `json:"rollbacks_hash"`
+	Time          int64        `json:"time"`
+	Version       int          `json:"version"`
+	EcosystemID   int64        `json:"ecosystem_id"`
+	KeyID         string       `json:"key_id"`
+	Address       string       `json:"address"`
+	NodePosition  int64        `json:"node_position"`
+	Sign          string       `json:"sign"`
+	Tx            []ExplorerTx `json:"tx"`
+}
+
+// ExplorerBlock returns the block with the decoded transactions
+func (c *Client) ExplorerBlock(id int64) (*ExplorerBlock, error) {
+	var ret ExplorerBlock
+	if err := c.Get(`explorer/block/`+strconv.FormatInt(id, 10), nil, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ExplorerTx returns the decoded transaction which has been written in the block
+func (c *Client) ExplorerTx(hash string) (*ExplorerTx, error) {
+	var ret ExplorerTx
+	if err := c.Get(`explorer/tx/`+url.PathEscape(hash), nil, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
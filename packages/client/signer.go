@@ -0,0 +1,54 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package client
+
+import (
+	"encoding/hex"
+
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+)
+
+// Signer signs the data of login and transactions, it can be implemented by the external key storage
+type Signer interface {
+	Sign(data string) ([]byte, error)
+	PublicKey() ([]byte, error)
+}
+
+// KeySigner signs the data with the private key in hex
+type KeySigner struct {
+	PrivateKey string
+}
+
+// Sign signs the data
+func (s *KeySigner) Sign(data string) ([]byte, error) {
+	return crypto.Sign(s.PrivateKey, data)
+}
+
+// PublicKey returns the public key of the private key
+func (s *KeySigner) PublicKey() ([]byte, error) {
+	key, err := hex.DecodeString(s.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.PrivateToPublic(key)
+}
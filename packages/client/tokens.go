@@ -0,0 +1,99 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+)
+
+// Access levels of the service tokens
+const (
+	AccessRead     = `read`
+	AccessContract = `contract`
+)
+
+// TokenScope restricts the routes of the service token. Empty lists allow all contracts or tables
+type TokenScope struct {
+	Access    string   `json:"access"`
+	Contracts []string `json:"contracts,omitempty"`
+	Tables    []string `json:"tables,omitempty"`
+}
+
+// APIToken is the scoped service token, Token is returned only on creation
+type APIToken struct {
+	ID      int64      `json:"id"`
+	Name    string     `json:"name"`
+	Scope   TokenScope `json:"scope"`
+	Created int64      `json:"created"`
+	Expire  int64      `json:"expire"`
+	Revoked int64      `json:"revoked"`
+	Token   string     `json:"token"`
+}
+
+// CreateAPIToken creates the service token of the logged in account with the signed request.
+// The expire is the lifetime of the token, the token never expires if it is 0
+func (c *Client) CreateAPIToken(name string, scope TokenScope, expire time.Duration) (*APIToken, error) {
+	if c.Signer == nil {
+		return nil, fmt.Errorf(`signer is not defined`)
+	}
+	if len(scope.Access) == 0 {
+		scope.Access = AccessRead
+	}
+	contracts, tables := strings.Join(scope.Contracts, `,`), strings.Join(scope.Tables, `,`)
+	seconds := int64(expire / time.Second)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	sign, err := c.Signer.Sign(fmt.Sprintf(consts.APITokenSignFormat, name, scope.Access, contracts, tables, seconds, now))
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{`name`: {name}, `access`: {scope.Access}, `contracts`: {contracts}, `tables`: {tables},
+		`expire`: {strconv.FormatInt(seconds, 10)}, `time`: {now}, `signature`: {hex.EncodeToString(sign)}}
+	var ret APIToken
+	if err = c.Post(`tokens`, form, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// APITokens returns the service tokens of the logged in account
+func (c *Client) APITokens() ([]APIToken, error) {
+	var ret struct {
+		List []APIToken `json:"list"`
+	}
+	if err := c.Get(`tokens`, nil, &ret); err != nil {
+		return nil, err
+	}
+	return ret.List, nil
+}
+
+// RevokeAPIToken revokes the service token
+func (c *Client) RevokeAPIToken(id int64) error {
+	return c.Post(fmt.Sprintf(`tokens/%d/revoke`, id), nil, nil)
+}
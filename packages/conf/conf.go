@@ -51,7 +51,7 @@ type DBConfig struct {
 	Password string
 }
 
-// StatsDConfig statd connection parameters
+// StatsDConfig statd connection parameters, statsd is off if Host is empty
 type StatsDConfig struct {
 	Name string
 	HostPort
@@ -63,16 +63,82 @@ type CentrifugoConfig struct {
 	URL    string
 }
 
+// AdminConfig contains the access parameters of the node admin API, it is off if Host is empty
+type AdminConfig struct {
+	HostPort
+	Key    string // the key is required if it is set, otherwise the admin API is available only from the localhost
+	Faults bool   // enables the injection of network partitions and clock skew for test networks
+}
+
+// HealthConfig contains the thresholds of readiness checks
+type HealthConfig struct {
+	MaxBlockLag int64 // the node is not ready if it lags behind the best remote host by more blocks
+}
+
+// HTTPClientConfig is the default policy of outbound http requests of contracts.
+// It can be overridden by the parameters of ecosystem
+type HTTPClientConfig struct {
+	AllowedHosts    string // comma separated list of hosts, *.domain masks and CIDRs, empty for public addresses
+	AllowedMethods  string // comma separated list of http methods
+	MaxResponseSize int64  // in bytes
+	Timeout         int64  // in seconds
+	Record          bool   // record answers for deterministic replay
+}
+
+// RateLimit is the token bucket limit of api requests
+type RateLimit struct {
+	Rate  int64 // requests per minute, 0 means no limit
+	Burst int64 // the number of requests which can be sent at once, Rate is used if it is 0
+}
+
+// RateLimitConfig contains the limits of api requests by route groups. The requests are counted
+// by the key of JWT token or by the client IP for anonymous requests
+type RateLimitConfig struct {
+	Default        RateLimit
+	Content        RateLimit // content/* routes
+	List           RateLimit // list, row, tables and history routes
+	Contract       RateLimit // prepare, contract and node routes
+	Exempt         string    // comma separated list of IPs and CIDRs without limits
+	RealIPHeader   string    // the header with the client IP set by the reverse proxy, e.g. X-Real-IP
+	TrustedProxies string    // comma separated list of IPs and CIDRs of proxies which can set RealIPHeader
+}
+
+// Prune modes
+const (
+	PruneModeArchive = "archive" // the node keeps all data
+	PruneModePrune   = "prune"   // the node removes rollback data of old blocks
+)
+
+// PruneConfig contains the parameters of removing the data of old blocks
+type PruneConfig struct {
+	Mode          string // archive or prune
+	Depth         int64  // the number of the last blocks which are not pruned, it is not less than rb_blocks_1
+	ArchiveDir    string // bodies of pruned blocks are moved to compressed files of the directory, they are kept in DB if it is empty
+	BlocksPerFile int64  // the number of blocks in the archive file
+	Interval      int64  // in minutes
+}
+
 // AutoupdateConfig is autoupdate params
 type AutoupdateConfig struct {
 	ServerAddress string
 	PublicKeyPath string
 }
 
+// LogRotateConfig contains the rotation and retention parameters of the log file
+type LogRotateConfig struct {
+	MaxSize    int64 // in megabytes, the file is rotated when it grows bigger, 0 means no limit
+	Interval   int64 // in hours, the file is rotated when it is older, 0 means no limit
+	MaxBackups int   // the number of rotated files to keep, 0 means all
+	MaxAge     int64 // in days, older rotated files are removed, 0 means no limit
+}
+
 // SavedConfig parameters saved in "config.toml"
 type SavedConfig struct {
 	LogLevel    string
 	LogFileName string
+	LogFormat   string // text or json
+	LogLevels   string // comma separated per-subsystem levels by log type or daemon name, e.g. "DB=DEBUG,Disseminator=WARN"
+	LogRotate   LogRotateConfig
 	InstallType string
 	NodeStateID string
 	TestMode    bool
@@ -99,6 +165,18 @@ type SavedConfig struct {
 	Centrifugo CentrifugoConfig
 
 	Autoupdate AutoupdateConfig
+
+	HTTPClient HTTPClientConfig
+
+	Health HealthConfig
+
+	Admin AdminConfig
+
+	Metrics HostPort // the address of Prometheus metrics, they are off if Host is empty
+
+	RateLimit RateLimitConfig
+
+	Prune PruneConfig
 }
 
 // Installed web UI installation mode
@@ -107,9 +185,31 @@ var Installed bool
 // Config global parameters
 var Config = SavedConfig{
 	InstallType:  "PRIVATE_NET",
+	LogFormat:    "text",
+	LogRotate:    LogRotateConfig{MaxSize: 100, MaxBackups: 10, MaxAge: 30},
 	NodeStateID:  "*",
 	StartDaemons: "",
 	StatsD:       StatsDConfig{Name: "apla", HostPort: HostPort{Host: "127.0.0.1", Port: 8125}},
+	HTTPClient: HTTPClientConfig{
+		AllowedMethods:  "GET,POST",
+		MaxResponseSize: 1 << 20,
+		Timeout:         10,
+	},
+	Health: HealthConfig{MaxBlockLag: 10},
+	Admin:  AdminConfig{HostPort: HostPort{Port: 7080}},
+	RateLimit: RateLimitConfig{
+		Default:  RateLimit{Rate: 600, Burst: 60},
+		Content:  RateLimit{Rate: 120, Burst: 20},
+		List:     RateLimit{Rate: 300, Burst: 30},
+		Contract: RateLimit{Rate: 120, Burst: 20},
+		Exempt:   "127.0.0.1/8,::1/128",
+	},
+	Prune: PruneConfig{
+		Mode:          PruneModeArchive,
+		Depth:         10000,
+		BlocksPerFile: 1000,
+		Interval:      10,
+	},
 }
 
 // GetConfigPath returns path from command line arg or default
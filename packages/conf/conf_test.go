@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	for _, item := range []struct {
+		path []string
+		env  string
+	}{
+		{[]string{`HTTP`, `Port`}, `GENESIS_HTTP_PORT`},
+		{[]string{`KeyID`}, `GENESIS_KEY_ID`},
+		{[]string{`HTTPClient`, `MaxResponseSize`}, `GENESIS_HTTP_CLIENT_MAX_RESPONSE_SIZE`},
+		{[]string{`FirstLoadBlockchainURL`}, `GENESIS_FIRST_LOAD_BLOCKCHAIN_URL`},
+		{[]string{`TCPServer`, `Host`}, `GENESIS_TCP_SERVER_HOST`},
+	} {
+		if env := EnvName(item.path...); env != item.env {
+			t.Errorf(`wrong env name %s != %s`, env, item.env)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	os.Setenv(`GENESIS_DB_HOST`, `db`)
+	os.Setenv(`GENESIS_DB_PORT`, `5433`)
+	os.Setenv(`GENESIS_TEST_MODE`, `true`)
+	os.Setenv(`GENESIS_HEALTH_MAX_BLOCK_LAG`, `wrong`)
+	defer func() {
+		for _, name := range []string{`GENESIS_DB_HOST`, `GENESIS_DB_PORT`, `GENESIS_TEST_MODE`, `GENESIS_HEALTH_MAX_BLOCK_LAG`} {
+			os.Unsetenv(name)
+		}
+	}()
+
+	var cfg SavedConfig
+	applied, err := ApplyEnv(&cfg)
+	if len(applied) != 3 || cfg.DB.Host != `db` || cfg.DB.Port != 5433 || !cfg.TestMode {
+		t.Errorf(`wrong applied env %v %+v`, applied, cfg)
+	}
+	if errs, ok := err.(ValidationError); !ok || len(errs) != 1 || errs[0].Field != `GENESIS_HEALTH_MAX_BLOCK_LAG` {
+		t.Errorf(`wrong env error %v`, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Config
+	cfg.TCPServer = HostPort{Host: `127.0.0.1`, Port: 7078}
+	cfg.HTTP = HostPort{Host: `127.0.0.1`, Port: 7079}
+	cfg.DB = DBConfig{Name: `genesis`, HostPort: HostPort{Host: `localhost`, Port: 5432}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.HTTP.Port = 70000
+	cfg.DB.Name = ``
+	cfg.MaxPageGenerationTime = -1
+	cfg.Centrifugo.URL = `localhost:8000`
+	cfg.Prune.Mode = `full`
+	cfg.Admin.HostPort = HostPort{Host: `127.0.0.1`, Port: 7080}
+	cfg.Metrics = cfg.Admin.HostPort
+	errs, ok := cfg.Validate().(ValidationError)
+	if !ok || len(errs) != 6 {
+		t.Errorf(`wrong validation errors %v`, errs)
+	}
+}
+
+func TestReload(t *testing.T) {
+	os.Setenv(`GENESIS_MAX_PAGE_GENERATION_TIME`, `1234`)
+	os.Setenv(`GENESIS_ADMIN_KEY`, `secret`)
+	defer func() {
+		os.Unsetenv(`GENESIS_MAX_PAGE_GENERATION_TIME`)
+		os.Unsetenv(`GENESIS_ADMIN_KEY`)
+	}()
+	*ConfigPath = filepath.Join(os.TempDir(), `missing-config.toml`)
+	defer func() { *ConfigPath = `` }()
+
+	changed, err := Reload(func(*SavedConfig) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != `MaxPageGenerationTime` || Current().MaxPageGenerationTime != 1234 {
+		t.Errorf(`wrong reloaded parameters %v %+v`, changed, Current())
+	}
+	if Config.MaxPageGenerationTime == 1234 || Config.Admin.Key == `secret` {
+		t.Error(`Config must not be changed by reload`)
+	}
+}
@@ -0,0 +1,139 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package conf
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EnvPrefix is the prefix of environment variables which override the parameters of the config file
+const EnvPrefix = "GENESIS_"
+
+// EnvName returns the name of environment variable for the path of config field,
+// e.g. HTTP.Port is GENESIS_HTTP_PORT, LogRotate.MaxSize is GENESIS_LOG_ROTATE_MAX_SIZE
+func EnvName(path ...string) string {
+	parts := make([]string, len(path))
+	for i, name := range path {
+		parts[i] = snakeCase(name)
+	}
+	return EnvPrefix + strings.Join(parts, "_")
+}
+
+func snakeCase(name string) string {
+	runes := []rune(name)
+	out := make([]rune, 0, len(runes)+4)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if !unicode.IsUpper(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, unicode.ToUpper(r))
+	}
+	return string(out)
+}
+
+// envField is the config field which can be set by environment variable
+type envField struct {
+	name  string
+	value reflect.Value
+}
+
+func envFields(v reflect.Value, path []string, fields []envField) []envField {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = append(append([]string{}, path...), field.Name)
+		}
+		if field.Type.Kind() == reflect.Struct {
+			fields = envFields(v.Field(i), fieldPath, fields)
+			continue
+		}
+		fields = append(fields, envField{name: EnvName(fieldPath...), value: v.Field(i)})
+	}
+	return fields
+}
+
+// EnvVars returns the names of all environment variables of the config
+func EnvVars() []string {
+	fields := envFields(reflect.ValueOf(&SavedConfig{}).Elem(), nil, nil)
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.name
+	}
+	return names
+}
+
+// ApplyEnv overrides the parameters of cfg by the environment variables with EnvPrefix.
+// It returns the names of applied variables
+func ApplyEnv(cfg *SavedConfig) ([]string, error) {
+	var (
+		applied []string
+		errs    ValidationError
+	)
+	for _, field := range envFields(reflect.ValueOf(cfg).Elem(), nil, nil) {
+		env, ok := os.LookupEnv(field.name)
+		if !ok {
+			continue
+		}
+		if err := setValue(field.value, env); err != nil {
+			errs = append(errs, FieldError{Field: field.name, Err: err.Error()})
+			continue
+		}
+		applied = append(applied, field.name)
+	}
+	if len(errs) > 0 {
+		return applied, errs
+	}
+	return applied, nil
+}
+
+func setValue(v reflect.Value, env string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(env)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", env)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", env)
+		}
+		v.SetInt(i)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Kind())
+	}
+	return nil
+}
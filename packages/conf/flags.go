@@ -84,8 +84,13 @@ var configFlagMap = map[string]interface{}{
 
 	"logLevel":   &flagStr{confVar: &Config.LogLevel, defVal: "ERROR", flagBase: flagBase{help: "log level - ERROR,WARN,INFO,DEBUG"}},
 	"logFile":    &flagStr{confVar: &Config.LogFileName, flagBase: flagBase{help: "log file name"}},
+	"logFormat":  &flagStr{confVar: &Config.LogFormat, defVal: "text", flagBase: flagBase{help: "log format - text,json"}},
+	"logLevels":  &flagStr{confVar: &Config.LogLevels, flagBase: flagBase{help: "per-subsystem log levels, e.g. DB=DEBUG,Disseminator=WARN"}},
 	"privateDir": &flagStr{confVar: &Config.PrivateDir, flagBase: flagBase{help: "directory for public/private keys"}},
 
+	"pruneMode":  &flagStr{confVar: &Config.Prune.Mode, defVal: PruneModeArchive, flagBase: flagBase{help: "archive keeps all data, prune removes rollback data of old blocks"}},
+	"archiveDir": &flagStr{confVar: &Config.Prune.ArchiveDir, flagBase: flagBase{help: "directory of compressed bodies of pruned blocks"}},
+
 	"updateServer":        &flagStr{confVar: &Config.Autoupdate.ServerAddress, defVal: defaultUpdateServer, flagBase: flagBase{help: "server address for autoupdates"}},
 	"updatePublicKeyPath": &flagStr{confVar: &Config.Autoupdate.PublicKeyPath, defVal: defaultUpdatePublicKeyPath, flagBase: flagBase{help: "public key path for autoupdates"}},
 }
@@ -122,6 +127,9 @@ var (
 	// FirstBlockHost is the host of the first block
 	FirstBlockHost = flag.String("firstBlockHost", defaultFirstBlockHost, "FirstBlockHost")
 
+	// FirstBlockNodes is the list of full nodes of the first block
+	FirstBlockNodes = flag.String("firstBlockNodes", "", "json list of full nodes of the first block [[host,key_id,node_public_key],...]")
+
 	// WalletAddress is a wallet address for forging
 	WalletAddress = flag.String("walletAddress", "", "walletAddress for forging ")
 
@@ -0,0 +1,141 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package conf
+
+import (
+	"flag"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	toml "github.com/BurntSushi/toml"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reloadable is the snapshot of the parameters which are applied without restart of the node.
+// The snapshot is immutable, the reloaded parameters are published as the new snapshot
+type Reloadable struct {
+	LogLevel              string
+	LogLevels             string
+	MaxPageGenerationTime int64
+	StatsD                StatsDConfig
+	Centrifugo            CentrifugoConfig
+}
+
+var (
+	reloadMutex sync.Mutex
+	reloaded    atomic.Value // *Reloadable
+)
+
+// Current returns the current values of the reloadable parameters. They are taken
+// from Config until the config is reloaded
+func Current() *Reloadable {
+	if r, ok := reloaded.Load().(*Reloadable); ok {
+		return r
+	}
+	return newReloadable(&Config)
+}
+
+// newReloadable copies the reloadable parameters of cfg
+func newReloadable(cfg *SavedConfig) *Reloadable {
+	r := &Reloadable{}
+	dst, src := reflect.ValueOf(r).Elem(), reflect.ValueOf(cfg).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		dst.Field(i).Set(src.FieldByName(dst.Type().Field(i).Name))
+	}
+	return r
+}
+
+// Reload reads the config file and environment again and publishes the reloadable parameters
+// which are returned by Current. Other parameters of Config are not changed. The command line
+// flags keep priority as at the start. The new config is checked by validate before it is applied.
+// Reload returns the names of changed parameters
+func Reload(validate func(*SavedConfig) error) ([]string, error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	cfg := Config
+	if !NoConfig() {
+		if _, err := toml.DecodeFile(GetConfigPath(), &cfg); err != nil {
+			log.WithFields(log.Fields{"type": consts.ConfigError, "error": err}).Error("reloading config file")
+			return nil, err
+		}
+	}
+	if _, err := ApplyEnv(&cfg); err != nil {
+		log.WithFields(log.Fields{"type": consts.ConfigError, "error": err}).Error("reloading config from environment")
+		return nil, err
+	}
+	keepFlags(reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(&Config).Elem(), flagPointers())
+	if err := validate(&cfg); err != nil {
+		log.WithFields(log.Fields{"type": consts.ConfigError, "error": err}).Error("reloaded config is invalid")
+		return nil, err
+	}
+
+	next := newReloadable(&cfg)
+	var changed []string
+	curValue, nextValue := reflect.ValueOf(Current()).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < nextValue.NumField(); i++ {
+		if !reflect.DeepEqual(curValue.Field(i).Interface(), nextValue.Field(i).Interface()) {
+			changed = append(changed, nextValue.Type().Field(i).Name)
+		}
+	}
+	oldValue, newValue := reflect.ValueOf(Config), reflect.ValueOf(cfg)
+	for i := 0; i < newValue.NumField(); i++ {
+		name := newValue.Type().Field(i).Name
+		if _, ok := nextValue.Type().FieldByName(name); ok ||
+			reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		log.WithFields(log.Fields{"type": consts.ConfigError, "param": name}).Warning("parameter is changed, the restart is required")
+	}
+	reloaded.Store(next)
+	return changed, nil
+}
+
+// flagPointers returns the addresses of Config parameters which have been set by command line flags
+func flagPointers() map[uintptr]bool {
+	ptrs := make(map[uintptr]bool)
+	flag.Visit(func(f *flag.Flag) {
+		switch flagParams := configFlagMap[f.Name].(type) {
+		case *flagStr:
+			ptrs[reflect.ValueOf(flagParams.confVar).Pointer()] = true
+		case *flagInt:
+			ptrs[reflect.ValueOf(flagParams.confVar).Pointer()] = true
+		}
+	})
+	return ptrs
+}
+
+// keepFlags copies the parameters which have been set by command line flags from src to dst
+func keepFlags(dst, src reflect.Value, ptrs map[uintptr]bool) {
+	for i := 0; i < src.NumField(); i++ {
+		if src.Field(i).Kind() == reflect.Struct {
+			keepFlags(dst.Field(i), src.Field(i), ptrs)
+			continue
+		}
+		if ptrs[src.Field(i).Addr().Pointer()] {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
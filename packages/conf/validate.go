@@ -0,0 +1,165 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package conf
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// FieldError is the error of the config parameter
+type FieldError struct {
+	Field string
+	Err   string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Err
+}
+
+// ValidationError contains the errors of all invalid config parameters
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	list := make([]string, len(e))
+	for i, err := range e {
+		list[i] = err.Error()
+	}
+	return strings.Join(list, "; ")
+}
+
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) fail(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Err: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) port(field string, port int) {
+	if port < 1 || port > 65535 {
+		v.fail(field, "port %d must be in range 1..65535", port)
+	}
+}
+
+func (v *validator) notNegative(field string, value int64) {
+	if value < 0 {
+		v.fail(field, "must not be negative")
+	}
+}
+
+func (v *validator) url(field, value string) {
+	if len(value) == 0 {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		v.fail(field, "%q is not a valid http(s) URL", value)
+	}
+}
+
+func (v *validator) ipList(field, value string) {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 && net.ParseIP(item) == nil {
+			if _, _, err := net.ParseCIDR(item); err != nil {
+				v.fail(field, "%q is not IP or CIDR", item)
+			}
+		}
+	}
+}
+
+// Validate checks the parameters of the config and returns ValidationError with all invalid ones
+func (c *SavedConfig) Validate() error {
+	v := &validator{}
+
+	v.port("TCPServer.Port", c.TCPServer.Port)
+	v.port("HTTP.Port", c.HTTP.Port)
+	if c.TCPServer.Str() == c.HTTP.Str() {
+		v.fail("HTTP", "tcp and http servers can't listen at the same address %s", c.HTTP.Str())
+	}
+
+	if len(c.DB.Name) == 0 {
+		v.fail("DB.Name", "must not be empty")
+	}
+	if len(c.DB.Host) == 0 {
+		v.fail("DB.Host", "must not be empty")
+	}
+	v.port("DB.Port", c.DB.Port)
+
+	if len(c.StatsD.Host) > 0 {
+		v.port("StatsD.Port", c.StatsD.Port)
+	}
+	if len(c.Admin.Host) > 0 {
+		v.port("Admin.Port", c.Admin.Port)
+		if c.Admin.Str() == c.HTTP.Str() || c.Admin.Str() == c.TCPServer.Str() {
+			v.fail("Admin", "admin API can't listen at the address %s of other server", c.Admin.Str())
+		}
+	}
+	if len(c.Metrics.Host) > 0 {
+		v.port("Metrics.Port", c.Metrics.Port)
+		if c.Metrics.Str() == c.HTTP.Str() || c.Metrics.Str() == c.TCPServer.Str() ||
+			(len(c.Admin.Host) > 0 && c.Metrics.Str() == c.Admin.Str()) {
+			v.fail("Metrics", "metrics can't listen at the address %s of other server", c.Metrics.Str())
+		}
+	}
+
+	v.notNegative("KeyID", c.KeyID)
+	v.notNegative("EcosystemID", c.EcosystemID)
+	v.notNegative("MaxPageGenerationTime", c.MaxPageGenerationTime)
+	v.notNegative("LogRotate.MaxSize", c.LogRotate.MaxSize)
+	v.notNegative("LogRotate.Interval", c.LogRotate.Interval)
+	v.notNegative("LogRotate.MaxBackups", int64(c.LogRotate.MaxBackups))
+	v.notNegative("LogRotate.MaxAge", c.LogRotate.MaxAge)
+	v.notNegative("HTTPClient.MaxResponseSize", c.HTTPClient.MaxResponseSize)
+	v.notNegative("HTTPClient.Timeout", c.HTTPClient.Timeout)
+	v.notNegative("Health.MaxBlockLag", c.Health.MaxBlockLag)
+
+	limits := []RateLimit{c.RateLimit.Default, c.RateLimit.Content, c.RateLimit.List, c.RateLimit.Contract}
+	for i, name := range []string{"Default", "Content", "List", "Contract"} {
+		v.notNegative("RateLimit."+name+".Rate", limits[i].Rate)
+		v.notNegative("RateLimit."+name+".Burst", limits[i].Burst)
+	}
+	v.ipList("RateLimit.Exempt", c.RateLimit.Exempt)
+	v.ipList("RateLimit.TrustedProxies", c.RateLimit.TrustedProxies)
+	if len(c.RateLimit.RealIPHeader) > 0 && len(strings.TrimSpace(c.RateLimit.TrustedProxies)) == 0 {
+		v.fail("RateLimit.TrustedProxies", "must not be empty if RealIPHeader is set")
+	}
+
+	if c.Prune.Mode != PruneModeArchive && c.Prune.Mode != PruneModePrune {
+		v.fail("Prune.Mode", "must be %s or %s", PruneModeArchive, PruneModePrune)
+	}
+	v.notNegative("Prune.Depth", c.Prune.Depth)
+	v.notNegative("Prune.BlocksPerFile", c.Prune.BlocksPerFile)
+	v.notNegative("Prune.Interval", c.Prune.Interval)
+
+	v.url("Centrifugo.URL", c.Centrifugo.URL)
+	v.url("Autoupdate.ServerAddress", c.Autoupdate.ServerAddress)
+	v.url("FirstLoadBlockchainURL", c.FirstLoadBlockchainURL)
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
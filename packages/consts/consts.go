@@ -23,7 +23,7 @@
 package consts
 
 // VERSION is current version
-const VERSION = "0.1.6b11"
+const VERSION = "0.1.6b18"
 
 // BLOCK_VERSION is block version
 const BLOCK_VERSION = 1
@@ -90,11 +90,19 @@ const HashSize = 32
 // TxTypes is the list of the embedded transactions
 var TxTypes = map[int]string{
 	1: "FirstBlock",
+	2: "FirstBlockNodes",
 }
 
 // ApiPath is the beginning of the api url
 var ApiPath = `/api/v2/`
 
+// APITokenPrefix is the prefix of the scoped service tokens of API
+const APITokenPrefix = `gst_`
+
+// APITokenSignFormat is the format of the signed message of the service token request.
+// The parameters are name, access, contracts, tables, expire and time
+const APITokenSignFormat = `APITOKEN:%s:%s:%s:%s:%d:%s`
+
 // DefaultConfigFile name of config file (toml format)
 const DefaultConfigFile = "config.toml"
 
@@ -72,4 +72,5 @@ const (
 	MigrationError           = "MigrationError"
 	AutoupdateError          = "AutoupdateError"
 	SchedulerError           = "SchedulerError"
+	HTTPRequestAudit         = "HTTPRequestAudit"
 )
@@ -51,27 +51,35 @@ type FirstBlock struct {
 	Host          string
 }
 
+// FirstBlockNodes is the transaction of the first block which sets the list of full nodes.
+// Nodes is json array of [host, key_id, node_public_key]
+type FirstBlockNodes struct {
+	TxHeader
+	Nodes string
+}
+
 // Don't forget to insert the structure in init() - list
 
 var blockStructs = make(map[string]reflect.Type)
 
 func init() {
-	list := []interface{}{FirstBlock{}} // New structures must be inserted here
+	list := []interface{}{FirstBlock{}, FirstBlockNodes{}} // New structures must be inserted here
 
 	for _, item := range list {
 		blockStructs[reflect.TypeOf(item).Name()] = reflect.TypeOf(item)
 	}
 }
 
-// MakeStruct is only used for FirstBlock now
+// MakeStruct is only used for the transactions of the first block now
 func MakeStruct(name string) interface{} {
 	v := reflect.New(blockStructs[name]) //.Elem()
 	return v.Interface()
 }
 
-// IsStruct is only used for FirstBlock now
+// IsStruct is only used for the transactions of the first block now
 func IsStruct(tx int) bool {
-	return tx == 1
+	_, ok := TxTypes[tx]
+	return ok
 }
 
 // Header returns TxHeader
@@ -31,6 +31,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/faults"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/parser"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
@@ -73,7 +74,7 @@ func BlockGenerator(ctx context.Context, d *daemon) error {
 		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting sleep time")
 		return err
 	}
-	toSleep := int64(sleepTime) - (time.Now().Unix() - int64(prevBlock.Time))
+	toSleep := int64(sleepTime) - (faults.Now().Unix() - int64(prevBlock.Time))
 	if toSleep > 0 {
 		d.logger.WithFields(log.Fields{"type": consts.JustWaiting, "seconds": toSleep}).Debug("sleeping n seconds")
 		d.sleepTime = time.Duration(toSleep) * time.Second
@@ -111,7 +112,7 @@ func BlockGenerator(ctx context.Context, d *daemon) error {
 		prevBlock,
 		trs,
 		NodePrivateKey,
-		time.Now().Unix(),
+		faults.Now().Unix(),
 		myNodePosition,
 		conf.Config.EcosystemID,
 		conf.Config.KeyID,
@@ -134,7 +135,7 @@ func generateNextBlock(
 
 	header := &utils.BlockData{
 		BlockID:      prevBlock.BlockID + 1,
-		Time:         time.Now().Unix(),
+		Time:         blockTime,
 		EcosystemID:  ecosystemID,
 		KeyID:        keyID,
 		NodePosition: myNodePosition,
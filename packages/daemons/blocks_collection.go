@@ -31,6 +31,7 @@ import (
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
@@ -86,6 +87,8 @@ func blocksCollection(ctx context.Context, d *daemon) error {
 	if err != nil {
 		return err
 	}
+	atomic.StoreInt64(&peerBlockID, maxBlockID)
+	atomic.StoreInt32(&peerChecked, 1)
 
 	// NOTE: should be generalized in separate method
 	infoBlock := &model.InfoBlock{}
@@ -57,6 +57,7 @@ var daemonsList = map[string]func(context.Context, *daemon) error{
 	"Confirmations":     Confirmations,
 	"Notificator":       Notificate,
 	"Scheduler":         Scheduler,
+	"Pruner":            Pruner,
 }
 
 var serverList = []string{
@@ -68,6 +69,7 @@ var serverList = []string{
 	"Confirmations",
 	"Notificator",
 	"Scheduler",
+	"Pruner",
 }
 
 var rollbackList = []string{
@@ -84,21 +86,27 @@ func daemonLoop(ctx context.Context, goRoutineName string, handler func(context.
 		}
 	}()
 
+	ctl := newDaemonControl(goRoutineName)
+	defer ctl.stop()
+
 	err := WaitDB(ctx)
 	if err != nil {
 		return
 	}
 
-	d := &daemon{
-		goRoutineName: goRoutineName,
-		sleepTime:     1 * time.Second,
-		logger:        logger,
+	var d *daemon
+	iterate := func() {
+		if d == nil || ctl.takeRestart() {
+			d = &daemon{
+				goRoutineName: goRoutineName,
+				sleepTime:     1 * time.Second,
+				logger:        logger,
+			}
+		}
+		d.run(ctx, ctl, handler)
 	}
 
-	startTime := time.Now()
-	counterName := statsd.DaemonCounterName(goRoutineName)
-	handler(ctx, d)
-	statsd.Client.TimingDuration(counterName+statsd.Time, time.Now().Sub(startTime), 1.0)
+	iterate()
 
 	for {
 		select {
@@ -107,16 +115,41 @@ func daemonLoop(ctx context.Context, goRoutineName string, handler func(context.
 			retCh <- goRoutineName
 			return
 
+		case <-ctl.trigger:
+			MonitorDaemonCh <- []string{d.goRoutineName, converter.Int64ToStr(time.Now().Unix())}
+			iterate()
+
 		case <-time.After(d.sleepTime):
+			if ctl.paused() {
+				continue
+			}
 			MonitorDaemonCh <- []string{d.goRoutineName, converter.Int64ToStr(time.Now().Unix())}
-			startTime := time.Now()
-			counterName := statsd.DaemonCounterName(goRoutineName)
-			handler(ctx, d)
-			statsd.Client.TimingDuration(counterName+statsd.Time, time.Now().Sub(startTime), 1.0)
+			iterate()
 		}
 	}
 }
 
+// run executes one iteration of the daemon and measures it
+func (d *daemon) run(ctx context.Context, ctl *daemonControl, handler func(context.Context, *daemon) error) {
+	runCtx := ctl.begin(ctx)
+	startTime := time.Now()
+	counterName := statsd.DaemonCounterName(d.goRoutineName)
+	err := handler(runCtx, d)
+	duration := time.Now().Sub(startTime)
+	statsd.Client().TimingDuration(counterName+statsd.Time, duration, 1.0)
+	daemonDuration.With(d.goRoutineName).Observe(duration.Seconds())
+	if err != nil {
+		daemonErrors.With(d.goRoutineName).Inc()
+	}
+	ctl.end(startTime, duration, d.sleepTime, err)
+}
+
+// IsDaemon returns true if there is the daemon with the specified name
+func IsDaemon(name string) bool {
+	_, ok := daemonsList[name]
+	return ok
+}
+
 // StartDaemons starts daemons
 func StartDaemons() {
 	if conf.Config.StartDaemons == "null" {
@@ -140,12 +173,7 @@ func StartDaemons() {
 	utils.CancelFunc = cancel
 	utils.ReturnCh = make(chan string)
 
-	daemonsToStart := serverList
-	if len(conf.Config.StartDaemons) > 0 {
-		daemonsToStart = strings.Split(conf.Config.StartDaemons, ",")
-	} else if *conf.TestRollBack {
-		daemonsToStart = rollbackList
-	}
+	daemonsToStart := configuredDaemons()
 
 	log.WithFields(log.Fields{"daemons_to_start": daemonsToStart}).Info("starting daemons")
 
@@ -162,6 +190,29 @@ func StartDaemons() {
 	}
 }
 
+// configuredDaemons returns the names of daemons which are started according to the config
+func configuredDaemons() []string {
+	switch {
+	case conf.Config.StartDaemons == "null":
+		return nil
+	case len(conf.Config.StartDaemons) > 0:
+		return strings.Split(conf.Config.StartDaemons, ",")
+	case *conf.TestRollBack:
+		return rollbackList
+	}
+	return serverList
+}
+
+// isConfiguredDaemon returns true if the daemon is started according to the config
+func isConfiguredDaemon(name string) bool {
+	for _, item := range configuredDaemons() {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
 func getHostPort(h string) string {
 	if strings.Contains(h, ":") {
 		return h
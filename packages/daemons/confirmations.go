@@ -25,13 +25,13 @@ package daemons
 import (
 	"context"
 	"net"
-	"strconv"
 	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/faults"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
 
@@ -112,8 +112,7 @@ func Confirmations(ctx context.Context, d *daemon) error {
 
 		ch := make(chan string)
 		for i := 0; i < len(hosts); i++ {
-			// NOTE: host should not use default port number
-			host := hosts[i] + ":" + strconv.Itoa(consts.DEFAULT_TCP_PORT)
+			host := getHostPort(hosts[i])
 			d.logger.WithFields(log.Fields{"host": host, "block_id": blockID}).Debug("checking block id confirmed at node")
 			go func() {
 				IsReachable(host, blockID, ch, d.logger)
@@ -162,6 +161,10 @@ func Confirmations(ctx context.Context, d *daemon) error {
 }
 
 func checkConf(host string, blockID int64, logger *log.Entry) string {
+	if err := faults.CheckDial(host); err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host, "block_id": blockID}).Debug("dialing to host")
+		return "0"
+	}
 	conn, err := net.DialTimeout("tcp", host, 5*time.Second)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host, "block_id": blockID}).Debug("dialing to host")
@@ -0,0 +1,240 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daemons
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// States of daemons
+const (
+	DaemonRunning = `running`
+	DaemonPaused  = `paused`
+	DaemonStopped = `stopped`
+)
+
+var (
+	// ErrUnknownDaemon is returned when the daemon has not been started
+	ErrUnknownDaemon = errors.New(`unknown daemon`)
+	// ErrDaemonStopped is returned when the daemon loop has finished
+	ErrDaemonStopped = errors.New(`daemon has been stopped`)
+)
+
+// DaemonInfo is the state of the daemon
+type DaemonInfo struct {
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	LastRun   int64  `json:"last_run,omitempty"`
+	Duration  int64  `json:"duration"` // of the last run in milliseconds
+	LastError string `json:"last_error,omitempty"`
+	ErrorTime int64  `json:"error_time,omitempty"`
+	SleepTime int64  `json:"sleep_time"` // in milliseconds
+	Runs      int64  `json:"runs"`
+}
+
+// daemonControl allows to manage the running daemon loop
+type daemonControl struct {
+	mutex   sync.Mutex
+	info    DaemonInfo
+	trigger chan struct{}
+	restart bool
+	cancel  context.CancelFunc // cancels the current iteration
+}
+
+var (
+	controlMutex sync.RWMutex
+	controls     = make(map[string]*daemonControl)
+)
+
+func newDaemonControl(name string) *daemonControl {
+	ctl := &daemonControl{
+		info:    DaemonInfo{Name: name, State: DaemonRunning},
+		trigger: make(chan struct{}, 1),
+	}
+	controlMutex.Lock()
+	controls[name] = ctl
+	controlMutex.Unlock()
+	return ctl
+}
+
+func getDaemonControl(name string) (*daemonControl, error) {
+	controlMutex.RLock()
+	defer controlMutex.RUnlock()
+	if ctl, ok := controls[name]; ok {
+		return ctl, nil
+	}
+	return nil, ErrUnknownDaemon
+}
+
+// paused returns true if the scheduled runs must be skipped
+func (ctl *daemonControl) paused() bool {
+	ctl.mutex.Lock()
+	defer ctl.mutex.Unlock()
+	return ctl.info.State == DaemonPaused
+}
+
+// takeRestart returns true if the daemon must be recreated before the next iteration
+func (ctl *daemonControl) takeRestart() bool {
+	ctl.mutex.Lock()
+	defer ctl.mutex.Unlock()
+	restart := ctl.restart
+	ctl.restart = false
+	return restart
+}
+
+// begin returns the context of the next iteration which is cancelled on restart
+func (ctl *daemonControl) begin(ctx context.Context) context.Context {
+	ctl.mutex.Lock()
+	defer ctl.mutex.Unlock()
+	runCtx, cancel := context.WithCancel(ctx)
+	ctl.cancel = cancel
+	return runCtx
+}
+
+// end saves the result of the iteration
+func (ctl *daemonControl) end(start time.Time, duration time.Duration, sleepTime time.Duration, err error) {
+	ctl.mutex.Lock()
+	defer ctl.mutex.Unlock()
+	if ctl.cancel != nil {
+		ctl.cancel()
+		ctl.cancel = nil
+	}
+	ctl.info.LastRun = start.Unix()
+	ctl.info.Duration = int64(duration / time.Millisecond)
+	ctl.info.SleepTime = int64(sleepTime / time.Millisecond)
+	ctl.info.Runs++
+	if err != nil {
+		ctl.info.LastError = err.Error()
+		ctl.info.ErrorTime = start.Unix()
+	}
+}
+
+func (ctl *daemonControl) stop() {
+	ctl.mutex.Lock()
+	ctl.info.State = DaemonStopped
+	ctl.mutex.Unlock()
+}
+
+func (ctl *daemonControl) setState(state string) error {
+	ctl.mutex.Lock()
+	defer ctl.mutex.Unlock()
+	if ctl.info.State == DaemonStopped {
+		return ErrDaemonStopped
+	}
+	ctl.info.State = state
+	return nil
+}
+
+// runNow asks the daemon loop to run the iteration without waiting
+func (ctl *daemonControl) runNow() error {
+	ctl.mutex.Lock()
+	stopped := ctl.info.State == DaemonStopped
+	ctl.mutex.Unlock()
+	if stopped {
+		return ErrDaemonStopped
+	}
+	select {
+	case ctl.trigger <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// GetDaemonsInfo returns the states of the started daemons
+func GetDaemonsInfo() []DaemonInfo {
+	controlMutex.RLock()
+	list := make([]DaemonInfo, 0, len(controls))
+	for _, ctl := range controls {
+		ctl.mutex.Lock()
+		list = append(list, ctl.info)
+		ctl.mutex.Unlock()
+	}
+	controlMutex.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// GetDaemonInfo returns the state of the daemon
+func GetDaemonInfo(name string) (*DaemonInfo, error) {
+	ctl, err := getDaemonControl(name)
+	if err != nil {
+		return nil, err
+	}
+	ctl.mutex.Lock()
+	info := ctl.info
+	ctl.mutex.Unlock()
+	return &info, nil
+}
+
+// PauseDaemon skips the scheduled runs of the daemon until it is resumed
+func PauseDaemon(name string) error {
+	ctl, err := getDaemonControl(name)
+	if err != nil {
+		return err
+	}
+	return ctl.setState(DaemonPaused)
+}
+
+// ResumeDaemon resumes the scheduled runs of the paused daemon
+func ResumeDaemon(name string) error {
+	ctl, err := getDaemonControl(name)
+	if err != nil {
+		return err
+	}
+	return ctl.setState(DaemonRunning)
+}
+
+// RestartDaemon cancels the current iteration of the daemon, resets its state and runs it again
+func RestartDaemon(name string) error {
+	ctl, err := getDaemonControl(name)
+	if err != nil {
+		return err
+	}
+	ctl.mutex.Lock()
+	if ctl.info.State == DaemonStopped {
+		ctl.mutex.Unlock()
+		return ErrDaemonStopped
+	}
+	ctl.info.State = DaemonRunning
+	ctl.info.LastError = ``
+	ctl.info.ErrorTime = 0
+	ctl.restart = true
+	if ctl.cancel != nil {
+		ctl.cancel()
+	}
+	ctl.mutex.Unlock()
+	return ctl.runNow()
+}
+
+// RunDaemon triggers the one-off run of the daemon, it works for paused daemons too
+func RunDaemon(name string) error {
+	ctl, err := getDaemonControl(name)
+	if err != nil {
+		return err
+	}
+	return ctl.runNow()
+}
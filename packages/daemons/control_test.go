@@ -0,0 +1,105 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daemons
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDaemonControl(t *testing.T) {
+	ctl := newDaemonControl(`TestDaemon`)
+	defer func() {
+		controlMutex.Lock()
+		delete(controls, `TestDaemon`)
+		controlMutex.Unlock()
+	}()
+
+	for _, item := range []struct {
+		action  func(string) error
+		state   string
+		paused  bool
+		trigger bool
+	}{
+		{PauseDaemon, DaemonPaused, true, false},
+		{RunDaemon, DaemonPaused, true, true},
+		{ResumeDaemon, DaemonRunning, false, false},
+		{RestartDaemon, DaemonRunning, false, true},
+	} {
+		if err := item.action(`TestDaemon`); err != nil {
+			t.Fatal(err)
+		}
+		info, err := GetDaemonInfo(`TestDaemon`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.State != item.state || ctl.paused() != item.paused {
+			t.Errorf(`wrong state %s, want %s`, info.State, item.state)
+		}
+		select {
+		case <-ctl.trigger:
+			if !item.trigger {
+				t.Error(`unexpected trigger`)
+			}
+		default:
+			if item.trigger {
+				t.Error(`daemon hasn't been triggered`)
+			}
+		}
+	}
+	if !ctl.takeRestart() || ctl.takeRestart() {
+		t.Error(`restart must be taken once`)
+	}
+
+	if _, err := GetDaemonInfo(`UnknownDaemon`); err != ErrUnknownDaemon {
+		t.Errorf(`wrong error %v`, err)
+	}
+	ctl.stop()
+	for _, action := range []func(string) error{PauseDaemon, ResumeDaemon, RestartDaemon, RunDaemon} {
+		if err := action(`TestDaemon`); err != ErrDaemonStopped {
+			t.Errorf(`wrong error of stopped daemon %v`, err)
+		}
+	}
+}
+
+func TestDaemonControlIteration(t *testing.T) {
+	ctl := &daemonControl{info: DaemonInfo{Name: `TestIteration`, State: DaemonRunning}}
+	ctx := ctl.begin(context.Background())
+	ctl.mutex.Lock()
+	ctl.restart = true
+	ctl.cancel()
+	ctl.mutex.Unlock()
+	select {
+	case <-ctx.Done():
+	default:
+		t.Error(`iteration must be cancelled on restart`)
+	}
+	start := time.Now()
+	ctl.end(start, 2*time.Second, time.Second, errors.New(`failed`))
+	if ctl.info.Runs != 1 || ctl.info.Duration != 2000 || ctl.info.SleepTime != 1000 ||
+		ctl.info.LastError != `failed` || ctl.info.ErrorTime != start.Unix() {
+		t.Errorf(`wrong info %+v`, ctl.info)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/GenesisKernel/go-genesis/packages/archive"
 	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
@@ -83,57 +84,22 @@ func writeNextBlocks(fileName string, minToSave int64, logger *log.Entry) error
 	return nil
 }
 
-/*
-Block record format:
- block len - 5 bytes
- block id - 5 bytes
- block data len - variable
- block data - block data len bytes
- full len - 5 bytes (for read from end of file)
-*/
+// the format of the block record is described in the archive package
 
 const (
 	// WordSize is size of word in file
-	WordSize = 5
+	WordSize = archive.WordSize
 )
 
-type blockData struct {
-	ID   int64
-	Data []byte
-}
+type blockData archive.Block
 
 func readBlock(r io.Reader, logger *log.Entry) (*blockData, error) {
-	var err error
-	buf := make([]byte, WordSize)
-
-	if _, err = io.ReadFull(r, buf); err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading block from file")
-		return nil, err
-	}
-
-	size := converter.BinToDec(buf)
-	if size > syspar.GetMaxBlockSize() {
-		logger.WithFields(log.Fields{"size": size, "max_size": syspar.GetMaxBlockSize(), "type": consts.ParameterExceeded}).Error("reading block from file")
-		return nil, utils.ErrInfo("size > conts.MAX_BLOCK_SIZE")
-	}
-
-	if size == 0 {
-		return nil, nil
-	}
-
-	dataBinary := make([]byte, size+WordSize)
-	if _, err = r.Read(dataBinary); err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading block from file")
-		return nil, utils.ErrInfo(err)
-	}
-
-	// parse the block
-	block, err := unmarshalBlockData(dataBinary, logger)
+	block, err := archive.ReadBlock(r, syspar.GetMaxBlockSize())
 	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading block from file")
 		return nil, utils.ErrInfo(err)
 	}
-
-	return &block, nil
+	return (*blockData)(block), nil
 }
 
 // read last block from file
@@ -193,32 +159,14 @@ func getLastBlockID(fileName string, logger *log.Entry) (int64, error) {
 }
 
 func unmarshalBlockData(buff []byte, logger *log.Entry) (blockData, error) {
-
-	blockID := converter.BinToDec(buff[:WordSize])
-	buff = buff[WordSize:]
-
-	// DecodeLength moves the pointer to the data field
-	blockDataLen, err := converter.DecodeLength(&buff)
+	block, err := archive.UnmarshalBlock(buff)
 	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("decoding block length")
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("decoding block record")
 		return blockData{}, utils.ErrInfo(err)
 	}
-
-	if blockDataLen > int64(len(buff)) {
-		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "length": blockDataLen, "real_length": int64(len(buff))}).Error("decoding block length")
-		return blockData{}, utils.ErrInfo("bad length")
-	}
-
-	return blockData{
-		ID:   blockID,
-		Data: buff[:blockDataLen],
-	}, nil
+	return blockData(block), nil
 }
 
 func marshallFileBlock(b blockData) []byte {
-	data := append(converter.DecToBin(b.ID, WordSize), converter.EncodeLengthPlusData(b.Data)...)
-	sizeAndData := append(converter.DecToBin(len(data), WordSize), data...)
-
-	blockBin := append(sizeAndData, converter.DecToBin(len(sizeAndData), WordSize)...)
-	return blockBin
+	return archive.MarshalBlock(archive.Block(b))
 }
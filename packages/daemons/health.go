@@ -0,0 +1,182 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daemons
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	healthOK   = `ok`
+	healthFail = `fail`
+)
+
+// HealthCheck is the result of one check of the node
+type HealthCheck struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthStatus is the result of all checks of the node
+type HealthStatus struct {
+	Status string                  `json:"status"`
+	Checks map[string]*HealthCheck `json:"checks,omitempty"`
+}
+
+type healthCheck struct {
+	name   string
+	check  func() error
+	needDB bool // the check is skipped if the database check has failed
+}
+
+// readinessChecks are run in the specified order, the database check must be the first
+var readinessChecks = []healthCheck{
+	{`database`, checkDatabase, false},
+	{`stop_daemons`, checkStopDaemons, true},
+	{`contracts`, checkContracts, false},
+	{`tcp`, checkTCP, false},
+	{`sync`, checkSync, true},
+}
+
+func checkDatabase() error {
+	if model.DBConn == nil {
+		return fmt.Errorf(`database is not connected`)
+	}
+	if err := model.DBConn.DB().Ping(); err != nil {
+		return err
+	}
+	if !CheckDB() {
+		return fmt.Errorf(`installation is not completed`)
+	}
+	return nil
+}
+
+func checkStopDaemons() error {
+	stop := &model.StopDaemon{}
+	found, err := stop.Get()
+	if err != nil {
+		return err
+	}
+	if found && stop.StopTime > 0 {
+		return fmt.Errorf(`daemons are stopping`)
+	}
+	return nil
+}
+
+func checkContracts() error {
+	if !smart.ContractsLoaded() {
+		return fmt.Errorf(`contracts are not loaded`)
+	}
+	return nil
+}
+
+func checkTCP() error {
+	if !tcpserver.IsListening() {
+		return fmt.Errorf(`tcp server is not listening`)
+	}
+	return nil
+}
+
+// checkSync checks the lag behind the remote hosts. The remote hosts are polled by BlocksCollection
+// so the check is skipped if the daemon is not started on the node
+func checkSync() error {
+	if !isConfiguredDaemon(`BlocksCollection`) || len(syspar.GetRemoteHosts()) == 0 {
+		return nil
+	}
+	if atomic.LoadInt32(&peerChecked) == 0 {
+		return syncError(false, 0)
+	}
+	return syncError(true, atomic.LoadInt64(&peerBlockID)-localBlockID())
+}
+
+func syncError(polled bool, lag int64) error {
+	if !polled {
+		return fmt.Errorf(`remote hosts have not been polled yet`)
+	}
+	if lag > conf.Config.Health.MaxBlockLag {
+		return fmt.Errorf(`node lags behind by %d blocks`, lag)
+	}
+	return nil
+}
+
+// Readiness runs the readiness checks
+func Readiness() *HealthStatus {
+	return runChecks(readinessChecks)
+}
+
+func runChecks(checks []healthCheck) *HealthStatus {
+	status := &HealthStatus{Status: healthOK, Checks: make(map[string]*HealthCheck)}
+	dbFailed := false
+	for _, item := range checks {
+		check := &HealthCheck{Status: healthOK}
+		var err error
+		if item.needDB && dbFailed {
+			err = fmt.Errorf(`database is unavailable`)
+		} else {
+			err = item.check()
+		}
+		if err != nil {
+			check.Status, check.Message = healthFail, err.Error()
+			status.Status = healthFail
+			dbFailed = dbFailed || item.name == `database`
+		}
+		status.Checks[item.name] = check
+	}
+	return status
+}
+
+func writeHealth(w http.ResponseWriter, status *HealthStatus) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if status.Status != healthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling health status")
+	}
+}
+
+// HealthLive answers that the process is running
+func HealthLive(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, &HealthStatus{Status: healthOK})
+}
+
+// HealthReady answers whether the node is ready to serve requests
+func HealthReady(w http.ResponseWriter, r *http.Request) {
+	status := Readiness()
+	if status.Status != healthOK {
+		log.WithFields(log.Fields{"checks": status.Checks}).Debug("node is not ready")
+	}
+	writeHealth(w, status)
+}
@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daemons
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+)
+
+func TestRunChecks(t *testing.T) {
+	var (
+		ok   = func() error { return nil }
+		fail = func() error { return errors.New(`failed`) }
+	)
+	for _, item := range []struct {
+		checks []healthCheck
+		status string
+		want   map[string]string
+	}{
+		{[]healthCheck{{`database`, ok, false}, {`sync`, ok, true}}, healthOK,
+			map[string]string{`database`: ``, `sync`: ``}},
+		{[]healthCheck{{`database`, fail, false}, {`sync`, ok, true}, {`tcp`, ok, false}}, healthFail,
+			map[string]string{`database`: `failed`, `sync`: `database is unavailable`, `tcp`: ``}},
+		{[]healthCheck{{`database`, ok, false}, {`tcp`, fail, false}}, healthFail,
+			map[string]string{`database`: ``, `tcp`: `failed`}},
+	} {
+		status := runChecks(item.checks)
+		if status.Status != item.status {
+			t.Errorf(`wrong status %s, want %s`, status.Status, item.status)
+		}
+		for name, msg := range item.want {
+			if check := status.Checks[name]; check == nil || check.Message != msg ||
+				(check.Status == healthOK) != (len(msg) == 0) {
+				t.Errorf(`wrong check %s %+v`, name, check)
+			}
+		}
+	}
+}
+
+func TestSyncError(t *testing.T) {
+	conf.Config.Health.MaxBlockLag = 10
+	for _, item := range []struct {
+		polled bool
+		lag    int64
+		ok     bool
+	}{
+		{false, 0, false},
+		{true, 0, true},
+		{true, 10, true},
+		{true, 11, false},
+		{true, -5, true},
+	} {
+		if err := syncError(item.polled, item.lag); (err == nil) != item.ok {
+			t.Errorf(`polled %v lag %d: %v`, item.polled, item.lag, err)
+		}
+	}
+}
+
+func TestConfiguredDaemons(t *testing.T) {
+	defer func(daemons string) { conf.Config.StartDaemons = daemons }(conf.Config.StartDaemons)
+	for _, item := range []struct {
+		daemons string
+		ok      bool
+	}{
+		{``, true},
+		{`null`, false},
+		{`BlocksCollection,QueueParserBlocks`, true},
+		{`BlockGenerator,Disseminator`, false},
+	} {
+		conf.Config.StartDaemons = item.daemons
+		if isConfiguredDaemon(`BlocksCollection`) != item.ok {
+			t.Errorf(`wrong BlocksCollection for %q`, item.daemons)
+		}
+	}
+	conf.Config.StartDaemons = `BlockGenerator`
+	if err := checkSync(); err != nil {
+		t.Errorf(`sync must be skipped without BlocksCollection: %v`, err)
+	}
+}
+
+func TestHealthLive(t *testing.T) {
+	w := httptest.NewRecorder()
+	HealthLive(w, httptest.NewRequest(`GET`, `/health/live`, nil))
+	var status HealthStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || status.Status != healthOK {
+		t.Errorf(`wrong live status %d %s`, w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	writeHealth(w, &HealthStatus{Status: healthFail})
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf(`wrong code %d`, w.Code)
+	}
+}
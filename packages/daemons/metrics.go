@@ -0,0 +1,86 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daemons
+
+import (
+	"sync/atomic"
+
+	"github.com/GenesisKernel/go-genesis/packages/metrics"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+)
+
+var (
+	daemonDuration = metrics.NewHistogram(`genesis_daemon_loop_duration_seconds`,
+		`Duration of one iteration of the daemon`, nil, `daemon`)
+	daemonErrors = metrics.NewCounter(`genesis_daemon_errors_total`,
+		`Number of daemon iterations which have finished with error`, `daemon`)
+
+	// peerBlockID is the maximum block id of the remote hosts
+	peerBlockID int64
+	// peerChecked is set when the remote hosts have been polled by BlocksCollection
+	peerChecked int32
+)
+
+func init() {
+	metrics.NewGaugeFunc(`genesis_block_height`, `Block id of the last block of the node`, func() float64 {
+		return float64(localBlockID())
+	})
+	metrics.NewGaugeFunc(`genesis_peer_block_height`, `Maximum block id of the remote hosts`, func() float64 {
+		return float64(atomic.LoadInt64(&peerBlockID))
+	})
+	metrics.NewGaugeFunc(`genesis_block_lag`, `Number of blocks which the node lags behind the remote hosts`, func() float64 {
+		lag := atomic.LoadInt64(&peerBlockID) - localBlockID()
+		if lag < 0 {
+			lag = 0
+		}
+		return float64(lag)
+	})
+	metrics.NewGaugeFunc(`genesis_mempool_transactions`, `Number of verified transactions waiting for a block`, func() float64 {
+		return dbCount(model.GetUnusedTransactionsCount)
+	})
+	metrics.NewGaugeFunc(`genesis_mempool_queue`, `Number of transactions waiting for verification`, func() float64 {
+		return dbCount(model.GetQueueTxCount)
+	})
+}
+
+func localBlockID() int64 {
+	if model.DBConn == nil {
+		return 0
+	}
+	infoBlock := &model.InfoBlock{}
+	if found, err := infoBlock.Get(); err != nil || !found {
+		return 0
+	}
+	return infoBlock.BlockID
+}
+
+func dbCount(count func() (int64, error)) float64 {
+	if model.DBConn == nil {
+		return 0
+	}
+	n, err := count()
+	if err != nil {
+		return 0
+	}
+	return float64(n)
+}
@@ -0,0 +1,198 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daemons
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/archive"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// pruneBatch is the maximum number of blocks whose rollback records are deleted at once
+const pruneBatch = 1000
+
+// Pruner deletes the rollback records of old blocks and moves their bodies to the archive files.
+// It does nothing in the archive mode
+func Pruner(ctx context.Context, d *daemon) error {
+	cfg := conf.Config.Prune
+	d.sleepTime = time.Duration(cfg.Interval) * time.Minute
+	if cfg.Interval <= 0 {
+		d.sleepTime = 10 * time.Minute
+	}
+	if cfg.Mode != conf.PruneModePrune {
+		return nil
+	}
+
+	infoBlock := &model.InfoBlock{}
+	if _, err := infoBlock.Get(); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return err
+	}
+	depth := cfg.Depth
+	if rb := syspar.GetRbBlocks1(); depth < rb {
+		depth = rb
+	}
+	// the blocks after safeBlockID can be rolled back
+	safeBlockID := infoBlock.BlockID - depth
+	if safeBlockID <= 0 {
+		return nil
+	}
+
+	info := &model.PruneInfo{}
+	found, err := info.Get()
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting prune info")
+		return err
+	}
+	if !found {
+		d.logger.WithFields(log.Fields{"type": consts.NotFound}).Error("prune info not found")
+		return nil
+	}
+
+	more, err := pruneRollbacks(info, safeBlockID, d.logger)
+	if err != nil {
+		return err
+	}
+	if len(cfg.ArchiveDir) > 0 {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		archived, err := archiveBlocks(info, cfg.ArchiveDir, cfg.BlocksPerFile, safeBlockID, d.logger)
+		if err != nil {
+			return err
+		}
+		more = more || archived
+	}
+	if more {
+		// there are more old blocks
+		d.sleepTime = time.Second
+	}
+	return nil
+}
+
+// pruneRollbacks deletes the rollback records of the next batch of blocks up to safeBlockID.
+// It returns true if there are more blocks to prune
+func pruneRollbacks(info *model.PruneInfo, safeBlockID int64, logger *log.Entry) (bool, error) {
+	if info.RollbackBlockID >= safeBlockID {
+		return false, nil
+	}
+	toBlockID := info.RollbackBlockID + pruneBatch
+	if toBlockID > safeBlockID {
+		toBlockID = safeBlockID
+	}
+	dbTx, err := model.StartTransaction()
+	if err != nil {
+		return false, err
+	}
+	deleted, err := model.PruneRollbackTxs(dbTx, info.RollbackBlockID+1, toBlockID)
+	if err == nil {
+		err = info.SetRollbackBlockID(dbTx, toBlockID)
+	}
+	if err == nil {
+		err = dbTx.Commit()
+	}
+	if err != nil {
+		dbTx.Rollback()
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": toBlockID}).Error("pruning rollback records")
+		return false, err
+	}
+	logger.WithFields(log.Fields{"block_id": toBlockID, "deleted": deleted}).Info("pruned rollback records")
+	return toBlockID < safeBlockID, nil
+}
+
+// clearArchived removes the bodies of the archived blocks from the database
+func clearArchived(info *model.PruneInfo, toBlockID int64, logger *log.Entry) error {
+	dbTx, err := model.StartTransaction()
+	if err != nil {
+		return err
+	}
+	err = model.ClearBlocksData(dbTx, info.ArchiveBlockID+1, toBlockID)
+	if err == nil {
+		err = info.SetArchiveBlockID(dbTx, toBlockID)
+	}
+	if err == nil {
+		err = dbTx.Commit()
+	}
+	if err != nil {
+		dbTx.Rollback()
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": toBlockID}).Error("clearing bodies of archived blocks")
+		return err
+	}
+	return nil
+}
+
+// archiveBlocks moves the bodies of the next blocks up to safeBlockID to the new archive file.
+// It returns true if there are more blocks to archive
+func archiveBlocks(info *model.PruneInfo, dir string, perFile, safeBlockID int64, logger *log.Entry) (bool, error) {
+	if perFile <= 0 {
+		perFile = 1000
+	}
+	lastArchived, err := archive.LastBlockID(dir)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "dir": dir}).Error("getting last archived block")
+		return false, err
+	}
+	// the file has been written but the node has been stopped before the bodies were removed
+	if lastArchived > info.ArchiveBlockID {
+		if err = clearArchived(info, lastArchived, logger); err != nil {
+			return false, err
+		}
+	}
+	toBlockID := info.ArchiveBlockID + perFile
+	if toBlockID > safeBlockID {
+		return false, nil
+	}
+	blocks, err := model.GetBlockchain(info.ArchiveBlockID, toBlockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting blockchain")
+		return false, err
+	}
+	list := make([]archive.Block, len(blocks))
+	for i, b := range blocks {
+		if b.ID != info.ArchiveBlockID+int64(i)+1 || len(b.Data) == 0 {
+			err = fmt.Errorf(`block %d is missing`, info.ArchiveBlockID+int64(i)+1)
+			logger.WithFields(log.Fields{"type": consts.NotFound, "error": err}).Error("archiving blocks")
+			return false, err
+		}
+		list[i] = archive.Block{ID: b.ID, Data: b.Data}
+	}
+	if int64(len(list)) != perFile {
+		return false, nil
+	}
+	if err = archive.WriteFile(dir, list); err != nil {
+		return false, err
+	}
+	if err = clearArchived(info, toBlockID, logger); err != nil {
+		return false, err
+	}
+	logger.WithFields(log.Fields{"block_id": toBlockID}).Info("archived blocks")
+	return toBlockID+perFile <= safeBlockID, nil
+}
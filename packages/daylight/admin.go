@@ -0,0 +1,193 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daylight
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	conf "github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/daemons"
+	"github.com/GenesisKernel/go-genesis/packages/faults"
+	"github.com/GenesisKernel/go-genesis/packages/metrics"
+
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+const adminPrefix = `/admin/`
+
+// errFaultsDisabled is returned if the faults are requested without Admin.Faults in the config
+var errFaultsDisabled = errors.New(`faults are disabled`)
+
+type adminError struct {
+	Error string `json:"error"`
+}
+
+// isAdmin checks the admin key if it is set, otherwise the request must come from the localhost
+func isAdmin(r *http.Request) bool {
+	if key := conf.Config.Admin.Key; len(key) > 0 {
+		auth := r.Header.Get(`Authorization`)
+		return strings.HasPrefix(auth, `Bearer `) &&
+			subtle.ConstantTimeCompare([]byte(auth[len(`Bearer `):]), []byte(key)) == 1
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func adminHandle(handle func(http.ResponseWriter, *http.Request, httprouter.Params) (interface{}, error)) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if !isAdmin(r) {
+			log.WithFields(log.Fields{"type": consts.AccessDenied, "remote": r.RemoteAddr, "path": r.URL.Path}).Warning("admin api access denied")
+			adminReply(w, http.StatusForbidden, adminError{`access denied`})
+			return
+		}
+		result, err := handle(w, r, ps)
+		if err != nil {
+			status := http.StatusBadRequest
+			if err == daemons.ErrUnknownDaemon {
+				status = http.StatusNotFound
+			}
+			adminReply(w, status, adminError{err.Error()})
+			return
+		}
+		adminReply(w, http.StatusOK, result)
+	}
+}
+
+func adminReply(w http.ResponseWriter, status int, result interface{}) {
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling admin api result")
+	}
+}
+
+func adminDaemons(w http.ResponseWriter, r *http.Request, ps httprouter.Params) (interface{}, error) {
+	return daemons.GetDaemonsInfo(), nil
+}
+
+func adminDaemon(w http.ResponseWriter, r *http.Request, ps httprouter.Params) (interface{}, error) {
+	return daemons.GetDaemonInfo(ps.ByName(`name`))
+}
+
+var daemonActions = map[string]func(string) error{
+	`pause`:   daemons.PauseDaemon,
+	`resume`:  daemons.ResumeDaemon,
+	`restart`: daemons.RestartDaemon,
+	`run`:     daemons.RunDaemon,
+}
+
+func adminDaemonAction(w http.ResponseWriter, r *http.Request, ps httprouter.Params) (interface{}, error) {
+	name, action := ps.ByName(`name`), ps.ByName(`action`)
+	f, ok := daemonActions[action]
+	if !ok {
+		return nil, fmt.Errorf(`unknown action %s`, action)
+	}
+	if err := f(name); err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{"daemon_name": name, "action": action, "remote": r.RemoteAddr}).Info("daemon control")
+	return daemons.GetDaemonInfo(name)
+}
+
+func adminConfigReload(w http.ResponseWriter, r *http.Request, ps httprouter.Params) (interface{}, error) {
+	changed, err := reloadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]string{`changed`: changed}, nil
+}
+
+func adminFaults(w http.ResponseWriter, r *http.Request, ps httprouter.Params) (interface{}, error) {
+	if !conf.Config.Admin.Faults {
+		return nil, errFaultsDisabled
+	}
+	if r.Method == `POST` {
+		var state faults.State
+		if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+			return nil, err
+		}
+		faults.Set(state)
+		log.WithFields(log.Fields{"blocked_hosts": state.BlockedHosts, "clock_offset": state.ClockOffset,
+			"remote": r.RemoteAddr}).Warning("faults have been injected")
+	}
+	return faults.Get(), nil
+}
+
+// adminListener serves the admin API at the separate address if Admin.Host is set
+func adminListener() {
+	if len(conf.Config.Admin.Host) == 0 {
+		return
+	}
+	route := httprouter.New()
+	adminRoute(route)
+	optionalListener(conf.Config.Admin.Str(), route)
+}
+
+// optionalListener serves the route at the address. Unlike httpListener it only logs the error
+// if the address is busy, so the node keeps working without the optional server
+func optionalListener(addr string, route http.Handler) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.WithFields(log.Fields{"host": addr, "error": err, "type": consts.NetworkError}).Error("cannot listen at host")
+		return
+	}
+	log.WithFields(log.Fields{"host": addr}).Info("listening at")
+	go func() {
+		if err := http.Serve(l, route); err != nil {
+			log.WithFields(log.Fields{"host": addr, "error": err, "type": consts.NetworkError}).Error("serving http at host")
+		}
+	}()
+}
+
+// metricsListener serves the node metrics at the separate address if Metrics.Host is set.
+// The metrics are read-only, so they are not bound to the control routes of the admin API
+func metricsListener() {
+	if len(conf.Config.Metrics.Host) == 0 {
+		return
+	}
+	route := httprouter.New()
+	route.HandlerFunc(`GET`, `/metrics`, metrics.Handler)
+	optionalListener(conf.Config.Metrics.Str(), route)
+}
+
+// adminRoute sets the routes of the node admin API
+func adminRoute(route *httprouter.Router) {
+	route.GET(adminPrefix+`daemons`, adminHandle(adminDaemons))
+	route.GET(adminPrefix+`daemons/:name`, adminHandle(adminDaemon))
+	route.POST(adminPrefix+`daemons/:name/:action`, adminHandle(adminDaemonAction))
+	route.POST(adminPrefix+`config/reload`, adminHandle(adminConfigReload))
+	route.GET(adminPrefix+`faults`, adminHandle(adminFaults))
+	route.POST(adminPrefix+`faults`, adminHandle(adminFaults))
+}
@@ -0,0 +1,344 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daylight
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/GenesisKernel/go-genesis/packages/archive"
+	conf "github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/parser"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+)
+
+// chainCommand is the command line mode for the block files, e.g. "go-genesis chain export -from 100 blocks"
+const chainCommand = `chain`
+
+// exportBatch is the count of blocks which are read from the database at once
+const exportBatch = 1000
+
+const chainUsage = `usage: chain export [-from id] [-to id] [-compress] file
+       chain import file
+       chain verify [-nodes file] [-prevHash hex] file`
+
+// runChainCommand executes the chain command and returns the exit code.
+// "chain export" writes the blocks of the database to the file in the format of the first load,
+// "chain import" inserts the blocks of the file into the empty node,
+// "chain verify" checks the hashes, signatures and linkage of the blocks of the file.
+// The value of -nodes is used for all blocks, without it the changes of full_nodes are restored
+// from the rollback records of the database
+func runChainCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, chainUsage)
+		return 2
+	}
+	flags := flag.NewFlagSet(chainCommand+` `+args[0], flag.ContinueOnError)
+	var cmd func(*flag.FlagSet) error
+	switch args[0] {
+	case `export`:
+		from := flags.Int64(`from`, 1, `first block`)
+		to := flags.Int64(`to`, 0, `last block, 0 is the last block of the database`)
+		compress := flags.Bool(`compress`, false, `compress the file by gzip`)
+		cmd = func(flags *flag.FlagSet) error {
+			return exportChain(flags.Arg(0), *from, *to, *compress)
+		}
+	case `import`:
+		cmd = func(flags *flag.FlagSet) error {
+			return importChain(flags.Arg(0))
+		}
+	case `verify`:
+		nodes := flags.String(`nodes`, ``, `file with the value of full_nodes parameter for all blocks, the database is used if it is empty`)
+		prevHash := flags.String(`prevHash`, ``, `hash of the block before the first block of the file`)
+		cmd = func(flags *flag.FlagSet) error {
+			return verifyChain(flags.Arg(0), *nodes, *prevHash)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown chain command %s\n%s\n", args[0], chainUsage)
+		return 2
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, chainUsage)
+		return 2
+	}
+	if err := cmd(flags); err != nil {
+		fmt.Fprintln(os.Stderr, `error`, err)
+		return 1
+	}
+	return 0
+}
+
+func openChainDB() error {
+	db := conf.Config.DB
+	if err := model.GormInit(db.Host, db.Port, db.User, db.Password, db.Name); err != nil {
+		return fmt.Errorf("can't connect to %s@%s/%s: %v", db.User, db.Str(), db.Name, err)
+	}
+	return nil
+}
+
+func exportChain(fileName string, from, to int64, compress bool) (err error) {
+	if from < 1 || (to > 0 && to < from) {
+		return fmt.Errorf("incorrect range of blocks %d-%d", from, to)
+	}
+	if err = openChainDB(); err != nil {
+		return err
+	}
+	defer model.GormClose()
+
+	if to == 0 {
+		last := &model.Block{}
+		found, err := last.GetMaxBlock()
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("blockchain is empty")
+		}
+		to = last.ID
+	}
+	if from > 1 {
+		prev := &model.Block{}
+		found, err := prev.Get(from - 1)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("block %d is not found", from-1)
+		}
+		fmt.Printf("hash of block %d %x\n", prev.ID, prev.Hash)
+	}
+
+	w, err := archive.NewWriter(fileName, compress)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	next := from
+	for next <= to {
+		end := next + exportBatch - 1
+		if end > to {
+			end = to
+		}
+		blocks, err := model.GetBlockchain(next-1, end)
+		if err != nil {
+			return err
+		}
+		for _, b := range blocks {
+			if b.ID != next {
+				return fmt.Errorf("block %d is not found", next)
+			}
+			if err = w.Write(archive.Block{ID: b.ID, Data: b.Data}); err != nil {
+				return err
+			}
+			next++
+		}
+		if next <= end {
+			return fmt.Errorf("block %d is not found", next)
+		}
+	}
+	fmt.Printf("blocks %d-%d have been exported\n", from, to)
+	return nil
+}
+
+func importChain(fileName string) error {
+	if err := openChainDB(); err != nil {
+		return err
+	}
+	defer model.GormClose()
+
+	found, err := (&model.InfoBlock{}).Get()
+	if err != nil {
+		return err
+	}
+	if found {
+		return fmt.Errorf("blocks can be imported only into the empty node")
+	}
+	if err = syspar.SysUpdate(nil); err != nil {
+		return err
+	}
+	if err = smart.LoadContracts(nil); err != nil {
+		return err
+	}
+	var (
+		count    int64
+		blockErr error
+	)
+	err = archive.ReadFile(fileName, syspar.GetMaxBlockSize(), func(b *archive.Block) bool {
+		if err := parser.InsertBlockWOForks(b.Data); err != nil {
+			blockErr = fmt.Errorf("block %d: %v", b.ID, err)
+			return false
+		}
+		count++
+		return true
+	})
+	if err == nil {
+		err = blockErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d blocks have been imported\n", count)
+	return nil
+}
+
+// fullNodesHistory returns the keys of full nodes by the block height. The previous values of full_nodes
+// are taken from the rollback records, so the nodes of the pruned blocks are unknown
+func fullNodesHistory() ([]parser.NodeKeys, error) {
+	param := &model.SystemParameter{}
+	found, err := param.Get(syspar.FullNodes)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s parameter is not found", syspar.FullNodes)
+	}
+	info := &model.PruneInfo{}
+	if _, err = info.Get(); err != nil {
+		return nil, err
+	}
+	rollbacks, err := model.GetRollbackTxsAfterBlock(nil, param.TableName(),
+		[]string{fmt.Sprint(param.ID)}, info.RollbackBlockID)
+	if err != nil {
+		return nil, err
+	}
+	type change struct {
+		blockID int64
+		value   string
+	}
+	// the value is changed by the block and is used starting from the next one
+	value := param.Value
+	var changes []change
+	for _, rtx := range rollbacks {
+		if len(rtx.Data) == 0 {
+			continue
+		}
+		var prev map[string]string
+		if err = json.Unmarshal([]byte(rtx.Data), &prev); err != nil {
+			return nil, err
+		}
+		old, ok := prev[`value`]
+		if !ok {
+			continue
+		}
+		if len(changes) == 0 || changes[len(changes)-1].blockID != rtx.BlockID+1 {
+			changes = append(changes, change{blockID: rtx.BlockID + 1, value: value})
+		}
+		value = old
+	}
+	changes = append(changes, change{blockID: info.RollbackBlockID + 1, value: value})
+
+	history := make([]parser.NodeKeys, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		keys, err := parser.NodeKeysFromJSON(changes[i].value)
+		if err != nil {
+			return nil, fmt.Errorf("full nodes of block %d: %v", changes[i].blockID, err)
+		}
+		history = append(history, parser.NodeKeys{BlockID: changes[i].blockID, Keys: keys})
+	}
+	return history, nil
+}
+
+func verifyChain(fileName, nodesFile, prevHash string) error {
+	var (
+		verifier parser.ChainVerifier
+		err      error
+	)
+	if len(prevHash) > 0 {
+		if verifier.PrevHash, err = hex.DecodeString(prevHash); err != nil {
+			return fmt.Errorf("prevHash: %v", err)
+		}
+	}
+	if len(nodesFile) > 0 {
+		data, err := ioutil.ReadFile(nodesFile)
+		if err != nil {
+			return err
+		}
+		keys, err := parser.NodeKeysFromJSON(string(data))
+		if err != nil {
+			return fmt.Errorf("full nodes: %v", err)
+		}
+		verifier.Nodes = []parser.NodeKeys{{BlockID: 1, Keys: keys}}
+	} else {
+		if err = openChainDB(); err != nil {
+			return err
+		}
+		err = syspar.SysUpdate(nil)
+		if err == nil {
+			verifier.Nodes, err = fullNodesHistory()
+		}
+		model.GormClose()
+		if err != nil {
+			return err
+		}
+	}
+
+	// max_block_size is unknown without the database
+	maxSize := syspar.GetMaxBlockSize()
+	if maxSize == 0 {
+		maxSize = archive.MaxRecordSize
+	}
+	var (
+		first, last int64
+		blockErr    error
+		lastHash    []byte
+	)
+	err = archive.ReadFile(fileName, maxSize, func(b *archive.Block) bool {
+		header, verr := verifier.Verify(b.Data)
+		if verr == nil && header.BlockID != b.ID {
+			verr = fmt.Errorf("block_id %d of the header doesn't match the record", header.BlockID)
+		}
+		if verr != nil {
+			blockErr = fmt.Errorf("block %d: %v", b.ID, verr)
+			return false
+		}
+		if first == 0 {
+			first = b.ID
+		}
+		last, lastHash = b.ID, header.Hash
+		return true
+	})
+	if err == nil {
+		err = blockErr
+	}
+	if err != nil {
+		return err
+	}
+	if first == 0 {
+		return fmt.Errorf("file %s doesn't contain blocks", fileName)
+	}
+	fmt.Printf("blocks %d-%d are valid, hash of block %d %x\n", first, last, last, lastHash)
+	return nil
+}
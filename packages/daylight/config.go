@@ -0,0 +1,171 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daylight
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	conf "github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/daemons"
+	logtools "github.com/GenesisKernel/go-genesis/packages/log"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/publisher"
+	"github.com/GenesisKernel/go-genesis/packages/statsd"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// configCommand is the command line mode for the config, e.g. "go-genesis -configPath=config.toml config check"
+const configCommand = `config`
+
+// validateConfig checks the parameters of the config including the ones which are parsed by other packages
+func validateConfig(cfg *conf.SavedConfig) error {
+	var errs conf.ValidationError
+	if err := cfg.Validate(); err != nil {
+		errs = err.(conf.ValidationError)
+	}
+	fail := func(field string, err error) {
+		errs = append(errs, conf.FieldError{Field: field, Err: err.Error()})
+	}
+	if len(cfg.LogLevel) > 0 {
+		if _, err := logtools.ParseLevel(cfg.LogLevel); err != nil {
+			fail(`LogLevel`, err)
+		}
+	}
+	if _, err := logtools.ParseLevels(cfg.LogLevels); err != nil {
+		fail(`LogLevels`, err)
+	}
+	if _, err := logtools.NewFormatter(cfg.LogFormat); err != nil {
+		fail(`LogFormat`, err)
+	}
+	if len(cfg.StartDaemons) > 0 && cfg.StartDaemons != `null` {
+		for _, name := range strings.Split(cfg.StartDaemons, `,`) {
+			if !daemons.IsDaemon(name) {
+				fail(`StartDaemons`, fmt.Errorf(`unknown daemon %s`, name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// runConfigCommand executes the config command and returns the exit code.
+// "config check" validates the config and checks the connection to the database,
+// "config env" prints the environment variables of the config
+func runConfigCommand(args []string, envVars []string, envErr error) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, `usage: config check|env`)
+		return 2
+	}
+	switch args[0] {
+	case `check`:
+		return checkConfig(envVars, envErr)
+	case `env`:
+		for _, name := range conf.EnvVars() {
+			fmt.Println(name)
+		}
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "unknown config command %s\n", args[0])
+	return 2
+}
+
+func checkConfig(envVars []string, envErr error) int {
+	if conf.NoConfig() {
+		fmt.Printf("config file %s is missing, default values are checked\n", conf.GetConfigPath())
+	} else {
+		fmt.Printf("config file %s\n", conf.GetConfigPath())
+	}
+	for _, name := range envVars {
+		fmt.Printf("overridden by %s\n", name)
+	}
+	var errs conf.ValidationError
+	if envErr != nil {
+		errs = append(errs, envErr.(conf.ValidationError)...)
+	}
+	if err := validateConfig(&conf.Config); err != nil {
+		errs = append(errs, err.(conf.ValidationError)...)
+	}
+	for _, err := range errs {
+		fmt.Println(`error`, err)
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+
+	db := conf.Config.DB
+	if err := model.GormInit(db.Host, db.Port, db.User, db.Password, db.Name); err != nil {
+		fmt.Printf("error DB: can't connect to %s@%s/%s: %v\n", db.User, db.Str(), db.Name, err)
+		return 1
+	}
+	model.GormClose()
+	fmt.Println(`config is valid`)
+	return 0
+}
+
+// reloadConfig reloads the config and applies the changed parameters
+func reloadConfig() ([]string, error) {
+	changed, err := conf.Reload(validateConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range changed {
+		switch name {
+		case `LogLevel`, `LogLevels`:
+			cfg := conf.Config
+			cfg.LogLevel, cfg.LogLevels = conf.Current().LogLevel, conf.Current().LogLevels
+			err = setLogFormat(&cfg)
+		case `StatsD`:
+			prev := statsd.Client()
+			cfg := conf.Current().StatsD
+			if err = statsd.Init(cfg.Host, cfg.Port, cfg.Name); err == nil && prev != nil {
+				prev.Close()
+			}
+		case `Centrifugo`:
+			publisher.InitCentrifugo(conf.Current().Centrifugo)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConfigError, "param": name, "error": err}).Error("applying reloaded parameter")
+		}
+	}
+	log.WithFields(log.Fields{"changed": changed}).Info("config has been reloaded")
+	return changed, nil
+}
+
+// waitReload reloads the config on SIGHUP
+func waitReload() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	go func() {
+		for range sigChan {
+			reloadConfig()
+		}
+	}()
+}
@@ -0,0 +1,172 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package daylight
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/GenesisKernel/go-genesis/packages/archive"
+	conf "github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/parser"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+)
+
+// replayCommand is the command line mode for the replay of blocks, e.g. "go-genesis replay -refName genesis2 -to 200"
+const replayCommand = `replay`
+
+// errDiverged is returned if the replayed block has the different result
+var errDiverged = errors.New(`state has diverged`)
+
+// runReplayCommand executes the replay command and returns the exit code.
+// The database of the config must be the snapshot of the node at some block. The next blocks are played
+// on it and the changed rows are compared with the database of the reference node
+func runReplayCommand(args []string) int {
+	ref := conf.Config.DB
+	flags := flag.NewFlagSet(replayCommand, flag.ContinueOnError)
+	to := flags.Int64(`to`, 0, `last replayed block, 0 is the last block of the reference node`)
+	fileName := flags.String(`file`, ``, `file of the exported blocks, the blocks are read from the reference node if it is empty`)
+	flags.StringVar(&ref.Host, `refHost`, ref.Host, `host of the database of the reference node`)
+	flags.IntVar(&ref.Port, `refPort`, ref.Port, `port of the database of the reference node`)
+	flags.StringVar(&ref.User, `refUser`, ref.User, `user of the database of the reference node`)
+	flags.StringVar(&ref.Password, `refPassword`, ref.Password, `password of the database of the reference node`)
+	flags.StringVar(&ref.Name, `refName`, ``, `name of the database of the reference node`)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if len(ref.Name) == 0 || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, `usage: replay -refName name [-refHost host] [-refPort port] [-refUser user] [-refPassword password] [-to id] [-file file]`)
+		return 2
+	}
+	if err := replayBlocks(ref, *to, *fileName); err != nil {
+		if err != errDiverged {
+			fmt.Fprintln(os.Stderr, `error`, err)
+		}
+		return 1
+	}
+	return 0
+}
+
+func replayBlocks(refCfg conf.DBConfig, to int64, fileName string) error {
+	if err := openChainDB(); err != nil {
+		return err
+	}
+	defer model.GormClose()
+	ref, err := model.OpenDB(refCfg)
+	if err != nil {
+		return err
+	}
+	defer ref.Close()
+
+	replayer := &parser.Replayer{Ref: ref}
+	last, err := replayer.ReplayStart()
+	if err != nil {
+		return err
+	}
+	if to == 0 {
+		info, err := model.GetOneRowTransaction(ref, `SELECT max(id) AS id FROM block_chain`).Int64()
+		if err != nil {
+			return err
+		}
+		to = info[`id`]
+	}
+	if to <= last {
+		return fmt.Errorf("local node is already at block %d", last)
+	}
+	if err = syspar.SysUpdate(nil); err != nil {
+		return err
+	}
+	if err = smart.LoadContracts(nil); err != nil {
+		return err
+	}
+
+	replay := func(blockID int64, data []byte) error {
+		div, err := replayer.ReplayBlock(data)
+		if err != nil {
+			return fmt.Errorf("block %d: %v", blockID, err)
+		}
+		if div != nil {
+			printDivergence(div)
+			return errDiverged
+		}
+		fmt.Printf("block %d is equal\n", blockID)
+		return nil
+	}
+	if len(fileName) == 0 {
+		for blockID := last + 1; blockID <= to; blockID++ {
+			data, err := replayer.RefBlockData(blockID)
+			if err != nil {
+				return err
+			}
+			if err = replay(blockID, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var blockErr error
+	err = archive.ReadFile(fileName, syspar.GetMaxBlockSize(), func(b *archive.Block) bool {
+		if b.ID <= last {
+			return true
+		}
+		if b.ID > to {
+			return false
+		}
+		blockErr = replay(b.ID, b.Data)
+		return blockErr == nil
+	})
+	if err == nil {
+		err = blockErr
+	}
+	return err
+}
+
+func printDivergence(div *parser.Divergence) {
+	fmt.Printf("block %d: row %s %s has diverged\n", div.BlockID, div.Table, div.ID)
+	if div.Local == nil {
+		fmt.Println(`  row doesn't exist on the local node`)
+	}
+	if div.Reference == nil {
+		fmt.Println(`  row doesn't exist on the reference node`)
+	}
+	if div.Local != nil && div.Reference != nil {
+		for _, column := range div.Columns {
+			fmt.Printf("  %s: local %q reference %q\n", column, div.Local[column], div.Reference[column])
+		}
+	}
+	for _, item := range []struct {
+		node    string
+		writers []parser.RowWriter
+	}{{`local`, div.Writers}, {`reference`, div.RefWriters}} {
+		if len(item.writers) == 0 {
+			fmt.Printf("  %s: row hasn't been changed by the block\n", item.node)
+		}
+		for _, w := range item.writers {
+			fmt.Printf("  %s: changed by transaction %x contract %s\n", item.node, w.TxHash, w.Contract)
+		}
+	}
+}
@@ -24,6 +24,7 @@ package daylight
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -93,30 +94,19 @@ func initLogs() error {
 		log.SetOutput(os.Stdout)
 	} else {
 		fileName := filepath.Join(conf.Config.WorkDir, conf.Config.LogFileName)
-		openMode := os.O_APPEND
-		if _, err := os.Stat(fileName); os.IsNotExist(err) {
-			openMode = os.O_CREATE
-		}
-
-		f, err := os.OpenFile(fileName, os.O_WRONLY|openMode, 0755)
+		rotate := conf.Config.LogRotate
+		w, err := logtools.NewRotateWriter(fileName, rotate.MaxSize<<20, time.Duration(rotate.Interval)*time.Hour,
+			rotate.MaxBackups, time.Duration(rotate.MaxAge)*24*time.Hour)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Can't open log file: ", fileName)
 			return err
 		}
-		log.SetOutput(f)
+		log.SetOutput(w)
 	}
 
-	switch conf.Config.LogLevel {
-	case "DEBUG":
-		log.SetLevel(log.DebugLevel)
-	case "INFO":
-		log.SetLevel(log.InfoLevel)
-	case "WARN":
-		log.SetLevel(log.WarnLevel)
-	case "ERROR":
-		log.SetLevel(log.ErrorLevel)
-	default:
-		log.SetLevel(log.InfoLevel)
+	if err := setLogFormat(&conf.Config); err != nil {
+		fmt.Fprintln(os.Stderr, "Can't set log format: ", err)
+		return err
 	}
 
 	log.AddHook(logtools.ContextHook{})
@@ -124,6 +114,26 @@ func initLogs() error {
 	return nil
 }
 
+// setLogFormat sets the format and the levels of logs, it is also called when the config is reloaded
+func setLogFormat(cfg *conf.SavedConfig) error {
+	level, err := logtools.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		level = log.InfoLevel
+	}
+	levels, err := logtools.ParseLevels(cfg.LogLevels)
+	if err != nil {
+		return err
+	}
+	formatter, err := logtools.NewFormatter(cfg.LogFormat)
+	if err != nil {
+		return err
+	}
+	levelFormatter := &logtools.LevelFormatter{Formatter: formatter, Level: level, Levels: levels}
+	log.SetFormatter(levelFormatter)
+	log.SetLevel(levelFormatter.MinLevel())
+	return nil
+}
+
 func savePid() error {
 	pid := os.Getpid()
 	PidAndVer, err := json.Marshal(map[string]string{"pid": converter.IntToStr(pid), "version": consts.VERSION})
@@ -190,6 +200,8 @@ func setRoute(route *httprouter.Router, path string, handle func(http.ResponseWr
 func initRoutes(listenHost string) {
 	route := httprouter.New()
 	setRoute(route, `/monitoring`, daemons.Monitoring, `GET`)
+	setRoute(route, `/health/live`, daemons.HealthLive, `GET`)
+	setRoute(route, `/health/ready`, daemons.HealthReady, `GET`)
 	api.Route(route)
 	route.Handler(`GET`, consts.WellKnownRoute, http.FileServer(http.Dir(*conf.TLS)))
 	if len(*conf.TLS) > 0 {
@@ -197,6 +209,8 @@ func initRoutes(listenHost string) {
 	}
 
 	httpListener(listenHost, route)
+	adminListener()
+	metricsListener()
 }
 
 // Start starts the main code of the program
@@ -241,8 +255,27 @@ func Start() {
 			conf.Installed = true
 		}
 	}
+	envVars, envErr := conf.ApplyEnv(&conf.Config)
 	conf.SetConfigParams()
 
+	if flag.Arg(0) == configCommand {
+		os.Exit(runConfigCommand(flag.Args()[1:], envVars, envErr))
+	}
+	if envErr != nil {
+		log.WithFields(log.Fields{"type": consts.ConfigError, "error": envErr}).Error("Incorrect value in environment")
+		os.Exit(1)
+	}
+	if err := validateConfig(&conf.Config); err != nil {
+		log.WithFields(log.Fields{"type": consts.ConfigError, "error": err}).Error("Invalid config")
+		os.Exit(1)
+	}
+	if flag.Arg(0) == chainCommand {
+		os.Exit(runChainCommand(flag.Args()[1:]))
+	}
+	if flag.Arg(0) == replayCommand {
+		os.Exit(runReplayCommand(flag.Args()[1:]))
+	}
+
 	autoupdate.InitUpdater(conf.Config.Autoupdate.ServerAddress, conf.Config.Autoupdate.PublicKeyPath)
 
 	// process directives
@@ -344,6 +377,7 @@ func Start() {
 	}
 
 	daemons.WaitForSignals()
+	waitReload()
 
 	initRoutes(conf.Config.HTTP.Str())
 
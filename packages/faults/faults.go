@@ -0,0 +1,86 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package faults injects the network partitions and the clock skew into the node of the test network
+package faults
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBlocked is returned when the connection to the blocked host is dialed
+var ErrBlocked = errors.New("host is blocked by the network partition")
+
+// State is the injected faults
+type State struct {
+	// BlockedHosts are the addresses of nodes which can't be connected to
+	BlockedHosts []string `json:"blocked_hosts"`
+	// ClockOffset is added to the time of the node in seconds
+	ClockOffset int64 `json:"clock_offset"`
+}
+
+var (
+	mutex   sync.RWMutex
+	blocked = make(map[string]bool)
+	offset  time.Duration
+)
+
+// Set replaces the injected faults
+func Set(state State) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	blocked = make(map[string]bool)
+	for _, host := range state.BlockedHosts {
+		blocked[host] = true
+	}
+	offset = time.Duration(state.ClockOffset) * time.Second
+}
+
+// Get returns the injected faults
+func Get() State {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	state := State{BlockedHosts: make([]string, 0, len(blocked)), ClockOffset: int64(offset / time.Second)}
+	for host := range blocked {
+		state.BlockedHosts = append(state.BlockedHosts, host)
+	}
+	return state
+}
+
+// CheckDial returns ErrBlocked if the address is blocked
+func CheckDial(addr string) error {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if blocked[addr] {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// Now returns the current time of the node shifted by the clock offset
+func Now() time.Time {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return time.Now().Add(offset)
+}
@@ -0,0 +1,47 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package faults
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFaults(t *testing.T) {
+	defer Set(State{})
+
+	Set(State{BlockedHosts: []string{`127.0.0.1:7078`}, ClockOffset: 3600})
+	if err := CheckDial(`127.0.0.1:7078`); err != ErrBlocked {
+		t.Errorf(`host must be blocked %v`, err)
+	}
+	if err := CheckDial(`127.0.0.1:7079`); err != nil {
+		t.Errorf(`host must not be blocked %v`, err)
+	}
+	if skew := Now().Sub(time.Now()); skew < 59*time.Minute {
+		t.Errorf(`wrong clock skew %s`, skew)
+	}
+	Set(State{})
+	if err := CheckDial(`127.0.0.1:7078`); err != nil {
+		t.Errorf(`partition must be healed %v`, err)
+	}
+}
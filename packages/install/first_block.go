@@ -112,7 +112,28 @@ func generateFirstBlock(publicKey, nodePublicKey []byte) error {
 		return err
 	}
 
-	block, err := parser.MarshallBlock(header, [][]byte{tx}, []byte("0"), "")
+	txs := [][]byte{tx}
+	if len(*conf.FirstBlockNodes) > 0 {
+		var nodesTx []byte
+		_, err = converter.BinMarshal(&nodesTx,
+			&consts.FirstBlockNodes{
+				TxHeader: consts.TxHeader{
+					Type: 2, // FirstBlockNodes
+
+					Time:  uint32(now),
+					KeyID: conf.Config.KeyID,
+				},
+				Nodes: *conf.FirstBlockNodes,
+			},
+		)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("first block nodes bin marshalling")
+			return err
+		}
+		txs = append(txs, nodesTx)
+	}
+
+	block, err := parser.MarshallBlock(header, txs, []byte("0"), "")
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("first block marshalling")
 		return err
@@ -132,33 +132,66 @@ func LangText(in string, state int, accept string, vde bool) (string, bool) {
 		}
 	}
 	if lres, ok := (*lang[istate]).res[in]; ok {
-		langs := strings.Split(accept, `,`)
-		lng := DefLang()
-		for _, val := range langs {
-			if len(val) < 2 {
-				break
-			}
-			if !IsLang(val[:2]) {
-				continue
-			}
-			if _, ok := (*lres)[val[:2]]; ok {
-				lng = val[:2]
-				break
-			}
+		return langValue(*lres, accept), true
+	}
+	return in, false
+}
+
+// langValue returns the meaning of the language source according to the languages specified in 'accept'
+func langValue(lres map[string]string, accept string) string {
+	langs := strings.Split(accept, `,`)
+	lng := DefLang()
+	for _, val := range langs {
+		if len(val) < 2 {
+			break
 		}
-		if len((*lres)[lng]) == 0 {
-			for _, val := range *lres {
-				return val, true
-			}
+		if !IsLang(val[:2]) {
+			continue
+		}
+		if _, ok := lres[val[:2]]; ok {
+			lng = val[:2]
+			break
 		}
-		return (*lres)[lng], true
+	}
+	if len(lres[lng]) == 0 {
+		for _, val := range lres {
+			return val
+		}
+	}
+	return lres[lng]
+}
+
+// Resources contains the language sources which are used instead of the cache
+// when templates are processed without database
+type Resources map[string]map[string]string
+
+// Text looks for the specified word through the resources like LangText does
+func (res Resources) Text(in string, accept string) (string, bool) {
+	if strings.IndexByte(in, ' ') >= 0 {
+		return in, false
+	}
+	if lres, ok := res[in]; ok {
+		return langValue(lres, accept), true
 	}
 	return in, false
 }
 
+// Macro replaces all inclusions of $resname$ in the incoming text with the resources like LangMacro does
+func (res Resources) Macro(input string, accept string) string {
+	return langMacro(input, func(name string) (string, bool) {
+		return res.Text(name, accept)
+	})
+}
+
 // LangMacro replaces all inclusions of $resname$ in the incoming text with the corresponding language resources,
 // if they exist
 func LangMacro(input string, state int, accept string, vde bool) string {
+	return langMacro(input, func(name string) (string, bool) {
+		return LangText(name, state, accept, vde)
+	})
+}
+
+func langMacro(input string, text func(string) (string, bool)) string {
 	if !strings.ContainsRune(input, '$') {
 		return input
 	}
@@ -185,7 +218,7 @@ func LangMacro(input string, state int, accept string, vde bool) string {
 			continue
 		}
 		if isName {
-			value, ok := LangText(string(name), state, accept, vde)
+			value, ok := text(string(name))
 			if ok {
 				result = append(result, []rune(value)...)
 				isName = false
@@ -0,0 +1,115 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package log
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ParseLevel converts the level name of the config to the logrus level
+func ParseLevel(name string) (logrus.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return logrus.DebugLevel, nil
+	case "INFO":
+		return logrus.InfoLevel, nil
+	case "WARN", "WARNING":
+		return logrus.WarnLevel, nil
+	case "ERROR":
+		return logrus.ErrorLevel, nil
+	}
+	return logrus.InfoLevel, fmt.Errorf(`unknown log level %s`, name)
+}
+
+// ParseLevels parses the comma separated list of name=LEVEL pairs
+func ParseLevels(input string) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level)
+	for _, item := range strings.Split(input, `,`) {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		pair := strings.SplitN(item, `=`, 2)
+		if len(pair) != 2 || len(strings.TrimSpace(pair[0])) == 0 {
+			return nil, fmt.Errorf(`wrong log level %s`, item)
+		}
+		level, err := ParseLevel(pair[1])
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(pair[0])] = level
+	}
+	return levels, nil
+}
+
+// LevelFormatter drops the entries which are filtered by the levels of subsystems.
+// The subsystem is defined by the daemon_name or type fields of the entry
+type LevelFormatter struct {
+	logrus.Formatter
+	Level  logrus.Level
+	Levels map[string]logrus.Level
+}
+
+// MinLevel returns the most verbose level of the formatter, it must be set to the logger
+func (f *LevelFormatter) MinLevel() logrus.Level {
+	min := f.Level
+	for _, level := range f.Levels {
+		if level > min {
+			min = level
+		}
+	}
+	return min
+}
+
+func (f *LevelFormatter) level(entry *logrus.Entry) logrus.Level {
+	for _, key := range []string{`daemon_name`, `type`} {
+		if name, ok := entry.Data[key].(string); ok {
+			if level, ok := f.Levels[name]; ok {
+				return level
+			}
+		}
+	}
+	return f.Level
+}
+
+// Format formats the entry if it passes the level of its subsystem
+func (f *LevelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level > f.level(entry) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// NewFormatter returns the formatter by the name of the format
+func NewFormatter(format string) (logrus.Formatter, error) {
+	switch strings.ToLower(format) {
+	case ``, `text`:
+		return &logrus.TextFormatter{}, nil
+	case `json`:
+		return &logrus.JSONFormatter{}, nil
+	}
+	return nil, fmt.Errorf(`unknown log format %s`, format)
+}
@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRotateWriter(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `rotate`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, `node.log`)
+	w, err := NewRotateWriter(fileName, 10, 0, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	w.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	for i := 0; i < 5; i++ {
+		if _, err = w.Write([]byte("12345678\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+	backups, _ := filepath.Glob(fileName + `.*`)
+	if len(backups) != 2 {
+		t.Errorf(`wrong number of backups %v`, backups)
+	}
+	data, _ := ioutil.ReadFile(fileName)
+	if string(data) != "12345678\n" {
+		t.Errorf(`wrong log file %q`, data)
+	}
+
+	w, err = NewRotateWriter(fileName, 0, time.Hour, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = func() time.Time { return now.Add(2 * time.Hour) }
+	w.created = now
+	w.Write([]byte("new\n"))
+	w.Close()
+	if backups, _ = filepath.Glob(fileName + `.*`); len(backups) != 3 {
+		t.Errorf(`file has not been rotated by time %v`, backups)
+	}
+}
+
+func TestLevelFormatter(t *testing.T) {
+	levels, err := ParseLevels(`DB=DEBUG, Disseminator=error`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ParseLevels(`DB`); err == nil {
+		t.Error(`wrong levels must fail`)
+	}
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	formatter := &LevelFormatter{Formatter: &logrus.JSONFormatter{}, Level: logrus.WarnLevel, Levels: levels}
+	logger.Formatter = formatter
+	logger.Level = formatter.MinLevel()
+
+	logger.WithFields(logrus.Fields{"type": "DB"}).Debug("db debug")
+	logger.WithFields(logrus.Fields{"daemon_name": "Disseminator", "type": "DB"}).Warn("disseminator warn")
+	logger.Info("global info")
+	logger.Warn("global warn")
+
+	out := buf.String()
+	if !strings.Contains(out, `db debug`) || !strings.Contains(out, `global warn`) ||
+		strings.Contains(out, `disseminator warn`) || strings.Contains(out, `global info`) {
+		t.Errorf("wrong output %s", out)
+	}
+	if strings.Count(out, "\n") != 2 {
+		t.Errorf("entries must be on separate lines %s", out)
+	}
+}
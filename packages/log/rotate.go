@@ -0,0 +1,156 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const rotateTimeFormat = `20060102-150405`
+
+// RotateWriter writes the log file and rotates it by size or time.
+// The rotated files have the name of the log file with the time suffix
+type RotateWriter struct {
+	mutex      sync.Mutex
+	fileName   string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	maxAge     time.Duration
+
+	file    *os.File
+	size    int64
+	created time.Time
+	now     func() time.Time
+}
+
+// NewRotateWriter opens the log file. maxSize is in bytes, zero values mean no limits
+func NewRotateWriter(fileName string, maxSize int64, interval time.Duration, maxBackups int, maxAge time.Duration) (*RotateWriter, error) {
+	w := &RotateWriter{fileName: fileName, maxSize: maxSize, interval: interval,
+		maxBackups: maxBackups, maxAge: maxAge, now: time.Now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotateWriter) open() error {
+	f, err := os.OpenFile(w.fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.size, w.created = f, info.Size(), info.ModTime()
+	if w.size == 0 {
+		w.created = w.now()
+	}
+	return nil
+}
+
+// Write writes the data to the log file and rotates it if it is necessary
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.size > 0 && ((w.maxSize > 0 && w.size+int64(len(p)) > w.maxSize) ||
+		(w.interval > 0 && w.now().Sub(w.created) >= w.interval)) {
+		if err := w.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to rotate log file, %v\n", err)
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (w *RotateWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.file.Close()
+}
+
+// rotate renames the log file and opens the new one. The current file is closed only
+// when the new file has been opened, so the writer keeps the usable file on errors
+func (w *RotateWriter) rotate() error {
+	backup := w.fileName + `.` + w.now().Format(rotateTimeFormat)
+	if _, err := os.Stat(backup); err == nil {
+		backup += fmt.Sprintf(`.%d`, w.now().UnixNano())
+	}
+	if err := os.Rename(w.fileName, backup); err != nil {
+		return err
+	}
+	old := w.file
+	if err := w.open(); err != nil {
+		// keep writing to the renamed file
+		return err
+	}
+	if err := old.Close(); err != nil {
+		return err
+	}
+	return w.cleanup()
+}
+
+// cleanup removes rotated files which exceed the retention limits
+func (w *RotateWriter) cleanup() error {
+	if w.maxBackups <= 0 && w.maxAge <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(w.fileName + `.*`)
+	if err != nil {
+		return err
+	}
+	prefix := w.fileName + `.`
+	list := backups[:0]
+	for _, name := range backups {
+		suffix := strings.SplitN(strings.TrimPrefix(name, prefix), `.`, 2)[0]
+		if _, err := time.Parse(rotateTimeFormat, suffix); err == nil {
+			list = append(list, name)
+		}
+	}
+	// the names contain the time so the newest files are at the end
+	sort.Strings(list)
+	for i, name := range list {
+		remove := w.maxBackups > 0 && i < len(list)-w.maxBackups
+		if !remove && w.maxAge > 0 {
+			suffix := strings.SplitN(strings.TrimPrefix(name, prefix), `.`, 2)[0]
+			created, _ := time.ParseInLocation(rotateTimeFormat, suffix, time.Local)
+			remove = w.now().Sub(created) > w.maxAge
+		}
+		if remove {
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
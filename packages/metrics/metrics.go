@@ -0,0 +1,443 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package metrics collects the node metrics and exposes them in the Prometheus
+// text exposition format
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	typeCounter   = `counter`
+	typeGauge     = `gauge`
+	typeHistogram = `histogram`
+
+	// ContentType is the content type of the text exposition format
+	ContentType = `text/plain; version=0.0.4; charset=utf-8`
+
+	// OtherLabel is the label value which aggregates the label sets over the limit of the family
+	OtherLabel = `other`
+)
+
+// DefBuckets are the default buckets of histograms in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultRegistry is the registry of all node metrics
+var DefaultRegistry = NewRegistry()
+
+// collector writes the samples of the metric family
+type collector interface {
+	write(buf *bytes.Buffer, name string)
+}
+
+type family struct {
+	name      string
+	help      string
+	kind      string
+	collector collector
+}
+
+// Registry keeps the metric families
+type Registry struct {
+	mutex    sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry returns the empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+func (r *Registry) register(name, help, kind string, c collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.families[name]; ok {
+		panic(fmt.Sprintf(`metric %s has been already registered`, name))
+	}
+	r.families[name] = &family{name: name, help: help, kind: kind, collector: c}
+}
+
+// WriteTo writes all metrics of the registry in the text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := r.families
+	r.mutex.Unlock()
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		f := families[name]
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+		f.collector.write(&buf, f.name)
+	}
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// Handler returns http handler of metrics
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(`Content-Type`, ContentType)
+		if _, err := r.WriteTo(w); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing metrics")
+		}
+	}
+}
+
+// Handler serves the metrics of the default registry
+func Handler(w http.ResponseWriter, r *http.Request) {
+	DefaultRegistry.Handler()(w, r)
+}
+
+// vec keeps the metrics of the family by label values
+type vec struct {
+	mutex   sync.Mutex
+	labels  []string
+	items   map[string]interface{}
+	values  map[string][]string
+	factory func() interface{}
+	limit   int // the maximum number of label sets, 0 is unlimited
+}
+
+func newVec(labels []string, factory func() interface{}) *vec {
+	return &vec{labels: labels, items: make(map[string]interface{}),
+		values: make(map[string][]string), factory: factory}
+}
+
+func (v *vec) with(values []string) interface{} {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf(`expected %d label values, got %d`, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	item, ok := v.items[key]
+	if !ok && v.limit > 0 && len(v.items) >= v.limit {
+		values = make([]string, len(v.labels))
+		for i := range values {
+			values[i] = OtherLabel
+		}
+		key = strings.Join(values, "\xff")
+		item, ok = v.items[key]
+	}
+	if !ok {
+		item = v.factory()
+		v.items[key] = item
+		v.values[key] = append([]string{}, values...)
+	}
+	return item
+}
+
+// each calls f for the items sorted by label values
+func (v *vec) each(f func(labels string, item interface{})) {
+	v.mutex.Lock()
+	keys := make([]string, 0, len(v.items))
+	for key := range v.items {
+		keys = append(keys, key)
+	}
+	v.mutex.Unlock()
+	sort.Strings(keys)
+	for _, key := range keys {
+		v.mutex.Lock()
+		item, values := v.items[key], v.values[key]
+		v.mutex.Unlock()
+		f(formatLabels(v.labels, values), item)
+	}
+}
+
+// value is float64 protected by mutex
+type value struct {
+	mutex sync.Mutex
+	val   float64
+}
+
+func (v *value) add(delta float64) {
+	v.mutex.Lock()
+	v.val += delta
+	v.mutex.Unlock()
+}
+
+func (v *value) set(val float64) {
+	v.mutex.Lock()
+	v.val = val
+	v.mutex.Unlock()
+}
+
+func (v *value) get() float64 {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	return v.val
+}
+
+// Counter is the monotonically increasing value
+type Counter struct {
+	value
+}
+
+// Inc increments the counter
+func (c *Counter) Inc() {
+	c.add(1)
+}
+
+// Add adds the non-negative value to the counter
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.add(delta)
+}
+
+// CounterVec is the counter with labels
+type CounterVec struct {
+	*vec
+}
+
+// With returns the counter for the label values
+func (c *CounterVec) With(values ...string) *Counter {
+	return c.with(values).(*Counter)
+}
+
+// Limit restricts the number of label sets of the counter. The new label sets over
+// the limit are counted with OtherLabel values
+func (c *CounterVec) Limit(n int) *CounterVec {
+	c.limit = n
+	return c
+}
+
+func (c *CounterVec) write(buf *bytes.Buffer, name string) {
+	c.each(func(labels string, item interface{}) {
+		writeSample(buf, name, labels, item.(*Counter).get())
+	})
+}
+
+// NewCounter registers the counter in the registry
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(labels, func() interface{} { return &Counter{} })}
+	r.register(name, help, typeCounter, c)
+	return c
+}
+
+// Gauge is the value which can go up and down
+type Gauge struct {
+	value
+}
+
+// Set sets the gauge value
+func (g *Gauge) Set(val float64) {
+	g.set(val)
+}
+
+// Add adds the value to the gauge
+func (g *Gauge) Add(delta float64) {
+	g.add(delta)
+}
+
+// GaugeVec is the gauge with labels
+type GaugeVec struct {
+	*vec
+}
+
+// With returns the gauge for the label values
+func (g *GaugeVec) With(values ...string) *Gauge {
+	return g.with(values).(*Gauge)
+}
+
+func (g *GaugeVec) write(buf *bytes.Buffer, name string) {
+	g.each(func(labels string, item interface{}) {
+		writeSample(buf, name, labels, item.(*Gauge).get())
+	})
+}
+
+// NewGauge registers the gauge in the registry
+func (r *Registry) NewGauge(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec(labels, func() interface{} { return &Gauge{} })}
+	r.register(name, help, typeGauge, g)
+	return g
+}
+
+type gaugeFunc func() float64
+
+func (g gaugeFunc) write(buf *bytes.Buffer, name string) {
+	writeSample(buf, name, ``, g())
+}
+
+// NewGaugeFunc registers the gauge which value is calculated when the metrics are collected
+func (r *Registry) NewGaugeFunc(name, help string, f func() float64) {
+	r.register(name, help, typeGauge, gaugeFunc(f))
+}
+
+// Histogram counts observations in buckets
+type Histogram struct {
+	mutex   sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// Observe adds the observation
+func (h *Histogram) Observe(val float64) {
+	i := sort.SearchFloat64s(h.buckets, val)
+	h.mutex.Lock()
+	if i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.sum += val
+	h.count++
+	h.mutex.Unlock()
+}
+
+// Since observes the duration in seconds from the start time
+func (h *Histogram) Since(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// HistogramVec is the histogram with labels
+type HistogramVec struct {
+	*vec
+}
+
+// With returns the histogram for the label values
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.with(values).(*Histogram)
+}
+
+func (h *HistogramVec) write(buf *bytes.Buffer, name string) {
+	h.each(func(labels string, item interface{}) {
+		hist := item.(*Histogram)
+		hist.mutex.Lock()
+		counts := append([]uint64{}, hist.counts...)
+		sum, count := hist.sum, hist.count
+		hist.mutex.Unlock()
+
+		var total uint64
+		for i, bound := range hist.buckets {
+			total += counts[i]
+			writeSample(buf, name+`_bucket`, addLabel(labels, `le`, formatFloat(bound)), float64(total))
+		}
+		writeSample(buf, name+`_bucket`, addLabel(labels, `le`, `+Inf`), float64(count))
+		writeSample(buf, name+`_sum`, labels, sum)
+		writeSample(buf, name+`_count`, labels, float64(count))
+	})
+}
+
+// NewHistogram registers the histogram in the registry. DefBuckets are used if buckets are empty
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{newVec(labels, func() interface{} {
+		return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+	})}
+	r.register(name, help, typeHistogram, h)
+	return h
+}
+
+// NewCounter registers the counter in the default registry
+func NewCounter(name, help string, labels ...string) *CounterVec {
+	return DefaultRegistry.NewCounter(name, help, labels...)
+}
+
+// NewGauge registers the gauge in the default registry
+func NewGauge(name, help string, labels ...string) *GaugeVec {
+	return DefaultRegistry.NewGauge(name, help, labels...)
+}
+
+// NewGaugeFunc registers the calculated gauge in the default registry
+func NewGaugeFunc(name, help string, f func() float64) {
+	DefaultRegistry.NewGaugeFunc(name, help, f)
+}
+
+// NewHistogram registers the histogram in the default registry
+func NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return DefaultRegistry.NewHistogram(name, help, buckets, labels...)
+}
+
+func writeSample(buf *bytes.Buffer, name, labels string, val float64) {
+	buf.WriteString(name)
+	if len(labels) > 0 {
+		buf.WriteString(`{` + labels + `}`)
+	}
+	buf.WriteString(` ` + formatFloat(val) + "\n")
+}
+
+func formatLabels(names, values []string) string {
+	list := make([]string, len(names))
+	for i, name := range names {
+		list[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+	return strings.Join(list, `,`)
+}
+
+func addLabel(labels, name, val string) string {
+	label := name + `="` + escapeLabel(val) + `"`
+	if len(labels) == 0 {
+		return label
+	}
+	return labels + `,` + label
+}
+
+func formatFloat(val float64) string {
+	switch {
+	case math.IsInf(val, 1):
+		return `+Inf`
+	case math.IsInf(val, -1):
+		return `-Inf`
+	case math.IsNaN(val):
+		return `NaN`
+	}
+	return strconv.FormatFloat(val, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
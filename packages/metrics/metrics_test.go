@@ -0,0 +1,108 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter(`test_requests_total`, `Requests`, `route`, `code`)
+	requests.With(`block/:id`, `200`).Inc()
+	requests.With(`block/:id`, `200`).Add(2)
+	requests.With(`say "hi"`, `500`).Inc()
+	r.NewGauge(`test_height`, "Block\nheight").With().Set(42)
+	r.NewGaugeFunc(`test_limit`, `Limit`, func() float64 { return 20 })
+	duration := r.NewHistogram(`test_duration_seconds`, `Duration`, []float64{1, 0.1}, `daemon`)
+	duration.With(`Disseminator`).Observe(0.05)
+	duration.With(`Disseminator`).Observe(0.5)
+	duration.With(`Disseminator`).Observe(3)
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_duration_seconds Duration
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{daemon="Disseminator",le="0.1"} 1
+test_duration_seconds_bucket{daemon="Disseminator",le="1"} 2
+test_duration_seconds_bucket{daemon="Disseminator",le="+Inf"} 3
+test_duration_seconds_sum{daemon="Disseminator"} 3.55
+test_duration_seconds_count{daemon="Disseminator"} 3
+# HELP test_height Block\nheight
+# TYPE test_height gauge
+test_height 42
+# HELP test_limit Limit
+# TYPE test_limit gauge
+test_limit 20
+# HELP test_requests_total Requests
+# TYPE test_requests_total counter
+test_requests_total{route="block/:id",code="200"} 3
+test_requests_total{route="say \"hi\"",code="500"} 1
+`
+	if buf.String() != want {
+		t.Errorf("wrong output\n%s", buf.String())
+	}
+
+	w := httptest.NewRecorder()
+	r.Handler()(w, httptest.NewRequest(`GET`, `/metrics`, nil))
+	if w.Header().Get(`Content-Type`) != ContentType || !strings.Contains(w.Body.String(), `test_limit 20`) {
+		t.Errorf(`wrong response %v`, w.Header())
+	}
+}
+
+func TestCounterLimit(t *testing.T) {
+	r := NewRegistry()
+	calls := r.NewCounter(`test_calls_total`, `Calls`, `contract`).Limit(2)
+	for _, name := range []string{`A`, `B`, `C`, `D`, `A`} {
+		calls.With(name).Inc()
+	}
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_calls_total Calls
+# TYPE test_calls_total counter
+test_calls_total{contract="A"} 2
+test_calls_total{contract="B"} 1
+test_calls_total{contract="other"} 2
+`
+	if buf.String() != want {
+		t.Errorf("wrong output\n%s", buf.String())
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error(`duplicate metric must panic`)
+		}
+	}()
+	r := NewRegistry()
+	r.NewCounter(`test_total`, ``)
+	r.NewGauge(`test_total`, ``)
+}
@@ -251,4 +251,170 @@ var (
 		"stop_time" int NOT NULL DEFAULT '0'
 		);
 		`
+
+	migrationOracles = `DROP TABLE IF EXISTS "oracles"; CREATE TABLE "oracles" (
+		"id" bigint NOT NULL DEFAULT '0',
+		"key_id" bigint NOT NULL DEFAULT '0',
+		"feeds" text NOT NULL DEFAULT '',
+		"active" character(1) NOT NULL DEFAULT '1'
+		);
+		ALTER TABLE ONLY "oracles" ADD CONSTRAINT oracles_pkey PRIMARY KEY (id);
+		CREATE UNIQUE INDEX "oracles_index_key" ON "oracles" (key_id);
+
+		DROP TABLE IF EXISTS "oracle_values"; CREATE TABLE "oracle_values" (
+		"id" bigint NOT NULL DEFAULT '0',
+		"feed" varchar(255) NOT NULL DEFAULT '',
+		"key_id" bigint NOT NULL DEFAULT '0',
+		"value" text NOT NULL DEFAULT '',
+		"time" int NOT NULL DEFAULT '0',
+		"block_id" int NOT NULL DEFAULT '0'
+		);
+		ALTER TABLE ONLY "oracle_values" ADD CONSTRAINT oracle_values_pkey PRIMARY KEY (id);
+		CREATE INDEX "oracle_values_index_feed" ON "oracle_values" (feed, time);
+
+		INSERT INTO system_parameters ("id","name", "value", "conditions") VALUES 
+		('62','oracle_quorum', '1', 'true'),
+		('63','extend_cost_oracle_value', '50', 'true');
+
+		INSERT INTO system_tables ("name", "permissions","columns", "conditions") VALUES  ('oracles',
+				'{"insert": "false", "update": "false", "new_column": "false"}','{}', 'ContractAccess(\"@0UpdSysContract\")'),
+				('oracle_values',
+				'{"insert": "false", "update": "false", "new_column": "false"}','{}', 'ContractAccess(\"@0UpdSysContract\")');
+		`
+
+	migrationAPITokens = `DROP SEQUENCE IF EXISTS api_tokens_id_seq CASCADE;
+		CREATE SEQUENCE api_tokens_id_seq START WITH 1;
+		DROP TABLE IF EXISTS "api_tokens"; CREATE TABLE "api_tokens" (
+		"id" bigint NOT NULL  default nextval('api_tokens_id_seq'),
+		"ecosystem_id" bigint NOT NULL DEFAULT '0',
+		"key_id" bigint NOT NULL DEFAULT '0',
+		"name" varchar(255) NOT NULL DEFAULT '',
+		"hash" varchar(64) NOT NULL DEFAULT '',
+		"scope" text NOT NULL DEFAULT '',
+		"created" bigint NOT NULL DEFAULT '0',
+		"expire" bigint NOT NULL DEFAULT '0',
+		"revoked" bigint NOT NULL DEFAULT '0'
+		);
+		ALTER SEQUENCE api_tokens_id_seq owned by api_tokens.id;
+		ALTER TABLE ONLY "api_tokens" ADD CONSTRAINT api_tokens_pkey PRIMARY KEY (id);
+		CREATE UNIQUE INDEX "api_tokens_index_hash" ON "api_tokens" (hash);
+		CREATE INDEX "api_tokens_index_key" ON "api_tokens" (ecosystem_id, key_id);
+		`
+
+	migrationTxHistory = `DROP SEQUENCE IF EXISTS tx_history_id_seq CASCADE;
+		CREATE SEQUENCE tx_history_id_seq START WITH 1;
+		DROP TABLE IF EXISTS "tx_history"; CREATE TABLE "tx_history" (
+		"id" bigint NOT NULL  default nextval('tx_history_id_seq'),
+		"hash" bytea  NOT NULL DEFAULT '',
+		"block_id" bigint NOT NULL DEFAULT '0',
+		"time" bigint NOT NULL DEFAULT '0',
+		"key_id" bigint NOT NULL DEFAULT '0',
+		"ecosystem" bigint NOT NULL DEFAULT '0',
+		"contract" varchar(255) NOT NULL DEFAULT '',
+		"params" text NOT NULL DEFAULT '',
+		"result" text NOT NULL DEFAULT '',
+		"error" text NOT NULL DEFAULT '',
+		"fuel" decimal(30) NOT NULL DEFAULT '0'
+		);
+		ALTER SEQUENCE tx_history_id_seq owned by tx_history.id;
+		ALTER TABLE ONLY "tx_history" ADD CONSTRAINT tx_history_pkey PRIMARY KEY (id);
+		CREATE INDEX "tx_history_index_hash" ON "tx_history" (hash);
+		CREATE INDEX "tx_history_index_key" ON "tx_history" (key_id, id);
+		CREATE INDEX "tx_history_index_contract" ON "tx_history" (ecosystem, contract, id);
+		CREATE INDEX "tx_history_index_block" ON "tx_history" (block_id);
+		`
+
+	migrationPruneInfo = `DROP TABLE IF EXISTS "prune_info"; CREATE TABLE "prune_info" (
+		"rollback_block_id" bigint NOT NULL DEFAULT '0',
+		"archive_block_id" bigint NOT NULL DEFAULT '0'
+		);
+		INSERT INTO "prune_info" ("rollback_block_id", "archive_block_id") VALUES ('0', '0');
+		CREATE INDEX IF NOT EXISTS "rollback_tx_index_block" ON "rollback_tx" (block_id);
+		`
+
+	// migrationOracleContracts adds the oracle contracts to the first ecosystem which has been created
+	// before them. The new ecosystem gets these contracts from SchemaFirstEcosystem
+	migrationOracleContracts = `DO $$
+		DECLARE
+			wallet bigint;
+			next_id bigint;
+		BEGIN
+			IF to_regclass('"1_contracts"') IS NULL THEN
+				RETURN;
+			END IF;
+			SELECT wallet_id INTO wallet FROM "1_contracts" WHERE id = 1;
+			SELECT max(id) + 1 INTO next_id FROM "1_contracts";
+
+			IF NOT EXISTS (SELECT 1 FROM "1_contracts" WHERE value LIKE 'contract NewOracle %') THEN
+				INSERT INTO "1_contracts" ("id","value", "wallet_id", "conditions") VALUES
+				(next_id, 'contract NewOracle {
+					data {
+						Wallet string
+						Feeds  string
+					}
+					conditions {
+						ContractConditions("MainCondition")
+						$oracle = AddressToId($Wallet)
+						if $oracle == 0 {
+							error Sprintf("Oracle wallet %s is invalid", $Wallet)
+						}
+					}
+					action {
+						$result = RegisterOracle($oracle, $Feeds)
+					}
+				}', wallet, 'ContractConditions("MainCondition")');
+				next_id := next_id + 1;
+			END IF;
+
+			IF NOT EXISTS (SELECT 1 FROM "1_contracts" WHERE value LIKE 'contract EditOracle %') THEN
+				INSERT INTO "1_contracts" ("id","value", "wallet_id", "conditions") VALUES
+				(next_id, 'contract EditOracle {
+					data {
+						Id     int
+						Feeds  string
+						Active int
+					}
+					conditions {
+						ContractConditions("MainCondition")
+					}
+					action {
+						UpdateOracle($Id, $Feeds, $Active)
+					}
+				}', wallet, 'ContractConditions("MainCondition")');
+				next_id := next_id + 1;
+			END IF;
+
+			IF NOT EXISTS (SELECT 1 FROM "1_contracts" WHERE value LIKE 'contract OracleSubmit %') THEN
+				INSERT INTO "1_contracts" ("id","value", "wallet_id", "conditions") VALUES
+				(next_id, 'contract OracleSubmit {
+					data {
+						Feed  string
+						Value string
+						Time  int
+					}
+					action {
+						$result = SubmitOracleValue($Feed, $Value, $Time)
+					}
+				}', wallet, 'ContractConditions("MainCondition")');
+			END IF;
+		END $$;
+		`
+
+	migrationRollbackTxIndex = `CREATE INDEX IF NOT EXISTS "rollback_tx_index_table" ON "rollback_tx" (table_name, table_id, block_id);`
+
+	migrationHTTPRecords = `DROP SEQUENCE IF EXISTS http_records_id_seq CASCADE;
+		CREATE SEQUENCE http_records_id_seq START WITH 1;
+		DROP TABLE IF EXISTS "http_records"; CREATE TABLE "http_records" (
+		"id" bigint NOT NULL  default nextval('http_records_id_seq'),
+		"tx_hash" bytea  NOT NULL DEFAULT '',
+		"method" varchar(16) NOT NULL DEFAULT '',
+		"url" text NOT NULL DEFAULT '',
+		"hash" varchar(64) NOT NULL DEFAULT '',
+		"status" int NOT NULL DEFAULT '0',
+		"body" text NOT NULL DEFAULT ''
+		);
+		ALTER SEQUENCE http_records_id_seq owned by http_records.id;
+		ALTER TABLE ONLY "http_records" ADD CONSTRAINT http_records_pkey PRIMARY KEY (id);
+		CREATE INDEX "http_records_index_tx" ON "http_records" (tx_hash, id);
+		`
 )
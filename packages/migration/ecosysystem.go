@@ -97,7 +97,12 @@ var (
 	  ('9','changing_contracts', 'ContractConditions("MainCondition")', 'ContractConditions("MainCondition")'),
 	  ('10','stylesheet', 'body { 
 		/* You can define your custom styles here or create custom CSS rules */
-	  }', 'ContractConditions("MainCondition")');
+	  }', 'ContractConditions("MainCondition")'),
+	  ('11','http_allowed_hosts', '', 'ContractConditions("MainCondition")'),
+	  ('12','http_allowed_methods', 'GET,POST', 'ContractConditions("MainCondition")'),
+	  ('13','http_max_response_size', '1048576', 'ContractConditions("MainCondition")'),
+	  ('14','http_timeout', '10', 'ContractConditions("MainCondition")'),
+	  ('15','http_record', '0', 'ContractConditions("MainCondition")');
 
 	  DROP TABLE IF EXISTS "%[1]d_vde_cron";
 	  CREATE TABLE "%[1]d_vde_cron" (
@@ -1625,5 +1630,44 @@ var (
 		action {
 			DBUpdateSysParam($Name, $Value, $Conditions )
 		}
+	}', '%[1]d','ContractConditions("MainCondition")'),
+	('29','contract NewOracle {
+		data {
+			Wallet string
+			Feeds  string
+		}
+		conditions {
+			ContractConditions("MainCondition")
+			$oracle = AddressToId($Wallet)
+			if $oracle == 0 {
+				error Sprintf("Oracle wallet %%s is invalid", $Wallet)
+			}
+		}
+		action {
+			$result = RegisterOracle($oracle, $Feeds)
+		}
+	}', '%[1]d','ContractConditions("MainCondition")'),
+	('30','contract EditOracle {
+		data {
+			Id     int
+			Feeds  string
+			Active int
+		}
+		conditions {
+			ContractConditions("MainCondition")
+		}
+		action {
+			UpdateOracle($Id, $Feeds, $Active)
+		}
+	}', '%[1]d','ContractConditions("MainCondition")'),
+	('31','contract OracleSubmit {
+		data {
+			Feed  string
+			Value string
+			Time  int
+		}
+		action {
+			$result = SubmitOracleValue($Feed, $Value, $Time)
+		}
 	}', '%[1]d','ContractConditions("MainCondition")');`
 )
@@ -23,6 +23,8 @@
 package migration
 
 import (
+	"regexp"
+
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 
 	version "github.com/hashicorp/go-version"
@@ -35,8 +37,32 @@ var migrations = []*migration{
 
 	// Initial schema
 	&migration{"0.1.6b9", migrationInitialSchema},
+
+	// Oracles
+	&migration{"0.1.6b12", migrationOracles},
+
+	// Scoped API tokens
+	&migration{"0.1.6b13", migrationAPITokens},
+
+	// Index of transactions by account and contract
+	&migration{"0.1.6b14", migrationTxHistory},
+
+	// Pruning of old blocks
+	&migration{"0.1.6b15", migrationPruneInfo},
+
+	// Oracle contracts of the existing first ecosystem
+	&migration{"0.1.6b16", migrationOracleContracts},
+
+	// Rollback records of the rows for the historical queries
+	&migration{"0.1.6b17", migrationRollbackTxIndex},
+
+	// Recorded answers of http requests of transactions
+	&migration{"0.1.6b18", migrationHTTPRecords},
 }
 
+// regPrerelease matches the number of the prerelease which is compared as a string by go-version
+var regPrerelease = regexp.MustCompile(`^(\d+\.\d+\.\d+[a-z]+)(\d+)$`)
+
 type migration struct {
 	version string
 	data    string
@@ -54,7 +80,7 @@ func migrate(db database, appVer *version.Version, migrations []*migration) erro
 		return err
 	}
 
-	dbVer, err := version.NewVersion(dbVerString)
+	dbVer, err := newVersion(dbVerString)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.MigrationError, "err": err}).Errorf("parse version")
 		return err
@@ -66,7 +92,7 @@ func migrate(db database, appVer *version.Version, migrations []*migration) erro
 	}
 
 	for _, m := range migrations {
-		mgrVer, err := version.NewVersion(m.version)
+		mgrVer, err := newVersion(m.version)
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.MigrationError, "err": err}).Errorf("parse version")
 			return err
@@ -89,7 +115,7 @@ func migrate(db database, appVer *version.Version, migrations []*migration) erro
 
 // Migrate applies migrations
 func Migrate(db database) error {
-	appVer, err := version.NewVersion(consts.VERSION)
+	appVer, err := newVersion(consts.VERSION)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.MigrationError, "err": err}).Errorf("parse version")
 		return err
@@ -97,3 +123,8 @@ func Migrate(db database) error {
 
 	return migrate(db, appVer, migrations)
 }
+
+// newVersion parses the version, the number of the prerelease is separated so 0.1.6b12 is greater than 0.1.6b9
+func newVersion(ver string) (*version.Version, error) {
+	return version.NewVersion(regPrerelease.ReplaceAllString(ver, `$1.$2`))
+}
@@ -25,6 +25,8 @@ package migration
 import (
 	"testing"
 
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
 	version "github.com/hashicorp/go-version"
 )
 
@@ -84,3 +86,28 @@ func TestMockMigration(t *testing.T) {
 		t.Errorf("current version expected 0.0.2 get %s", v)
 	}
 }
+
+func TestNewVersion(t *testing.T) {
+	older := version.Must(newVersion("0.1.6b9"))
+	if !older.LessThan(version.Must(newVersion("0.1.6b12"))) {
+		t.Error("0.1.6b9 must be less than 0.1.6b12")
+	}
+	if !older.LessThan(version.Must(newVersion("0.1.6"))) {
+		t.Error("0.1.6b9 must be less than 0.1.6")
+	}
+}
+
+func TestMigrationsVersion(t *testing.T) {
+	prev := version.Must(version.NewVersion("0"))
+	for _, m := range migrations {
+		ver := version.Must(newVersion(m.version))
+		if !prev.LessThan(ver) {
+			t.Errorf("migration %s must be greater than %s", ver, prev)
+		}
+		prev = ver
+	}
+	// the last migration is skipped by the nodes if it is greater than the version of the application
+	if !prev.Equal(version.Must(newVersion(consts.VERSION))) {
+		t.Errorf("version %s must be equal to the last migration %s", consts.VERSION, prev)
+	}
+}
@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package model
+
+// APIToken is the scoped service token of API, only the hash of the token is stored
+type APIToken struct {
+	ID          int64  `gorm:"primary_key;not null" json:"id"`
+	EcosystemID int64  `gorm:"not null" json:"ecosystem_id"`
+	KeyID       int64  `gorm:"not null" json:"key_id"`
+	Name        string `gorm:"not null;size:255" json:"name"`
+	Hash        string `gorm:"not null;size:64" json:"-"`
+	Scope       string `gorm:"not null" json:"scope"`
+	Created     int64  `gorm:"not null" json:"created"`
+	Expire      int64  `gorm:"not null" json:"expire"`
+	Revoked     int64  `gorm:"not null" json:"revoked"`
+}
+
+// TableName returns name of table
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// Create is creating record of model
+func (t *APIToken) Create() error {
+	return DBConn.Create(t).Error
+}
+
+// GetByHash is retrieving the token by the hash
+func (t *APIToken) GetByHash(hash string) (bool, error) {
+	return isFound(DBConn.Where("hash = ?", hash).First(t))
+}
+
+// GetAPITokens returns the tokens of the key
+func GetAPITokens(ecosystemID, keyID int64) ([]APIToken, error) {
+	var tokens []APIToken
+	err := DBConn.Where("ecosystem_id = ? AND key_id = ?", ecosystemID, keyID).Order("id").Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeAPIToken marks the token of the key as revoked, it returns false if the token is not found
+func RevokeAPIToken(id, ecosystemID, keyID, revoked int64) (bool, error) {
+	db := DBConn.Model(&APIToken{}).Where("id = ? AND ecosystem_id = ? AND key_id = ? AND revoked = 0",
+		id, ecosystemID, keyID).Update("revoked", revoked)
+	return db.RowsAffected > 0, db.Error
+}
+
+// GetRevokedAPITokens returns the ids of revoked tokens which have not expired
+func GetRevokedAPITokens(now int64) ([]int64, error) {
+	var ids []int64
+	err := DBConn.Model(&APIToken{}).Where("revoked > 0 AND (expire = 0 OR expire > ?)", now).Pluck("id", &ids).Error
+	return ids, err
+}
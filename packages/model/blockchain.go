@@ -22,6 +22,10 @@
 
 package model
 
+import (
+	"github.com/GenesisKernel/go-genesis/packages/archive"
+)
+
 // Block is model
 type Block struct {
 	ID            int64  `gorm:"primary_key;not_null"`
@@ -47,7 +51,48 @@ func (b *Block) Create(transaction *DbTransaction) error {
 
 // Get is retrieving model from database
 func (b *Block) Get(blockID int64) (bool, error) {
-	return isFound(DBConn.Where("id = ?", blockID).First(b))
+	found, err := isFound(DBConn.Where("id = ?", blockID).First(b))
+	if found && err == nil {
+		err = b.loadArchived()
+	}
+	return found, err
+}
+
+// loadArchived reads the body of the block from the archive if it has been moved there
+func (b *Block) loadArchived() (err error) {
+	if len(b.Data) == 0 && b.ID > 0 {
+		b.Data, err = archive.GetBlockData(b.ID)
+	}
+	return
+}
+
+// loadArchivedBlocks reads the bodies of the blocks which have been moved to the archive
+func loadArchivedBlocks(blocks []Block) error {
+	var ids []int64
+	for _, b := range blocks {
+		if len(b.Data) == 0 && b.ID > 0 {
+			ids = append(ids, b.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	data, err := archive.GetBlocksData(ids)
+	if err != nil {
+		return err
+	}
+	for i := range blocks {
+		if len(blocks[i].Data) == 0 {
+			blocks[i].Data = data[blocks[i].ID]
+		}
+	}
+	return nil
+}
+
+// ClearBlocksData removes the bodies of the blocks from the range which have been moved to the archive
+func ClearBlocksData(transaction *DbTransaction, fromBlockID, toBlockID int64) error {
+	return GetDB(transaction).Exec(`UPDATE block_chain SET data = '' WHERE id >= ? AND id <= ?`,
+		fromBlockID, toBlockID).Error
 }
 
 // GetMaxBlock returns last block existence
@@ -67,6 +112,9 @@ func GetBlockchain(startBlockID int64, endblockID int64) ([]Block, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = loadArchivedBlocks(*blockchain); err != nil {
+		return nil, err
+	}
 	return *blockchain, nil
 }
 
@@ -79,6 +127,9 @@ func (b *Block) GetBlocks(startFromID int64, limit int32) ([]Block, error) {
 	} else {
 		err = DBConn.Order("id desc").Limit(limit).Find(&blockchain).Error
 	}
+	if err == nil {
+		err = loadArchivedBlocks(*blockchain)
+	}
 	return *blockchain, err
 }
 
@@ -87,6 +138,9 @@ func (b *Block) GetBlocksFrom(startFromID int64, ordering string) ([]Block, erro
 	var err error
 	blockchain := new([]Block)
 	err = DBConn.Order("id "+ordering).Where("id > ?", startFromID).Find(&blockchain).Error
+	if err == nil {
+		err = loadArchivedBlocks(*blockchain)
+	}
 	return *blockchain, err
 }
 
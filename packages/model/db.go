@@ -23,6 +23,7 @@
 package model
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -104,6 +105,21 @@ func StartTransaction() (*DbTransaction, error) {
 	}, nil
 }
 
+// StartReadTransaction begins the read-only transaction with the snapshot of the database,
+// so the rows and their rollback records are read at the same state even if the blocks are applied
+func StartReadTransaction() (*DbTransaction, error) {
+	tr, err := StartTransaction()
+	if err != nil {
+		return nil, err
+	}
+	if err = tr.conn.Exec(`SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY`).Error; err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("setting isolation level of transaction")
+		tr.Rollback()
+		return nil, err
+	}
+	return tr, nil
+}
+
 // Rollback is transaction rollback
 func (tr *DbTransaction) Rollback() {
 	tr.conn.Rollback()
@@ -114,6 +130,23 @@ func (tr *DbTransaction) Commit() error {
 	return tr.conn.Commit().Error
 }
 
+// OpenDB opens the connection to other database, e.g. of the reference node. The result can be passed
+// to the functions of the model instead of the transaction
+func OpenDB(cfg conf.DBConfig) (*DbTransaction, error) {
+	conn, err := gorm.Open("postgres",
+		fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=disable password=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Password))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "db_name": cfg.Name}).Error("cant open connection to DB")
+		return nil, err
+	}
+	return &DbTransaction{conn: conn}, nil
+}
+
+// Close closes the connection which has been opened by OpenDB
+func (tr *DbTransaction) Close() error {
+	return tr.conn.Close()
+}
+
 // GetDB is returning gorm.DB
 func GetDB(tr *DbTransaction) *gorm.DB {
 	if tr != nil && tr.conn != nil {
@@ -192,6 +225,58 @@ func GetColumnCount(tableName string) (int64, error) {
 	return count, nil
 }
 
+// GetTableColumns returns the data types of the columns of the table
+func GetTableColumns(tableName string) (map[string]string, error) {
+	rows, err := DBConn.Raw(`SELECT column_name, data_type FROM information_schema.columns WHERE table_name=?`,
+		tableName).Rows()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting columns of table")
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err = rows.Scan(&name, &dataType); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("scanning columns of table")
+			return nil, err
+		}
+		columns[name] = dataType
+	}
+	return columns, rows.Err()
+}
+
+// EstimateCount returns the estimated number of rows of the table from the statistics
+func EstimateCount(tableName string) (int64, error) {
+	var count float64
+	err := DBConn.Raw(`SELECT reltuples FROM pg_class WHERE relname=?`, tableName).Row().Scan(&count)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting estimated count")
+		return 0, err
+	}
+	return int64(count), nil
+}
+
+// EstimateQueryCount returns the number of rows of the query which is estimated by the planner
+func EstimateQueryCount(query string, args ...interface{}) (int64, error) {
+	var plan string
+	err := DBConn.Raw(`EXPLAIN (FORMAT JSON) `+query, args...).Row().Scan(&plan)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("explaining query")
+		return 0, err
+	}
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err = json.Unmarshal([]byte(plan), &explain); err != nil || len(explain) == 0 {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling query plan")
+		return 0, fmt.Errorf(`wrong query plan`)
+	}
+	return int64(explain[0].Plan.Rows), nil
+}
+
 // SendTx is creates transaction
 func SendTx(txType int64, adminWallet int64, data []byte) ([]byte, error) {
 	hash, err := crypto.Hash(data)
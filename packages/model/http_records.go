@@ -0,0 +1,65 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package model
+
+// HTTPRecord is the answer of outbound http request of the contract. The answers are recorded
+// with the transaction, so the same answers are returned when the block is played again
+type HTTPRecord struct {
+	ID     int64  `gorm:"primary_key;not null" json:"-"`
+	TxHash []byte `gorm:"not null" json:"-"`
+	Method string `gorm:"not null;size:16" json:"method"`
+	URL    string `gorm:"not null" json:"url"`
+	Hash   string `gorm:"not null;size:64" json:"hash"`
+	Status int    `gorm:"not null" json:"status"`
+	Body   string `gorm:"not null" json:"body"`
+}
+
+// TableName returns name of table
+func (HTTPRecord) TableName() string {
+	return "http_records"
+}
+
+// GetHTTPRecords returns the recorded answers of the transaction in the order of requests
+func GetHTTPRecords(transaction *DbTransaction, txHash []byte) ([]HTTPRecord, error) {
+	var list []HTTPRecord
+	err := GetDB(transaction).Where("tx_hash = ?", txHash).Order("id").Find(&list).Error
+	return list, err
+}
+
+// SaveHTTPRecords replaces the recorded answers of the transaction with records.
+// They aren't removed by the rollback of the block, so the transaction gets the same answers
+// in the next block
+func SaveHTTPRecords(transaction *DbTransaction, txHash []byte, records []HTTPRecord) error {
+	db := GetDB(transaction)
+	if err := db.Exec("DELETE FROM http_records WHERE tx_hash = ?", txHash).Error; err != nil {
+		return err
+	}
+	for _, item := range records {
+		item.ID = 0
+		item.TxHash = txHash
+		if err := db.Create(&item).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
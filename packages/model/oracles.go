@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package model
+
+// Oracle is model
+type Oracle struct {
+	ID     int64  `gorm:"primary_key;not null"`
+	KeyID  int64  `gorm:"not null"`
+	Feeds  string `gorm:"not null"`
+	Active string `gorm:"not null;size:1"`
+}
+
+// TableName returns name of table
+func (Oracle) TableName() string {
+	return "oracles"
+}
+
+// Get is retrieving model from database
+func (o *Oracle) Get(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("id = ?", id).First(o))
+}
+
+// GetByKey is retrieving the oracle by its key
+func (o *Oracle) GetByKey(transaction *DbTransaction, keyID int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("key_id = ?", keyID).First(o))
+}
+
+// OracleValue is model
+type OracleValue struct {
+	ID      int64  `gorm:"primary_key;not null"`
+	Feed    string `gorm:"not null;size:255"`
+	KeyID   int64  `gorm:"not null"`
+	Value   string `gorm:"not null"`
+	Time    int64  `gorm:"not null"`
+	BlockID int64  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (OracleValue) TableName() string {
+	return "oracle_values"
+}
+
+// GetLastOracleValues returns the latest value of the feed from every active oracle
+// which has been submitted within the time interval
+func GetLastOracleValues(transaction *DbTransaction, feed string, from, to int64) ([]OracleValue, error) {
+	var values []OracleValue
+	err := GetDB(transaction).Raw(`SELECT DISTINCT ON (v.key_id) v.* FROM "oracle_values" v
+		INNER JOIN "oracles" o ON o.key_id = v.key_id AND o.active = '1'
+		WHERE v.feed = ? AND v.time >= ? AND v.time <= ?
+		ORDER BY v.key_id, v.time DESC, v.id DESC`, feed, from, to).Scan(&values).Error
+	return values, err
+}
@@ -0,0 +1,59 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package model
+
+// PruneInfo is the state of pruning of old blocks
+type PruneInfo struct {
+	// RollbackBlockID is the last block whose rollback records have been deleted
+	RollbackBlockID int64 `gorm:"not null"`
+	// ArchiveBlockID is the last block whose body has been moved to the archive
+	ArchiveBlockID int64 `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (PruneInfo) TableName() string {
+	return "prune_info"
+}
+
+// Get is retrieving model from database
+func (pi *PruneInfo) Get() (bool, error) {
+	return isFound(DBConn.First(pi))
+}
+
+// SetRollbackBlockID updates the last block whose rollback records have been deleted
+func (pi *PruneInfo) SetRollbackBlockID(transaction *DbTransaction, blockID int64) error {
+	pi.RollbackBlockID = blockID
+	return GetDB(transaction).Exec(`UPDATE prune_info SET rollback_block_id = ?`, blockID).Error
+}
+
+// SetArchiveBlockID updates the last block whose body has been moved to the archive
+func (pi *PruneInfo) SetArchiveBlockID(transaction *DbTransaction, blockID int64) error {
+	pi.ArchiveBlockID = blockID
+	return GetDB(transaction).Exec(`UPDATE prune_info SET archive_block_id = ?`, blockID).Error
+}
+
+// PruneRollbackTxs deletes the rollback records of the blocks from the range
+func PruneRollbackTxs(transaction *DbTransaction, fromBlockID, toBlockID int64) (int64, error) {
+	query := GetDB(transaction).Exec(`DELETE FROM rollback_tx WHERE block_id >= ? AND block_id <= ?`, fromBlockID, toBlockID)
+	return query.RowsAffected, query.Error
+}
@@ -0,0 +1,64 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package querycost
+
+import (
+	"strings"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/metrics"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+)
+
+var (
+	queryCostDuration = metrics.NewHistogram(`genesis_db_query_cost_duration_seconds`,
+		`Duration of the database queries which estimate the cost of contract queries`, nil, `query`)
+	queryCostValue = metrics.NewHistogram(`genesis_db_query_cost`,
+		`Estimated cost of contract queries`, []float64{1, 2, 5, 10, 25, 50, 100, 500, 1000}, `query`)
+)
+
+// measuredQueryCoster records the timings and the costs of the wrapped coster
+type measuredQueryCoster struct {
+	QueryCoster
+}
+
+func (m measuredQueryCoster) QueryCost(transaction *model.DbTransaction, query string, args ...interface{}) (int64, error) {
+	start := time.Now()
+	cost, err := m.QueryCoster.QueryCost(transaction, query, args...)
+	kind := queryKind(query)
+	queryCostDuration.With(kind).Since(start)
+	if err == nil {
+		queryCostValue.With(kind).Observe(float64(cost))
+	}
+	return cost, err
+}
+
+func queryKind(query string) string {
+	cleanedQuery := strings.TrimSpace(strings.ToLower(query))
+	for _, kind := range []string{Select, Insert, Update, Delete} {
+		if strings.HasPrefix(cleanedQuery, kind) {
+			return kind
+		}
+	}
+	return `other`
+}
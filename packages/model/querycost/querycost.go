@@ -55,11 +55,11 @@ func (*ExplainAnalyzeQueryCoster) QueryCost(transaction *model.DbTransaction, qu
 func GetQueryCoster(tp QueryCosterType) QueryCoster {
 	switch tp {
 	case ExplainQueryCosterType:
-		return &ExplainQueryCoster{}
+		return measuredQueryCoster{&ExplainQueryCoster{}}
 	case ExplainAnalyzeQueryCosterType:
-		return &ExplainAnalyzeQueryCoster{}
+		return measuredQueryCoster{&ExplainAnalyzeQueryCoster{}}
 	case FormulaQueryCosterType:
-		return &FormulaQueryCoster{&DBCountQueryRowCounter{}}
+		return measuredQueryCoster{&FormulaQueryCoster{&DBCountQueryRowCounter{}}}
 	}
 	return nil
 }
@@ -67,6 +67,13 @@ func GetQueuedTransactionsCount(hash []byte) (int64, error) {
 	return rowsCount, err
 }
 
+// GetQueueTxCount counting all queued transactions
+func GetQueueTxCount() (int64, error) {
+	var rowsCount int64
+	err := DBConn.Table("queue_tx").Count(&rowsCount).Error
+	return rowsCount, err
+}
+
 // GetAllUnverifiedAndUnusedTransactions is returns all unverified and unused transaction
 func GetAllUnverifiedAndUnusedTransactions() ([]*QueueTx, error) {
 	query := `SELECT *
@@ -211,12 +211,12 @@ func GetAllTransaction(transaction *DbTransaction, query string, countRows int,
 
 // GetAll returns all transaction
 func GetAll(query string, countRows int, args ...interface{}) ([]map[string]string, error) {
-	return GetAllTransaction(nil, query, countRows, args)
+	return GetAllTransaction(nil, query, countRows, args...)
 }
 
 // GetAllTx returns all tx's
 func GetAllTx(transaction *DbTransaction, query string, countRows int, args ...interface{}) ([]map[string]string, error) {
-	return GetAllTransaction(transaction, query, countRows, args)
+	return GetAllTransaction(transaction, query, countRows, args...)
 }
 
 // GetOneRowTransaction returns one row from transactions
@@ -22,6 +22,10 @@
 
 package model
 
+import (
+	"encoding/json"
+)
+
 // RollbackTx is model
 type RollbackTx struct {
 	ID        int64  `gorm:"primary_key;not null" json:"-"`
@@ -48,6 +52,13 @@ func (rt *RollbackTx) GetBlockRollbackTransactions(dbTransaction *DbTransaction,
 	return rollbackTransactions, err
 }
 
+// GetBlockRollbackTxsInOrder returns the rollback records of the block in the order of the changes
+func GetBlockRollbackTxsInOrder(transaction *DbTransaction, blockID int64) ([]RollbackTx, error) {
+	var list []RollbackTx
+	err := GetDB(transaction).Where("block_id = ?", blockID).Order("id asc").Find(&list).Error
+	return list, err
+}
+
 func (rt *RollbackTx) GetRollbackTxsByTableIDAndTableName(tableID, tableName string, limit int) (*[]RollbackTx, error) {
 	rollbackTx := new([]RollbackTx)
 	if err := DBConn.Where("table_id = ? AND table_name = ?", tableID, tableName).Limit(limit).Find(rollbackTx).Error; err != nil {
@@ -75,3 +86,93 @@ func (rt *RollbackTx) Create(transaction *DbTransaction) error {
 func (rt *RollbackTx) Get(dbTransaction *DbTransaction, transactionHash []byte, tableName string) (bool, error) {
 	return isFound(GetDB(dbTransaction).Where("tx_hash = ? AND table_name = ?", transactionHash, tableName).First(rt))
 }
+
+// GetRollbackTxsAfterBlock returns the rollback records of the rows of the table which have been changed
+// after the block, from the newest to the oldest. If ids is empty then the records of all rows are returned
+func GetRollbackTxsAfterBlock(transaction *DbTransaction, table string, ids []string, blockID int64) ([]RollbackTx, error) {
+	var list []RollbackTx
+	query := GetDB(transaction).Where("table_name = ? AND block_id > ?", table, blockID)
+	if len(ids) > 0 {
+		query = query.Where("table_id IN (?)", ids)
+	}
+	err := query.Order("block_id desc, id desc").Find(&list).Error
+	return list, err
+}
+
+// NotInsertedAfterBlock returns the condition which excludes the rows of the table inserted after the block
+func NotInsertedAfterBlock(table string, blockID int64) (string, []interface{}) {
+	return `id NOT IN (SELECT table_id::bigint FROM rollback_tx WHERE table_name = ? AND block_id > ? AND data = '')`,
+		[]interface{}{table, blockID}
+}
+
+// RestoreRow applies the rollback records in the order from the newest to the oldest to the current row.
+// It returns nil if the row has been inserted by one of these records. Only the columns of the row are restored
+func RestoreRow(row map[string]string, rollbacks []RollbackTx) (map[string]string, error) {
+	if len(row) == 0 {
+		return nil, nil
+	}
+	state := make(map[string]string, len(row))
+	for key, val := range row {
+		state[key] = val
+	}
+	for _, rtx := range rollbacks {
+		if len(rtx.Data) == 0 {
+			return nil, nil
+		}
+		var prev map[string]string
+		if err := json.Unmarshal([]byte(rtx.Data), &prev); err != nil {
+			return nil, err
+		}
+		for key, val := range prev {
+			if _, ok := state[key]; ok {
+				state[key] = val
+			}
+		}
+	}
+	return state, nil
+}
+
+// RestoreRows reconstructs the rows of the table as of the block. The rows which did not exist yet are
+// removed from the list. The rows must be selected in the same transaction
+func RestoreRows(transaction *DbTransaction, table string, rows []map[string]string, blockID int64) ([]map[string]string, error) {
+	if len(rows) == 0 {
+		return rows, nil
+	}
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row[`id`])
+	}
+	rollbacks, err := GetRollbackTxsAfterBlock(transaction, table, ids, blockID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string][]RollbackTx)
+	for _, rtx := range rollbacks {
+		byID[rtx.TableID] = append(byID[rtx.TableID], rtx)
+	}
+	result := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		state, err := RestoreRow(row, byID[row[`id`]])
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			result = append(result, state)
+		}
+	}
+	return result, nil
+}
+
+// GetRowAsOf returns the columns of the row as they were after the block has been applied.
+// It returns nil if the row did not exist at that block
+func GetRowAsOf(transaction *DbTransaction, table, columns, id string, blockID int64) (map[string]string, error) {
+	row, err := GetOneRowTransaction(transaction, `SELECT `+columns+` FROM "`+table+`" WHERE id = ?`, id).String()
+	if err != nil || len(row) == 0 {
+		return nil, err
+	}
+	rollbacks, err := GetRollbackTxsAfterBlock(transaction, table, []string{id}, blockID)
+	if err != nil {
+		return nil, err
+	}
+	return RestoreRow(row, rollbacks)
+}
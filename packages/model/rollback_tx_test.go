@@ -0,0 +1,51 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRestoreRow(t *testing.T) {
+	row := map[string]string{`id`: `1`, `amount`: `30`, `name`: `c`}
+	state, err := RestoreRow(row, []RollbackTx{
+		{Data: `{"amount":"20","name":"b"}`},
+		{Data: `{"amount":"10","key":"skip"}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(state, map[string]string{`id`: `1`, `amount`: `10`, `name`: `b`}) {
+		t.Errorf(`wrong state %v`, state)
+	}
+	if row[`amount`] != `30` {
+		t.Error(`the current row must not be changed`)
+	}
+	if state, err = RestoreRow(row, []RollbackTx{{Data: `{"amount":"20"}`}, {}}); err != nil || state != nil {
+		t.Errorf(`the inserted row must be removed %v %v`, state, err)
+	}
+	if _, err = RestoreRow(row, []RollbackTx{{Data: `{`}}); err == nil {
+		t.Error(`wrong data must be rejected`)
+	}
+}
@@ -71,6 +71,15 @@ func GetTransactionCountAll() (int64, error) {
 	return rowsCount, nil
 }
 
+// GetUnusedTransactionsCount count transactions which have not been included in blocks
+func GetUnusedTransactionsCount() (int64, error) {
+	var rowsCount int64
+	if err := DBConn.Table("transactions").Where("used = ?", "0").Count(&rowsCount).Error; err != nil {
+		return -1, err
+	}
+	return rowsCount, nil
+}
+
 // GetTransactionsCount count all transactions by hash
 func GetTransactionsCount(hash []byte) (int64, error) {
 	var rowsCount int64
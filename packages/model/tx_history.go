@@ -0,0 +1,105 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package model
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// TxHistory is the index of the transaction which has been applied in the block
+type TxHistory struct {
+	ID        int64           `gorm:"primary_key;not null"`
+	Hash      []byte          `gorm:"not null"`
+	BlockID   int64           `gorm:"not null"`
+	Time      int64           `gorm:"not null"`
+	KeyID     int64           `gorm:"not null"`
+	Ecosystem int64           `gorm:"not null"`
+	Contract  string          `gorm:"not null;size:255"`
+	Params    string          `gorm:"not null"`
+	Result    string          `gorm:"not null"`
+	Error     string          `gorm:"not null"`
+	Fuel      decimal.Decimal `gorm:"not null;type:decimal(30)"`
+}
+
+// TxHistoryFilter is the filter of the transaction history, zero values are not used
+type TxHistoryFilter struct {
+	KeyID     int64
+	Ecosystem int64
+	Contract  string
+	// Before is the id of the last record of the previous page
+	Before int64
+}
+
+// TableName returns name of table
+func (TxHistory) TableName() string {
+	return "tx_history"
+}
+
+// Create is creating record of model. The record is inserted inside the savepoint,
+// so the failed insert doesn't abort the transaction of the block
+func (th *TxHistory) Create(transaction *DbTransaction) error {
+	if transaction == nil {
+		return DBConn.Create(th).Error
+	}
+	db := GetDB(transaction)
+	if err := db.Exec(`SAVEPOINT tx_history`).Error; err != nil {
+		return err
+	}
+	if err := db.Create(th).Error; err != nil {
+		db.Exec(`ROLLBACK TO SAVEPOINT tx_history`)
+		return err
+	}
+	return db.Exec(`RELEASE SAVEPOINT tx_history`).Error
+}
+
+// GetByHash returns the last record of the transaction. The failed transaction can be applied later
+// in other block, so the hash can have several records
+func (th *TxHistory) GetByHash(hash []byte) (bool, error) {
+	return isFound(DBConn.Where("hash = ?", hash).Last(th))
+}
+
+// DeleteTxHistoryByHash is deleting the record of the transaction in the block
+func DeleteTxHistoryByHash(transaction *DbTransaction, hash []byte, blockID int64) (int64, error) {
+	query := GetDB(transaction).Exec("DELETE FROM tx_history WHERE hash = ? AND block_id = ?", hash, blockID)
+	return query.RowsAffected, query.Error
+}
+
+// GetTxHistory returns the records of the filter from the newest to the oldest
+func GetTxHistory(filter TxHistoryFilter, limit int) ([]TxHistory, error) {
+	var list []TxHistory
+	query := DBConn.Model(&TxHistory{})
+	if filter.KeyID != 0 {
+		query = query.Where("key_id = ?", filter.KeyID)
+	}
+	if filter.Ecosystem > 0 {
+		query = query.Where("ecosystem = ?", filter.Ecosystem)
+	}
+	if len(filter.Contract) > 0 {
+		query = query.Where("contract = ?", filter.Contract)
+	}
+	if filter.Before > 0 {
+		query = query.Where("id < ?", filter.Before)
+	}
+	err := query.Order("id desc").Limit(limit).Find(&list).Error
+	return list, err
+}
@@ -24,6 +24,7 @@ package parser
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -142,6 +143,8 @@ func GetParser(p *Parser, txType string) (ParserInterface, error) {
 	switch txType {
 	case "FirstBlock":
 		return &FirstBlockParser{p}, nil
+	case "FirstBlockNodes":
+		return &FirstBlockNodesParser{p}, nil
 	}
 	log.WithFields(log.Fields{"tx_type": txType, "type": consts.UnknownObject}).Error("unknown txType")
 	return nil, fmt.Errorf("Unknown txType: %s", txType)
@@ -171,6 +174,7 @@ type Parser struct {
 	TxType           int64
 	TxCost           int64           // Maximum cost of executing contract
 	TxUsedCost       decimal.Decimal // Used cost of CPU resources
+	TxFuel           decimal.Decimal // Fuel paid for the transaction
 	TxPtr            interface{}     // Pointer to the corresponding struct in consts/struct.go
 	TxData           map[string]interface{}
 	TxSmart          *tx.SmartContract
@@ -185,6 +189,10 @@ type Parser struct {
 
 // GetLogger returns logger
 func (p Parser) GetLogger() *log.Entry {
+	return p.getLogger().WithFields(log.Fields{"tx_hash": hex.EncodeToString(p.TxHash)})
+}
+
+func (p Parser) getLogger() *log.Entry {
 	if p.BlockData != nil && p.PrevBlock != nil {
 		logger := log.WithFields(log.Fields{"block_id": p.BlockData.BlockID, "block_time": p.BlockData.Time, "block_wallet_id": p.BlockData.KeyID, "block_state_id": p.BlockData.EcosystemID, "block_hash": p.BlockData.Hash, "block_version": p.BlockData.Version, "prev_block_id": p.PrevBlock.BlockID, "prev_block_time": p.PrevBlock.Time, "prev_block_wallet_id": p.PrevBlock.KeyID, "prev_block_state_id": p.PrevBlock.EcosystemID, "prev_block_hash": p.PrevBlock.Hash, "prev_block_version": p.PrevBlock.Version, "tx_type": p.TxType, "tx_time": p.TxTime, "tx_state_id": p.TxEcosystemID, "tx_wallet_id": p.TxKeyID})
 		return logger
@@ -506,7 +514,22 @@ func (p *Parser) CallContract(flags int) (resultContract string, err error) {
 		PublicKeys:    p.PublicKeys,
 		DbTransaction: p.DbTransaction,
 	}
+	if flags&smart.CallAction != 0 {
+		// the recorded answers of http requests are returned if the transaction is played again
+		if sc.HTTPRecords, err = model.GetHTTPRecords(p.DbTransaction, p.TxHash); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "tx_hash": p.TxHash}).Error("getting http records")
+			return ``, err
+		}
+		sc.HTTPReplay = len(sc.HTTPRecords) > 0
+	}
 	resultContract, err = sc.CallContract(flags)
 	p.SysUpdate = sc.SysUpdate
+	p.TxFuel = sc.TxFuel
+	if !sc.HTTPReplay && len(sc.HTTPRecords) > 0 {
+		if errRecord := model.SaveHTTPRecords(p.DbTransaction, p.TxHash, sc.HTTPRecords); errRecord != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": errRecord, "tx_hash": p.TxHash}).Error("saving http records")
+			return ``, errRecord
+		}
+	}
 	return
 }
@@ -24,12 +24,10 @@ package parser
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
-	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 
@@ -86,11 +84,7 @@ func GetBlocks(blockID int64, host string) error {
 		}
 
 		// SIGN from 128 bytes to 512 bytes. Signature of TYPE, BLOCK_ID, PREV_BLOCK_HASH, TIME, WALLET_ID, state_id, MRKL_ROOT
-		forSign := fmt.Sprintf("0,%v,%x,%v,%v,%v,%v,%s",
-			block.Header.BlockID, block.PrevHeader.Hash, block.Header.Time,
-			block.Header.EcosystemID, block.Header.KeyID, block.Header.NodePosition,
-			block.MrklRoot,
-		)
+		forSign := blockForSign(&block.Header, block.PrevHeader.Hash, block.MrklRoot)
 
 		// save the block
 		blocks = append(blocks, block)
@@ -150,8 +144,7 @@ func GetBlocks(blockID int64, host string) error {
 			block.PrevHeader.NodePosition = prevBlocks[block.Header.BlockID-1].Header.NodePosition
 		}
 
-		forSha := fmt.Sprintf("%d,%x,%s,%d,%d,%d,%d", block.Header.BlockID, block.PrevHeader.Hash, block.MrklRoot, block.Header.Time, block.Header.EcosystemID, block.Header.KeyID, block.Header.NodePosition)
-		hash, err := crypto.DoubleHash([]byte(forSha))
+		hash, err := blockHash(&block.Header, block.PrevHeader.Hash, block.MrklRoot)
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Fatal("double hashing block")
 		}
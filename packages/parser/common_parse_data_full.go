@@ -25,14 +25,15 @@ package parser
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/faults"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/script"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
@@ -233,6 +234,11 @@ func parseBlock(blockBuffer *bytes.Buffer) (*Block, error) {
 
 // ParseBlockHeader is parses block header
 func ParseBlockHeader(binaryBlock *bytes.Buffer) (utils.BlockData, error) {
+	return parseBlockHeader(binaryBlock, true)
+}
+
+// parseBlockHeader parses the block header, the size of block is checked by max_block_size if checkSize is true
+func parseBlockHeader(binaryBlock *bytes.Buffer, checkSize bool) (utils.BlockData, error) {
 	var block utils.BlockData
 	var err error
 
@@ -243,7 +249,7 @@ func ParseBlockHeader(binaryBlock *bytes.Buffer) (utils.BlockData, error) {
 
 	blockVersion := int(converter.BinToDec(binaryBlock.Next(2)))
 
-	if int64(binaryBlock.Len()) > syspar.GetMaxBlockSize() {
+	if checkSize && int64(binaryBlock.Len()) > syspar.GetMaxBlockSize() {
 		log.WithFields(log.Fields{"size": binaryBlock.Len(), "max_size": syspar.GetMaxBlockSize(), "type": consts.ParameterExceeded}).Error("binary block size exceeds max block size")
 		err = fmt.Errorf(`len(binaryBlock) > variables.Int64["max_block_size"]  %v > %v`,
 			binaryBlock.Len(), syspar.GetMaxBlockSize())
@@ -544,7 +550,7 @@ func CheckTransaction(data []byte) (*tx.Header, error) {
 		return nil, err
 	}
 
-	err = checkTransaction(p, time.Now().Unix(), true)
+	err = checkTransaction(p, faults.Now().Unix(), true)
 	if err != nil {
 		return nil, err
 	}
@@ -603,6 +609,7 @@ func (b *Block) playBlock(dbTransaction *model.DbTransaction) error {
 			// skip this transaction
 			model.MarkTransactionUsed(nil, p.TxHash)
 			p.processBadTransaction(p.TxHash, err.Error())
+			b.indexTransaction(p, ``, err.Error())
 			if p.SysUpdate {
 				if err = syspar.SysUpdate(p.DbTransaction); err != nil {
 					log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating syspar")
@@ -630,15 +637,56 @@ func (b *Block) playBlock(dbTransaction *model.DbTransaction) error {
 		if err := InsertInLogTx(p.DbTransaction, p.TxFullData, p.TxTime); err != nil {
 			return utils.ErrInfo(err)
 		}
+		b.indexTransaction(p, msg, ``)
 	}
 	return nil
 }
 
+// indexTransaction saves the transaction to the history of accounts and contracts.
+// The history is the local index of the node, so its errors are logged and don't reject the block
+func (b *Block) indexTransaction(p *Parser, result, txError string) {
+	th := &model.TxHistory{
+		Hash:      p.TxHash,
+		BlockID:   b.Header.BlockID,
+		Time:      p.TxTime,
+		KeyID:     p.TxKeyID,
+		Ecosystem: p.TxEcosystemID,
+		Result:    result,
+		Error:     txError,
+		Fuel:      p.TxFuel,
+	}
+	var params interface{}
+	if p.TxContract != nil {
+		th.Contract = p.TxContract.Name
+		data := make(map[string]interface{})
+		for key, val := range p.TxData {
+			if key != `forsign` {
+				data[key] = val
+			}
+		}
+		params = data
+	} else {
+		th.Contract = consts.TxTypes[int(p.TxType)]
+		params = p.TxPtr
+	}
+	if params != nil {
+		out, err := json.Marshal(params)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling params of transaction")
+		} else {
+			th.Params = string(out)
+		}
+	}
+	if err := th.Create(p.DbTransaction); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "tx_hash": p.TxHash}).Error("indexing transaction")
+	}
+}
+
 // CheckBlock is checking block
 func (b *Block) CheckBlock() error {
 	logger := b.GetLogger()
 	// exclude blocks from future
-	if b.Header.Time > time.Now().Unix() {
+	if b.Header.Time > faults.Now().Unix() {
 		logger.WithFields(log.Fields{"type": consts.ParameterExceeded}).Error("block time is larger than now")
 		return utils.ErrInfo(fmt.Errorf("incorrect block time - block.Header.Time > time.Now().Unix()"))
 	}
@@ -722,8 +770,7 @@ func (b *Block) CheckHash() (bool, error) {
 			return false, utils.ErrInfo(fmt.Errorf("empty nodePublicKey"))
 		}
 		// check the signature
-		forSign := fmt.Sprintf("0,%d,%x,%d,%d,%d,%d,%s", b.Header.BlockID, b.PrevHeader.Hash,
-			b.Header.Time, b.Header.EcosystemID, b.Header.KeyID, b.Header.NodePosition, b.MrklRoot)
+		forSign := blockForSign(&b.Header, b.PrevHeader.Hash, b.MrklRoot)
 
 		resultCheckSign, err := utils.CheckSign([][]byte{nodePublicKey}, forSign, b.Header.Sign, true)
 		if err != nil {
@@ -737,6 +784,19 @@ func (b *Block) CheckHash() (bool, error) {
 	return true, nil
 }
 
+// blockForSign returns the data of the block header which is signed by the node
+func blockForSign(header *utils.BlockData, prevHash, mrklRoot []byte) string {
+	return fmt.Sprintf("0,%d,%x,%d,%d,%d,%d,%s", header.BlockID, prevHash,
+		header.Time, header.EcosystemID, header.KeyID, header.NodePosition, mrklRoot)
+}
+
+// blockHash returns the hash of the block header
+func blockHash(header *utils.BlockData, prevHash, mrklRoot []byte) ([]byte, error) {
+	forSha := fmt.Sprintf("%d,%x,%s,%d,%d,%d,%d", header.BlockID, prevHash, mrklRoot,
+		header.Time, header.EcosystemID, header.KeyID, header.NodePosition)
+	return crypto.DoubleHash([]byte(forSha))
+}
+
 // MarshallBlock is marshalling block
 func MarshallBlock(header *utils.BlockData, trData [][]byte, prevHash []byte, key string) ([]byte, error) {
 	var mrklArray [][]byte
@@ -760,11 +820,8 @@ func MarshallBlock(header *utils.BlockData, trData [][]byte, prevHash []byte, ke
 		}
 		mrklRoot := utils.MerkleTreeRoot(mrklArray)
 
-		forSign := fmt.Sprintf("0,%d,%x,%d,%d,%d,%d,%s",
-			header.BlockID, prevHash, header.Time, header.EcosystemID, header.KeyID, header.NodePosition, mrklRoot)
-
 		var err error
-		signed, err = crypto.Sign(key, forSign)
+		signed, err = crypto.Sign(key, blockForSign(header, prevHash, mrklRoot))
 		if err != nil {
 			logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing blocko")
 			return nil, err
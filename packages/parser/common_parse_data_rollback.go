@@ -119,6 +119,11 @@ func doBlockRollback(transaction *model.DbTransaction, block *Block) error {
 			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting log transactions by hash")
 			return utils.ErrInfo(err)
 		}
+		_, err = model.DeleteTxHistoryByHash(transaction, p.TxHash, block.Header.BlockID)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting transaction history by hash")
+			return utils.ErrInfo(err)
+		}
 
 		ts := &model.TransactionStatus{}
 		err = ts.UpdateBlockID(transaction, 0, p.TxHash)
@@ -24,6 +24,7 @@ package parser
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
@@ -35,6 +36,15 @@ import (
 // RollbackToBlockID rollbacks blocks till blockID
 func (p *Parser) RollbackToBlockID(blockID int64) error {
 	logger := p.GetLogger()
+	info := &model.PruneInfo{}
+	if _, err := info.Get(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting prune info")
+		return p.ErrInfo(err)
+	}
+	if blockID < info.RollbackBlockID {
+		logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "block_id": blockID, "pruned_block_id": info.RollbackBlockID}).Error("rollback records have been pruned")
+		return p.ErrInfo(fmt.Errorf(`rollback records of the blocks up to %d have been pruned`, info.RollbackBlockID))
+	}
 	_, err := model.MarkVerifiedAndNotUsedTransactionsUnverified()
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("marking verified and not used transactions unverified")
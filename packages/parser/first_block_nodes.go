@@ -0,0 +1,94 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FirstBlockNodesParser sets the full nodes of the network which starts with several nodes
+type FirstBlockNodesParser struct {
+	*Parser
+}
+
+// ErrNotFirstBlock is returned if the transaction of the first block is in other block
+var ErrNotFirstBlock = errors.New("transaction is allowed only in the first block")
+
+// Init first block nodes
+func (p *FirstBlockNodesParser) Init() error {
+	return nil
+}
+
+// Validate first block nodes
+func (p *FirstBlockNodesParser) Validate() error {
+	return nil
+}
+
+// Action replaces the node of FirstBlock transaction with the list of nodes
+func (p *FirstBlockNodesParser) Action() error {
+	logger := p.GetLogger()
+	if p.BlockData == nil || p.BlockData.BlockID != 1 {
+		return p.ErrInfo(ErrNotFirstBlock)
+	}
+	data := p.TxPtr.(*consts.FirstBlockNodes)
+	if _, err := NodeKeysFromJSON(data.Nodes); err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("parsing full nodes")
+		return p.ErrInfo(err)
+	}
+	var nodes [][]string
+	if err := json.Unmarshal([]byte(data.Nodes), &nodes); err != nil {
+		return p.ErrInfo(err)
+	}
+	if len(nodes) == 0 {
+		return p.ErrInfo(fmt.Errorf("list of full nodes is empty"))
+	}
+	node := &model.SystemParameter{Name: `full_nodes`}
+	if err := node.SaveArray(nodes); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving node array")
+		return p.ErrInfo(err)
+	}
+	if err := syspar.SysUpdate(nil); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating syspar")
+		return p.ErrInfo(err)
+	}
+	return nil
+}
+
+// Rollback first block nodes
+func (p *FirstBlockNodesParser) Rollback() error {
+	return nil
+}
+
+// Header is returns first block nodes header
+func (p FirstBlockNodesParser) Header() *tx.Header {
+	return nil
+}
@@ -0,0 +1,239 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/GenesisKernel/go-genesis/packages/model"
+)
+
+// RowWriter is the transaction which has changed the row in the block
+type RowWriter struct {
+	TxHash   []byte
+	Contract string
+}
+
+// Divergence is the row whose state after the replayed block differs from the reference node
+type Divergence struct {
+	BlockID int64
+	Table   string
+	ID      string
+	// Local and Reference are nil if the row doesn't exist
+	Local     map[string]string
+	Reference map[string]string
+	// Columns are the names of the different columns
+	Columns    []string
+	Writers    []RowWriter
+	RefWriters []RowWriter
+}
+
+// Replayer re-executes the blocks on the local database and compares the changed rows
+// with the database of the reference node
+type Replayer struct {
+	Ref *model.DbTransaction
+}
+
+type rowKey struct {
+	table string
+	id    string
+}
+
+// ReplayBlock plays the block on the local database and returns the first divergent row of the block
+// or nil if the changed rows are equal to the rows of the reference node
+func (r *Replayer) ReplayBlock(data []byte) (*Divergence, error) {
+	block, err := ProcessBlockWherePrevFromBlockchainTable(data)
+	if err != nil {
+		return nil, err
+	}
+	if err = block.CheckBlock(); err != nil {
+		return nil, err
+	}
+	if err = r.copyHTTPRecords(block); err != nil {
+		return nil, err
+	}
+	if err = block.PlayBlockSafe(); err != nil {
+		return nil, err
+	}
+	return r.compareBlock(block.Header.BlockID)
+}
+
+// copyHTTPRecords copies the recorded answers of http requests of the block transactions
+// from the reference node, so the contracts get the same answers as on the reference node
+func (r *Replayer) copyHTTPRecords(block *Block) error {
+	for _, p := range block.Parsers {
+		records, err := model.GetHTTPRecords(r.Ref, p.TxHash)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			continue
+		}
+		if err = model.SaveHTTPRecords(nil, p.TxHash, records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareBlock compares the rows which have been changed by the block either on the local node
+// or on the reference node. The rows of the reference node are restored as of the block by rollback_tx
+func (r *Replayer) compareBlock(blockID int64) (*Divergence, error) {
+	local, err := model.GetBlockRollbackTxsInOrder(nil, blockID)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := model.GetBlockRollbackTxsInOrder(r.Ref, blockID)
+	if err != nil {
+		return nil, err
+	}
+	var keys []rowKey
+	writers := make(map[rowKey][]RowWriter)
+	refWriters := make(map[rowKey][]RowWriter)
+	addWriters := func(list []model.RollbackTx, result map[rowKey][]RowWriter) {
+		for _, rtx := range list {
+			key := rowKey{table: rtx.NameTable, id: rtx.TableID}
+			_, isLocal := writers[key]
+			_, isRef := refWriters[key]
+			if !isLocal && !isRef {
+				keys = append(keys, key)
+			}
+			prev := result[key]
+			if len(prev) == 0 || string(prev[len(prev)-1].TxHash) != string(rtx.TxHash) {
+				result[key] = append(prev, RowWriter{TxHash: rtx.TxHash})
+			}
+		}
+	}
+	addWriters(local, writers)
+	addWriters(ref, refWriters)
+
+	for _, key := range keys {
+		localRow, err := model.GetRowAsOf(nil, key.table, `*`, key.id, blockID)
+		if err != nil {
+			return nil, err
+		}
+		refRow, err := model.GetRowAsOf(r.Ref, key.table, `*`, key.id, blockID)
+		if err != nil {
+			return nil, err
+		}
+		columns := diffColumns(localRow, refRow)
+		if len(columns) == 0 {
+			continue
+		}
+		div := &Divergence{
+			BlockID:    blockID,
+			Table:      key.table,
+			ID:         key.id,
+			Local:      localRow,
+			Reference:  refRow,
+			Columns:    columns,
+			Writers:    writers[key],
+			RefWriters: refWriters[key],
+		}
+		if err = setContracts(nil, div.Writers); err != nil {
+			return nil, err
+		}
+		if err = setContracts(r.Ref, div.RefWriters); err != nil {
+			return nil, err
+		}
+		return div, nil
+	}
+	return nil, nil
+}
+
+// diffColumns returns the sorted names of the columns which have the different values.
+// If one of the rows doesn't exist then all columns are different
+func diffColumns(local, ref map[string]string) []string {
+	var columns []string
+	if (local == nil) != (ref == nil) {
+		for _, row := range []map[string]string{local, ref} {
+			for key := range row {
+				columns = append(columns, key)
+			}
+		}
+	} else {
+		for key, val := range local {
+			if refVal, ok := ref[key]; !ok || refVal != val {
+				columns = append(columns, key)
+			}
+		}
+		for key := range ref {
+			if _, ok := local[key]; !ok {
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// setContracts gets the names of the contracts of the transactions from the history of transactions
+func setContracts(transaction *model.DbTransaction, list []RowWriter) error {
+	for i, item := range list {
+		row, err := model.GetOneRowTransaction(transaction, `SELECT contract FROM tx_history WHERE hash = ?`,
+			item.TxHash).String()
+		if err != nil {
+			return err
+		}
+		list[i].Contract = row[`contract`]
+	}
+	return nil
+}
+
+// ReplayStart returns the last block of the local database and checks that the reference node
+// has the rollback records of the next block
+func (r *Replayer) ReplayStart() (int64, error) {
+	last := &model.Block{}
+	found, err := last.GetMaxBlock()
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("blockchain of the local node is empty")
+	}
+	info, err := model.GetOneRowTransaction(r.Ref, `SELECT rollback_block_id FROM prune_info`).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if info[`rollback_block_id`] > last.ID {
+		return 0, fmt.Errorf("rollback records of the reference node have been pruned up to block %d",
+			info[`rollback_block_id`])
+	}
+	return last.ID, nil
+}
+
+// RefBlockData returns the body of the block of the reference node
+func (r *Replayer) RefBlockData(blockID int64) ([]byte, error) {
+	var block model.Block
+	if err := model.GetDB(r.Ref).Where("id = ?", blockID).First(&block).Error; err != nil {
+		if err == model.ErrRecordNotFound {
+			return nil, fmt.Errorf("block %d is not found on the reference node", blockID)
+		}
+		return nil, err
+	}
+	if len(block.Data) == 0 {
+		return nil, fmt.Errorf("block %d of the reference node has been archived", blockID)
+	}
+	return block.Data, nil
+}
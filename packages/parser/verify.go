@@ -0,0 +1,151 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+)
+
+// NodeKeys are the public keys of full nodes by the node position which sign the blocks starting from BlockID
+type NodeKeys struct {
+	BlockID int64
+	Keys    [][]byte
+}
+
+// ChainVerifier checks the consecutive blocks without the database.
+// The value of full_nodes changes over time, so the keys of the nodes are taken from Nodes by the block height.
+// The blocks before the first item of Nodes are rejected because their nodes are unknown
+type ChainVerifier struct {
+	// Nodes are the keys of full nodes in the ascending order of BlockID
+	Nodes []NodeKeys
+	// PrevHash is the hash of the block before the first checked block, it is empty for the first block
+	PrevHash []byte
+
+	prev *utils.BlockData
+}
+
+// NodeKeysFromJSON returns the public keys of nodes by positions from the value of full_nodes parameter
+func NodeKeysFromJSON(value string) ([][]byte, error) {
+	var nodes [][]string
+	if err := json.Unmarshal([]byte(value), &nodes); err != nil {
+		return nil, err
+	}
+	keys := make([][]byte, len(nodes))
+	for i, item := range nodes {
+		if len(item) < 3 {
+			continue
+		}
+		pub, err := hex.DecodeString(item[2])
+		if err != nil {
+			return nil, fmt.Errorf(`public key of node %d: %s`, i, err)
+		}
+		keys[i] = pub
+	}
+	return keys, nil
+}
+
+// nodeKeys returns the keys of the nodes which sign the block
+func (v *ChainVerifier) nodeKeys(blockID int64) [][]byte {
+	for i := len(v.Nodes) - 1; i >= 0; i-- {
+		if v.Nodes[i].BlockID <= blockID {
+			return v.Nodes[i].Keys
+		}
+	}
+	return nil
+}
+
+// blockMrklRoot returns the merkle root of the transactions of the block body
+func blockMrklRoot(buf *bytes.Buffer) ([]byte, error) {
+	var mrklSlice [][]byte
+	for buf.Len() > 0 {
+		size, err := converter.DecodeLengthBuf(buf)
+		if err != nil {
+			return nil, fmt.Errorf("bad block format (%s)", err)
+		}
+		if size == 0 || buf.Len() < int(size) {
+			return nil, fmt.Errorf("bad block format (transaction length %d)", size)
+		}
+		hash, err := crypto.DoubleHash(buf.Next(int(size)))
+		if err != nil {
+			return nil, err
+		}
+		mrklSlice = append(mrklSlice, converter.BinToHex(hash))
+	}
+	if len(mrklSlice) == 0 {
+		mrklSlice = append(mrklSlice, []byte("0"))
+	}
+	return utils.MerkleTreeRoot(mrklSlice), nil
+}
+
+// Verify checks the linkage of the block to the previous one, its hash and the signature of the node.
+// It returns the header of the block with the calculated hash
+func (v *ChainVerifier) Verify(data []byte) (*utils.BlockData, error) {
+	buf := bytes.NewBuffer(data)
+	header, err := parseBlockHeader(buf, false)
+	if err != nil {
+		return nil, err
+	}
+	prevHash := v.PrevHash
+	if v.prev != nil {
+		if header.BlockID != v.prev.BlockID+1 {
+			return nil, fmt.Errorf("incorrect block_id %d != %d +1", header.BlockID, v.prev.BlockID)
+		}
+		prevHash = v.prev.Hash
+	} else if header.BlockID > 1 && len(prevHash) == 0 {
+		return nil, fmt.Errorf("hash of the previous block %d is unknown", header.BlockID-1)
+	}
+	mrklRoot, err := blockMrklRoot(buf)
+	if err != nil {
+		return nil, err
+	}
+	if header.BlockID > 1 {
+		keys := v.nodeKeys(header.BlockID)
+		if keys == nil {
+			return nil, fmt.Errorf("full nodes of block %d are unknown", header.BlockID)
+		}
+		if header.NodePosition < 0 || header.NodePosition >= int64(len(keys)) ||
+			len(keys[header.NodePosition]) == 0 {
+			return nil, fmt.Errorf("unknown public key of node %d", header.NodePosition)
+		}
+		ok, err := utils.CheckSign([][]byte{keys[header.NodePosition]}, blockForSign(&header, prevHash, mrklRoot),
+			header.Sign, true)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("incorrect signature of node %d", header.NodePosition)
+		}
+	}
+	if header.Hash, err = blockHash(&header, prevHash, mrklRoot); err != nil {
+		return nil, err
+	}
+	v.prev = &header
+	return &header, nil
+}
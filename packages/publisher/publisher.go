@@ -26,6 +26,7 @@ import (
 	"encoding/hex"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
@@ -55,17 +56,17 @@ func (cn *ClientsChannels) Get(id int64) string {
 var (
 	clientsChannels   = ClientsChannels{storage: make(map[int64]string)}
 	centrifugoTimeout = time.Second * 5
-	publisher         *gocent.Client
+	publisher         atomic.Value // *gocent.Client
 )
 
 // InitCentrifugo client
 func InitCentrifugo(cfg conf.CentrifugoConfig) {
-	publisher = gocent.NewClient(cfg.URL, cfg.Secret, centrifugoTimeout)
+	publisher.Store(gocent.NewClient(cfg.URL, cfg.Secret, centrifugoTimeout))
 }
 
 // GetHMACSign returns HMACS sign for userID
 func GetHMACSign(userID int64) (string, error) {
-	secret, err := crypto.GetHMAC(conf.Config.Centrifugo.Secret, strconv.FormatInt(userID, 10))
+	secret, err := crypto.GetHMAC(conf.Current().Centrifugo.Secret, strconv.FormatInt(userID, 10))
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("HMAC getting error")
 		return "", err
@@ -77,5 +78,5 @@ func GetHMACSign(userID int64) (string, error) {
 
 // Write is publishing data to server
 func Write(userID int64, data string) (bool, error) {
-	return publisher.Publish("client#"+strconv.FormatInt(userID, 10), []byte(data))
+	return publisher.Load().(*gocent.Client).Publish("client#"+strconv.FormatInt(userID, 10), []byte(data))
 }
@@ -23,15 +23,11 @@
 package smart
 
 import (
-	"bytes"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"math/rand"
-	"net/http"
 	"net/url"
 	"reflect"
 	"sort"
@@ -79,10 +75,14 @@ type SmartContract struct {
 	TxContract    *Contract
 	TxCost        int64           // Maximum cost of executing contract
 	TxUsedCost    decimal.Decimal // Used cost of CPU resources
+	TxFuel        decimal.Decimal // Fuel paid for the transaction
 	BlockData     *utils.BlockData
 	TxHash        []byte
 	PublicKeys    [][]byte
 	DbTransaction *model.DbTransaction
+	HTTPRecords   []model.HTTPRecord // answers of http requests which are recorded or replayed
+	HTTPReplay    bool               // if true then http requests return HTTPRecords instead of network calls
+	httpIndex     int
 }
 
 var (
@@ -214,6 +214,10 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		vmExtendCost(vm, getCost)
 		vmFuncCallsDB(vm, funcCallsDB)
 	case script.VMTypeSmart:
+		f["RegisterOracle"] = RegisterOracle
+		f["UpdateOracle"] = UpdateOracle
+		f["SubmitOracleValue"] = SubmitOracleValue
+		f["OracleValue"] = OracleValue
 		ExtendCost(getCostP)
 		FuncCallsDB(funcCallsDBP)
 	}
@@ -928,7 +932,6 @@ func CreateColumn(sc *SmartContract, tableName, name, coltype, permissions strin
 	if err != nil {
 		return err
 	}
-
 	return nil
 }
 
@@ -1040,76 +1043,32 @@ func Date(time_format string, timestamp int64) string {
 }
 
 // HTTPRequest sends http request
-func HTTPRequest(requrl, method string, headers map[string]interface{},
+func HTTPRequest(sc *SmartContract, requrl, method string, headers map[string]interface{},
 	params map[string]interface{}) (string, error) {
 
-	var ioform io.Reader
+	var body []byte
 
 	form := &url.Values{}
-	client := &http.Client{}
 	for key, v := range params {
 		form.Set(key, fmt.Sprint(v))
 	}
 	if len(*form) > 0 {
-		ioform = strings.NewReader(form.Encode())
-	}
-	req, err := http.NewRequest(method, requrl, ioform)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("new http request")
-		return ``, err
+		body = []byte(form.Encode())
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	hdrs := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
 	for key, v := range headers {
-		req.Header.Set(key, fmt.Sprint(v))
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("http request")
-		return ``, err
-	}
-	defer resp.Body.Close()
-	data, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading http answer")
-		return ``, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("http status code")
-		return ``, fmt.Errorf(`%d %s`, resp.StatusCode, strings.TrimSpace(string(data)))
+		hdrs[key] = fmt.Sprint(v)
 	}
-	return string(data), nil
+	return sc.sendHTTP(method, requrl, hdrs, body)
 }
 
 // HTTPPostJSON sends post http request with json
-func HTTPPostJSON(requrl string, headers map[string]interface{}, json_str string) (string, error) {
-
-	client := &http.Client{}
-
-	req, err := http.NewRequest("POST", requrl, bytes.NewBuffer([]byte(json_str)))
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("new http request")
-		return ``, err
-	}
-
+func HTTPPostJSON(sc *SmartContract, requrl string, headers map[string]interface{}, json_str string) (string, error) {
+	hdrs := make(map[string]string)
 	for key, v := range headers {
-		req.Header.Set(key, fmt.Sprint(v))
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("http request")
-		return ``, err
-	}
-	defer resp.Body.Close()
-	data, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading http answer")
-		return ``, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("http status code")
-		return ``, fmt.Errorf(`%d %s`, resp.StatusCode, strings.TrimSpace(string(data)))
+		hdrs[key] = fmt.Sprint(v)
 	}
-	return string(data), nil
+	return sc.sendHTTP("POST", requrl, hdrs, []byte(json_str))
 }
 
 func Random(min int64, max int64) (int64, error) {
@@ -0,0 +1,371 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package smart
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const maxHTTPRedirects = 5
+
+// HTTPPolicy restricts outbound http requests of contracts
+type HTTPPolicy struct {
+	Hosts   []string     // allowed host names, *.domain masks are supported
+	Nets    []*net.IPNet // allowed networks
+	Methods []string
+	MaxSize int64
+	Timeout time.Duration
+	Record  bool
+}
+
+// NewHTTPPolicy parses the policy from the text values of parameters
+func NewHTTPPolicy(hosts, methods string, maxSize, timeout int64, record bool) (*HTTPPolicy, error) {
+	policy := &HTTPPolicy{MaxSize: maxSize, Timeout: time.Duration(timeout) * time.Second, Record: record}
+	for _, item := range strings.Split(hosts, `,`) {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if len(item) == 0 {
+			continue
+		}
+		if strings.IndexByte(item, '/') >= 0 {
+			_, ipnet, err := net.ParseCIDR(item)
+			if err != nil {
+				return nil, fmt.Errorf(`wrong network %s`, item)
+			}
+			policy.Nets = append(policy.Nets, ipnet)
+			continue
+		}
+		if ip := net.ParseIP(item); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			policy.Nets = append(policy.Nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		policy.Hosts = append(policy.Hosts, item)
+	}
+	for _, item := range strings.Split(methods, `,`) {
+		if item = strings.ToUpper(strings.TrimSpace(item)); len(item) > 0 {
+			policy.Methods = append(policy.Methods, item)
+		}
+	}
+	if policy.MaxSize <= 0 {
+		policy.MaxSize = conf.Config.HTTPClient.MaxResponseSize
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = time.Duration(conf.Config.HTTPClient.Timeout) * time.Second
+	}
+	return policy, nil
+}
+
+// GetHTTPPolicy returns the policy of outbound http requests for the ecosystem of the contract.
+// The parameters of ecosystem override the settings of the node
+func GetHTTPPolicy(sc *SmartContract) (*HTTPPolicy, error) {
+	cfg := conf.Config.HTTPClient
+	hosts, methods := cfg.AllowedHosts, cfg.AllowedMethods
+	maxSize, timeout, record := cfg.MaxResponseSize, cfg.Timeout, cfg.Record
+	list, err := model.GetAll(`SELECT name, value FROM "`+getDefTableName(sc, `parameters`)+
+		`" WHERE name in (?)`, -1, []string{`http_allowed_hosts`, `http_allowed_methods`,
+		`http_max_response_size`, `http_timeout`, `http_record`})
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting http parameters")
+		return nil, err
+	}
+	for _, item := range list {
+		val := strings.TrimSpace(item[`value`])
+		if len(val) == 0 {
+			continue
+		}
+		switch item[`name`] {
+		case `http_allowed_hosts`:
+			hosts = val
+		case `http_allowed_methods`:
+			methods = val
+		case `http_max_response_size`:
+			maxSize = converter.StrToInt64(val)
+		case `http_timeout`:
+			timeout = converter.StrToInt64(val)
+		case `http_record`:
+			record = val == `1` || val == `true`
+		}
+	}
+	return NewHTTPPolicy(hosts, methods, maxSize, timeout, record)
+}
+
+func (policy *HTTPPolicy) isRestricted() bool {
+	return len(policy.Hosts) > 0 || len(policy.Nets) > 0
+}
+
+// AllowedHost checks if the host name is in the list of allowed hosts
+func (policy *HTTPPolicy) AllowedHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, item := range policy.Hosts {
+		if item == host || (strings.HasPrefix(item, `*.`) && strings.HasSuffix(host, item[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedIP checks if the contract can connect to ip. If the list of allowed hosts
+// is empty then only public addresses are allowed
+func (policy *HTTPPolicy) AllowedIP(ip net.IP) bool {
+	if !policy.isRestricted() {
+		return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+			ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+	}
+	for _, ipnet := range policy.Nets {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedMethod checks if the http method is allowed
+func (policy *HTTPPolicy) AllowedMethod(method string) bool {
+	for _, item := range policy.Methods {
+		if item == method {
+			return true
+		}
+	}
+	return false
+}
+
+// isNodeAPI checks if the url is the API of this node on the loopback interface
+func isNodeAPI(requrl *url.URL) bool {
+	port := requrl.Port()
+	if len(port) == 0 {
+		port = map[string]string{`http`: `80`, `https`: `443`}[requrl.Scheme]
+	}
+	if port != strconv.Itoa(conf.Config.HTTP.Port) {
+		return false
+	}
+	host := requrl.Hostname()
+	if host == `localhost` {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkURL checks the scheme, the method and the host of request. It returns true
+// if the host has been allowed by name so its addresses are not checked. If the list
+// of allowed hosts is empty then the API of the node is allowed
+func (policy *HTTPPolicy) checkURL(method string, requrl *url.URL) (bool, error) {
+	if requrl.Scheme != `http` && requrl.Scheme != `https` {
+		return false, fmt.Errorf(`scheme %s is not allowed`, requrl.Scheme)
+	}
+	if !policy.AllowedMethod(method) {
+		return false, fmt.Errorf(`method %s is not allowed`, method)
+	}
+	host := requrl.Hostname()
+	if policy.AllowedHost(host) || (!policy.isRestricted() && isNodeAPI(requrl)) {
+		return true, nil
+	}
+	if ip := net.ParseIP(host); ip != nil && !policy.AllowedIP(ip) {
+		return false, fmt.Errorf(`host %s is not allowed`, host)
+	}
+	if policy.isRestricted() && net.ParseIP(host) == nil && len(policy.Nets) == 0 {
+		return false, fmt.Errorf(`host %s is not allowed`, host)
+	}
+	return false, nil
+}
+
+// Client returns http client which enforces the policy
+func (policy *HTTPPolicy) Client(method string) *http.Client {
+	dialer := &net.Dialer{Timeout: policy.Timeout}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			d := *dialer
+			if byName, _ := ctx.Value(httpHostAllowed{}).(bool); !byName {
+				d.Control = func(network, address string, c syscall.RawConn) error {
+					host, _, err := net.SplitHostPort(address)
+					if err != nil {
+						return err
+					}
+					if ip := net.ParseIP(host); ip == nil || !policy.AllowedIP(ip) {
+						return fmt.Errorf(`address %s is not allowed`, host)
+					}
+					return nil
+				}
+			}
+			return d.DialContext(ctx, network, addr)
+		},
+		TLSHandshakeTimeout:   policy.Timeout,
+		ResponseHeaderTimeout: policy.Timeout,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   policy.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxHTTPRedirects {
+				return fmt.Errorf(`too many redirects`)
+			}
+			byName, err := policy.checkURL(method, req.URL)
+			if err != nil {
+				return err
+			}
+			*req = *req.WithContext(context.WithValue(req.Context(), httpHostAllowed{}, byName))
+			return nil
+		},
+	}
+}
+
+type httpHostAllowed struct{}
+
+func httpRequestHash(method, requrl string, headers map[string]string, body []byte) (string, error) {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	buf.WriteString(method + "\n" + requrl + "\n")
+	for _, key := range keys {
+		buf.WriteString(key + ":" + headers[key] + "\n")
+	}
+	buf.Write(body)
+	hash, err := crypto.Hash(buf.Bytes())
+	if err != nil {
+		return ``, err
+	}
+	return hex.EncodeToString(hash), nil
+}
+
+// replayHTTP returns the next recorded answer if it matches the request
+func (sc *SmartContract) replayHTTP(method, requrl, hash string) (string, error) {
+	if sc.httpIndex >= len(sc.HTTPRecords) {
+		return ``, fmt.Errorf(`there is no recorded answer for %s %s`, method, requrl)
+	}
+	rec := sc.HTTPRecords[sc.httpIndex]
+	sc.httpIndex++
+	if rec.Method != method || rec.URL != requrl || rec.Hash != hash {
+		return ``, fmt.Errorf(`%s %s does not match the recorded request`, method, requrl)
+	}
+	if rec.Status != http.StatusOK {
+		return ``, fmt.Errorf(`%d %s`, rec.Status, strings.TrimSpace(rec.Body))
+	}
+	return rec.Body, nil
+}
+
+// sendHTTP sends the request of the contract according to the policy of the ecosystem.
+// If the transaction has the recorded answers then they are returned instead of the network calls
+func (sc *SmartContract) sendHTTP(method, requrl string, headers map[string]string, body []byte) (string, error) {
+	method = strings.ToUpper(method)
+	logger := log.WithFields(log.Fields{"type": consts.HTTPRequestAudit, "ecosystem": sc.TxSmart.EcosystemID,
+		"key_id": sc.TxSmart.KeyID, "method": method, "url": requrl})
+	if sc.TxContract != nil {
+		logger = logger.WithFields(log.Fields{"contract": sc.TxContract.Name})
+	}
+	hash, err := httpRequestHash(method, requrl, headers, body)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Error("hashing http request")
+		return ``, err
+	}
+	if sc.HTTPReplay {
+		logger.Info("replaying http request")
+		return sc.replayHTTP(method, requrl, hash)
+	}
+	policy, err := GetHTTPPolicy(sc)
+	if err != nil {
+		return ``, err
+	}
+	return sc.requestHTTP(policy, logger, method, requrl, hash, headers, body)
+}
+
+// requestHTTP sends the request over the network and records the answer if the policy requires it
+func (sc *SmartContract) requestHTTP(policy *HTTPPolicy, logger *log.Entry, method, requrl, hash string,
+	headers map[string]string, body []byte) (string, error) {
+	parsed, err := url.Parse(requrl)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Error("parsing http url")
+		return ``, err
+	}
+	byName, err := policy.checkURL(method, parsed)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Warning("http request is denied")
+		return ``, err
+	}
+	var ioform io.Reader
+	if body != nil {
+		ioform = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, requrl, ioform)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Error("new http request")
+		return ``, err
+	}
+	for key, v := range headers {
+		req.Header.Set(key, v)
+	}
+	req = req.WithContext(context.WithValue(req.Context(), httpHostAllowed{}, byName))
+	start := time.Now()
+	resp, err := policy.Client(method).Do(req)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Error("http request")
+		return ``, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, policy.MaxSize+1))
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Error("reading http answer")
+		return ``, err
+	}
+	logger = logger.WithFields(log.Fields{"status": resp.StatusCode, "size": len(data),
+		"duration": time.Since(start)})
+	if int64(len(data)) > policy.MaxSize {
+		logger.Warning("http answer is too large")
+		return ``, fmt.Errorf(`http answer exceeds %d bytes`, policy.MaxSize)
+	}
+	logger.Info("http request")
+	if policy.Record {
+		sc.HTTPRecords = append(sc.HTTPRecords, model.HTTPRecord{Method: method, URL: requrl, Hash: hash,
+			Status: resp.StatusCode, Body: string(data)})
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ``, fmt.Errorf(`%d %s`, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return string(data), nil
+}
@@ -0,0 +1,144 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package smart
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestHTTPPolicy(t *testing.T) {
+	conf.Config.HTTP.Port = 7079
+	policy, err := NewHTTPPolicy(``, `GET`, 1024, 5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range []struct {
+		method, url string
+		ok          bool
+	}{
+		{`GET`, `https://example.com/api`, true},
+		{`POST`, `https://example.com/api`, false},
+		{`GET`, `ftp://example.com/file`, false},
+		{`GET`, `http://127.0.0.1:8080/`, false},
+		{`GET`, `http://10.1.2.3/`, false},
+		{`GET`, `http://[::1]/`, false},
+		{`GET`, `http://localhost:7079/api/v2/contracts`, true},
+		{`GET`, `http://127.0.0.1:7079/api/v2/contracts`, true},
+	} {
+		parsed, _ := url.Parse(item.url)
+		if _, err = policy.checkURL(item.method, parsed); (err == nil) != item.ok {
+			t.Errorf(`%s %s: %v`, item.method, item.url, err)
+		}
+	}
+
+	policy, err = NewHTTPPolicy(`*.example.com, api.test.org`, `GET,post`, 1024, 5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range []struct {
+		method, url string
+		ok          bool
+	}{
+		{`POST`, `https://rates.example.com/`, true},
+		{`GET`, `https://api.test.org/`, true},
+		{`GET`, `https://test.org/`, false},
+		{`GET`, `http://10.0.0.2/`, false},
+		{`PUT`, `https://rates.example.com/`, false},
+		{`GET`, `http://localhost:7079/api/v2/contracts`, false},
+	} {
+		parsed, _ := url.Parse(item.url)
+		if _, err = policy.checkURL(item.method, parsed); (err == nil) != item.ok {
+			t.Errorf(`%s %s: %v`, item.method, item.url, err)
+		}
+	}
+	policy, _ = NewHTTPPolicy(`10.0.0.0/8`, `GET`, 1024, 5, false)
+	if !policy.AllowedIP(net.ParseIP(`10.20.30.40`)) || policy.AllowedIP(net.ParseIP(`8.8.8.8`)) {
+		t.Error(`wrong checking of networks`)
+	}
+	if _, err = NewHTTPPolicy(`10.0.0.0/99`, `GET`, 0, 0, false); err == nil {
+		t.Error(`wrong network must be rejected`)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat(`a`, 100)))
+	}))
+	defer server.Close()
+
+	policy, _ := NewHTTPPolicy(``, `GET`, 1024, 5, false)
+	if _, err := policy.Client(`GET`).Get(server.URL); err == nil {
+		t.Error(`connection to loopback must be denied`)
+	}
+	policy, _ = NewHTTPPolicy(`127.0.0.1`, `GET`, 1024, 5, false)
+	resp, err := policy.Client(`GET`).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestHTTPReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`answer ` + r.URL.Query().Get(`q`)))
+	}))
+	requrl := server.URL + `/?q=1`
+	headers := map[string]string{`A`: `1`, `Content-Type`: `application/x-www-form-urlencoded`}
+	hash, err := httpRequestHash(`GET`, requrl, headers, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, _ := NewHTTPPolicy(`127.0.0.1`, `GET`, 1024, 5, true)
+	sc := &SmartContract{}
+	ret, err := sc.requestHTTP(policy, log.WithFields(log.Fields{}), `GET`, requrl, hash, headers, nil)
+	server.Close()
+	if err != nil || ret != `answer 1` {
+		t.Fatalf(`wrong answer %s %v`, ret, err)
+	}
+	if len(sc.HTTPRecords) != 1 || sc.HTTPRecords[0].Body != ret || sc.HTTPRecords[0].Hash != hash {
+		t.Fatalf(`wrong records %v`, sc.HTTPRecords)
+	}
+
+	// the server is closed, so the answer can only be taken from the records
+	sc = &SmartContract{HTTPReplay: true, HTTPRecords: sc.HTTPRecords}
+	ret, err = HTTPRequest(sc, requrl, `get`, map[string]interface{}{`A`: 1}, nil)
+	if err != nil || ret != `answer 1` {
+		t.Errorf(`wrong replay %s %v`, ret, err)
+	}
+	if _, err = HTTPRequest(sc, requrl, `GET`, nil, nil); err == nil {
+		t.Error(`there must be no more records`)
+	}
+	sc = &SmartContract{HTTPReplay: true, HTTPRecords: sc.HTTPRecords}
+	if _, err = HTTPRequest(sc, server.URL+`/?q=2`, `GET`, map[string]interface{}{`A`: 1}, nil); err == nil {
+		t.Error(`the different request must not match the record`)
+	}
+}
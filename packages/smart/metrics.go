@@ -0,0 +1,51 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package smart
+
+import (
+	"github.com/GenesisKernel/go-genesis/packages/metrics"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxContractSeries limits the number of contracts in metrics, because contracts are created by users
+const maxContractSeries = 200
+
+var (
+	contractCalls = metrics.NewCounter(`genesis_contract_executions_total`,
+		`Number of contract executions`, `contract`).Limit(maxContractSeries)
+	contractCost = metrics.NewCounter(`genesis_contract_cost_total`,
+		`Fuel spent by contract executions`, `contract`).Limit(maxContractSeries)
+	contractFailures = metrics.NewCounter(`genesis_contract_failures_total`,
+		`Number of contract executions which have finished with error`, `contract`).Limit(maxContractSeries)
+)
+
+func observeContract(name string, cost decimal.Decimal, err error) {
+	contractCalls.With(name).Inc()
+	if fuel, _ := cost.Float64(); fuel > 0 {
+		contractCost.With(name).Add(fuel)
+	}
+	if err != nil {
+		contractFailures.With(name).Inc()
+	}
+}
@@ -0,0 +1,255 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package smart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	oracleMaxFeedSize  = 255
+	oracleMaxValueSize = 1024
+	// oracleAllFeeds allows the oracle to submit values of any feed
+	oracleAllFeeds = `*`
+)
+
+// oracleTime returns the time which oracle values are checked against. It is the time
+// of the block when the transaction is being processed in the block
+func oracleTime(sc *SmartContract) int64 {
+	if sc.BlockData != nil {
+		return sc.BlockData.Time
+	}
+	return sc.TxSmart.Time
+}
+
+// parseFeeds checks and normalizes the comma separated list of feeds
+func parseFeeds(feeds string) (string, error) {
+	list := make([]string, 0)
+	for _, feed := range strings.Split(feeds, `,`) {
+		feed = strings.TrimSpace(feed)
+		if len(feed) == 0 {
+			continue
+		}
+		if len(feed) > oracleMaxFeedSize {
+			return ``, fmt.Errorf(`Feed %s is too long`, feed)
+		}
+		list = append(list, feed)
+	}
+	if len(list) == 0 {
+		return ``, fmt.Errorf(`Feeds are empty`)
+	}
+	return strings.Join(list, `,`), nil
+}
+
+// hasFeed returns true if the feed is in the list of oracle feeds
+func hasFeed(feeds, feed string) bool {
+	for _, item := range strings.Split(feeds, `,`) {
+		if item == feed || item == oracleAllFeeds {
+			return true
+		}
+	}
+	return false
+}
+
+// oracleAggregate returns the agreed value of oracles. Numeric values are aggregated
+// as the median, other values must match for at least quorum oracles
+func oracleAggregate(values []string, quorum int64) (string, error) {
+	if quorum < 1 {
+		quorum = 1
+	}
+	if int64(len(values)) < quorum {
+		return ``, fmt.Errorf(`Not enough oracle values %d < %d`, len(values), quorum)
+	}
+	numbers := make([]decimal.Decimal, 0, len(values))
+	for _, value := range values {
+		number, err := decimal.NewFromString(value)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, number)
+	}
+	if len(numbers) == len(values) {
+		sort.Slice(numbers, func(i, j int) bool { return numbers[i].LessThan(numbers[j]) })
+		middle := len(numbers) / 2
+		if len(numbers)%2 == 1 {
+			return numbers[middle].String(), nil
+		}
+		return numbers[middle-1].Add(numbers[middle]).Div(decimal.New(2, 0)).String(), nil
+	}
+	votes := make(map[string]int64)
+	for _, value := range values {
+		votes[value]++
+	}
+	var (
+		ret  string
+		max  int64
+		draw bool
+	)
+	for value, count := range votes {
+		if count > max {
+			ret, max, draw = value, count, false
+		} else if count == max {
+			draw = true
+		}
+	}
+	if draw || max < quorum {
+		return ``, fmt.Errorf(`Oracles have not reached consensus`)
+	}
+	return ret, nil
+}
+
+// RegisterOracle registers the key as the oracle of the comma separated list of feeds
+func RegisterOracle(sc *SmartContract, keyID int64, feeds string) (qcost int64, ret int64, err error) {
+	if !accessContracts(sc, `NewOracle`) {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("RegisterOracle can be only called from NewOracle")
+		return 0, 0, fmt.Errorf(`RegisterOracle can be only called from NewOracle`)
+	}
+	if keyID == 0 {
+		log.WithFields(log.Fields{"type": consts.InvalidObject}).Error("oracle key is zero")
+		return 0, 0, ErrInvalidValue
+	}
+	if feeds, err = parseFeeds(feeds); err != nil {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("parsing oracle feeds")
+		return 0, 0, err
+	}
+	oracle := &model.Oracle{}
+	found, err := oracle.GetByKey(sc.DbTransaction, keyID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting oracle")
+		return 0, 0, err
+	}
+	if found {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "key_id": keyID}).Error("oracle has been already registered")
+		return 0, 0, fmt.Errorf(`Oracle %d has been already registered`, keyID)
+	}
+	var id string
+	qcost, id, err = sc.selectiveLoggingAndUpd([]string{`key_id`, `feeds`, `active`},
+		[]interface{}{keyID, feeds, `1`}, `oracles`, nil, nil, !sc.VDE && sc.Rollback, false)
+	if err == nil {
+		ret = converter.StrToInt64(id)
+	}
+	return
+}
+
+// UpdateOracle changes the feeds and the activity of the oracle
+func UpdateOracle(sc *SmartContract, id int64, feeds string, active int64) (qcost int64, err error) {
+	if !accessContracts(sc, `EditOracle`) {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("UpdateOracle can be only called from EditOracle")
+		return 0, fmt.Errorf(`UpdateOracle can be only called from EditOracle`)
+	}
+	if feeds, err = parseFeeds(feeds); err != nil {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("parsing oracle feeds")
+		return 0, err
+	}
+	state := `0`
+	if active != 0 {
+		state = `1`
+	}
+	qcost, _, err = sc.selectiveLoggingAndUpd([]string{`feeds`, `active`}, []interface{}{feeds, state},
+		`oracles`, []string{`id`}, []string{converter.Int64ToStr(id)}, !sc.VDE && sc.Rollback, true)
+	return
+}
+
+// SubmitOracleValue stores the data point of the feed. The transaction must be signed
+// by the key of the active oracle of this feed
+func SubmitOracleValue(sc *SmartContract, feed, value string, t int64) (qcost int64, ret int64, err error) {
+	if !accessContracts(sc, `OracleSubmit`) {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("SubmitOracleValue can be only called from OracleSubmit")
+		return 0, 0, fmt.Errorf(`SubmitOracleValue can be only called from OracleSubmit`)
+	}
+	keyID := sc.TxSmart.KeyID
+	oracle := &model.Oracle{}
+	found, err := oracle.GetByKey(sc.DbTransaction, keyID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting oracle")
+		return 0, 0, err
+	}
+	if !found || oracle.Active != `1` || !hasFeed(oracle.Feeds, feed) {
+		log.WithFields(log.Fields{"type": consts.AccessDenied, "key_id": keyID, "feed": feed}).Error("key is not the oracle of the feed")
+		return 0, 0, errAccessDenied
+	}
+	if len(feed) == 0 || len(feed) > oracleMaxFeedSize || len(value) > oracleMaxValueSize {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "feed": feed}).Error("invalid oracle value")
+		return 0, 0, ErrInvalidValue
+	}
+	if t <= 0 || t > oracleTime(sc) {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "time": t}).Error("invalid time of oracle value")
+		return 0, 0, fmt.Errorf(`Invalid time %d of oracle value`, t)
+	}
+	var (
+		id      string
+		blockID int64
+	)
+	if sc.BlockData != nil {
+		blockID = sc.BlockData.BlockID
+	}
+	qcost, id, err = sc.selectiveLoggingAndUpd([]string{`feed`, `key_id`, `value`, `time`, `block_id`},
+		[]interface{}{feed, keyID, value, t, blockID}, `oracle_values`, nil, nil, !sc.VDE && sc.Rollback, false)
+	if err == nil {
+		ret = converter.StrToInt64(id)
+	}
+	return
+}
+
+// OracleValue returns the value of the feed agreed by oracles. Only values which are not
+// older than maxAge seconds are taken and the number of oracles must reach oracle_quorum
+func OracleValue(sc *SmartContract, feed string, maxAge int64) (string, error) {
+	if maxAge <= 0 {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "max_age": maxAge}).Error("invalid max age of oracle value")
+		return ``, ErrInvalidValue
+	}
+	now := oracleTime(sc)
+	list, err := model.GetLastOracleValues(sc.DbTransaction, feed, now-maxAge, now)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "feed": feed}).Error("getting oracle values")
+		return ``, err
+	}
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		oracle := &model.Oracle{}
+		found, err := oracle.GetByKey(sc.DbTransaction, item.KeyID)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting oracle")
+			return ``, err
+		}
+		if found && hasFeed(oracle.Feeds, feed) {
+			values = append(values, item.Value)
+		}
+	}
+	ret, err := oracleAggregate(values, syspar.SysInt64(`oracle_quorum`))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NotFound, "error": err, "feed": feed}).Error("aggregating oracle values")
+		return ``, err
+	}
+	return ret, nil
+}
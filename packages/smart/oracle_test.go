@@ -0,0 +1,63 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package smart
+
+import (
+	"testing"
+)
+
+func TestOracleAggregate(t *testing.T) {
+	for _, item := range []struct {
+		values []string
+		quorum int64
+		want   string
+		ok     bool
+	}{
+		{[]string{`1.0834`}, 1, `1.0834`, true},
+		{[]string{`1.0834`}, 2, ``, false},
+		{[]string{`3`, `1`, `2`}, 2, `2`, true},
+		{[]string{`1.5`, `1.1`, `1.2`, `9`}, 3, `1.35`, true},
+		{[]string{`ab01`, `ab01`, `ff02`}, 2, `ab01`, true},
+		{[]string{`ab01`, `ab01`, `ff02`}, 3, ``, false},
+		{[]string{`ab01`, `ff02`}, 1, ``, false},
+		{nil, 0, ``, false},
+	} {
+		ret, err := oracleAggregate(item.values, item.quorum)
+		if (err == nil) != item.ok || ret != item.want {
+			t.Errorf(`%v quorum %d: wrong result %q %v`, item.values, item.quorum, ret, err)
+		}
+	}
+}
+
+func TestOracleFeeds(t *testing.T) {
+	feeds, err := parseFeeds(` USD/EUR, ,BTC/USD `)
+	if err != nil || feeds != `USD/EUR,BTC/USD` {
+		t.Errorf(`wrong feeds %q %v`, feeds, err)
+	}
+	if _, err = parseFeeds(` , `); err == nil {
+		t.Error(`empty feeds must fail`)
+	}
+	if !hasFeed(feeds, `BTC/USD`) || hasFeed(feeds, `BTC`) || !hasFeed(`*`, `BTC`) {
+		t.Error(`wrong feed check`)
+	}
+}
@@ -24,10 +24,12 @@ package smart
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
@@ -70,6 +72,8 @@ var (
 	smartVDE  map[int64]*script.VM
 	smartTest = make(map[string]string)
 
+	contractsLoaded int32
+
 	ErrCurrentBalance = errors.New(`current balance is not enough`)
 	ErrDiffKeys       = errors.New(`Contract and user public keys are different`)
 	ErrEmptyPublicKey = errors.New(`empty public key`)
@@ -96,7 +100,7 @@ func (sc SmartContract) GetLogger() *log.Entry {
 	if sc.TxContract != nil {
 		name = sc.TxContract.Name
 	}
-	return log.WithFields(log.Fields{"vde": sc.VDE, "name": name})
+	return log.WithFields(log.Fields{"vde": sc.VDE, "name": name, "tx_hash": hex.EncodeToString(sc.TxHash)})
 }
 
 func newVM() *script.VM {
@@ -316,8 +320,18 @@ func (contract *Contract) GetFunc(name string) *script.Block {
 	return nil
 }
 
+// ContractsLoaded returns true if contracts have been successfully loaded by LoadContracts
+func ContractsLoaded() bool {
+	return atomic.LoadInt32(&contractsLoaded) == 1
+}
+
 // LoadContracts reads and compiles contracts from smart_contracts tables
 func LoadContracts(transaction *model.DbTransaction) (err error) {
+	defer func() {
+		if err == nil {
+			atomic.StoreInt32(&contractsLoaded, 1)
+		}
+	}()
 	var states []map[string]string
 	var prefix []string
 	prefix = []string{`system`}
@@ -844,6 +858,9 @@ func (sc *SmartContract) CallContract(flags int) (string, error) {
 	}
 	sc.TxUsedCost = decimal.New(before-(*sc.TxContract.Extend)[`txcost`].(int64), 0)
 	sc.TxContract.TxPrice = price
+	if (flags & CallRollback) == 0 {
+		observeContract(sc.TxContract.Name, sc.TxUsedCost, err)
+	}
 	if (*sc.TxContract.Extend)[`result`] != nil {
 		result = fmt.Sprint((*sc.TxContract.Extend)[`result`])
 		if len(result) > 255 {
@@ -882,6 +899,7 @@ func (sc *SmartContract) CallContract(flags int) (string, error) {
 			return retError(ierr)
 		}
 		logger.WithFields(log.Fields{"commission": commission}).Debug("Paid commission")
+		sc.TxFuel = apl
 	}
 	if err != nil {
 		return retError(err)
@@ -44,11 +44,14 @@ import (
 
 var (
 	funcCallsDBP = map[string]struct{}{
-		"DBInsert":         {},
-		"DBUpdate":         {},
-		"DBUpdateSysParam": {},
-		"DBUpdateExt":      {},
-		"DBSelect":         {},
+		"DBInsert":          {},
+		"DBUpdate":          {},
+		"DBUpdateSysParam":  {},
+		"DBUpdateExt":       {},
+		"DBSelect":          {},
+		"RegisterOracle":    {},
+		"UpdateOracle":      {},
+		"SubmitOracleValue": {},
 	}
 
 	extendCostSysParams = map[string]string{
@@ -86,6 +89,7 @@ var (
 		"CreateColumn":      "extend_cost_create_column",
 		"PermColumn":        "extend_cost_perm_column",
 		"JSONToMap":         "extend_cost_json_to_map",
+		"OracleValue":       "extend_cost_oracle_value",
 	}
 )
 
@@ -152,6 +156,8 @@ func UpdateSysParam(sc *SmartContract, name, value, conditions string) (int64, e
 		switch name {
 		case `gap_between_blocks`:
 			ok = ival > 0 && ival < 86400
+		case `oracle_quorum`:
+			ok = ival > 0
 		case `rb_blocks_1`, `number_of_nodes`:
 			ok = ival > 0 && ival < 1000
 		case `ecosystem_price`, `contract_price`, `column_price`, `table_price`, `menu_price`,
@@ -25,6 +25,7 @@ package statsd
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/cactus/go-statsd-client/statsd"
 )
@@ -34,20 +35,41 @@ const (
 	Time  = ".time"
 )
 
-var Client statsd.Statter
+// holder keeps the client in atomic.Value which requires the same concrete type
+type holder struct {
+	statsd.Statter
+}
+
+var current atomic.Value
 
+// Client returns the current statsd client
+func Client() statsd.Statter {
+	h, _ := current.Load().(holder)
+	return h.Statter
+}
+
+// Init creates the statsd client. The metrics are discarded if the host is empty.
+// The current client is replaced only if the new one has been created
 func Init(host string, port int, name string) error {
-	var err error
-	Client, err = statsd.NewClient(fmt.Sprintf("%s:%d", host, port), name)
+	var (
+		client statsd.Statter
+		err    error
+	)
+	if len(host) == 0 {
+		client, err = statsd.NewNoopClient()
+	} else {
+		client, err = statsd.NewClient(fmt.Sprintf("%s:%d", host, port), name)
+	}
 	if err != nil {
 		return err
 	}
+	current.Store(holder{client})
 	return nil
 }
 
 func Close() {
-	if Client != nil {
-		Client.Close()
+	if client := Client(); client != nil {
+		client.Close()
 	}
 }
 
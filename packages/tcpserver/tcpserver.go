@@ -30,14 +30,31 @@ import (
 	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/metrics"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// maxConnections is the limit of simultaneously handled requests
+const maxConnections = 20
+
 var (
-	counter int64
+	counter   int64
+	listening int32
+
+	tcpRejected = metrics.NewCounter(`genesis_tcp_rejected_total`,
+		`Number of TCP requests rejected because of the connection limit`).With()
 )
 
+func init() {
+	metrics.NewGaugeFunc(`genesis_tcp_connections`, `Number of handled TCP requests`, func() float64 {
+		return float64(atomic.LoadInt64(&counter))
+	})
+	metrics.NewGaugeFunc(`genesis_tcp_connections_limit`, `Limit of simultaneously handled TCP requests`, func() float64 {
+		return maxConnections
+	})
+}
+
 // HandleTCPRequest proceed TCP requests
 func HandleTCPRequest(rw io.ReadWriter) {
 	defer func() {
@@ -45,7 +62,8 @@ func HandleTCPRequest(rw io.ReadWriter) {
 	}()
 
 	count := atomic.AddInt64(&counter, +1)
-	if count > 20 {
+	if count > maxConnections {
+		tcpRejected.Inc()
 		return
 	}
 
@@ -106,6 +124,11 @@ func HandleTCPRequest(rw io.ReadWriter) {
 	}
 }
 
+// IsListening returns true if the tcp listener has been started
+func IsListening() bool {
+	return atomic.LoadInt32(&listening) == 1
+}
+
 // TcpListener is listening tcp address
 func TcpListener(laddr string) error {
 
@@ -119,6 +142,7 @@ func TcpListener(laddr string) error {
 		return err
 	}
 
+	atomic.StoreInt32(&listening, 1)
 	go func() {
 		defer l.Close()
 		for {
@@ -0,0 +1,216 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxComponentDepth is the maximum depth of nested components calls
+const maxComponentDepth = 16
+
+// component is a template function which has been declared with DefineComponent
+type component struct {
+	Name   string
+	Params []string
+	Body   string
+}
+
+func (comp *component) tplFunc() tplFunc {
+	return tplFunc{comp.render, comp.full, strings.ToLower(comp.Name), strings.Join(comp.Params, `,`)}
+}
+
+// slots returns the values of parameters which have been passed as tails like Card(...).Footer{...}
+func (comp *component) slots(par parFunc) map[string]string {
+	ret := make(map[string]string)
+	if par.Tails == nil {
+		return ret
+	}
+	for _, v := range *par.Tails {
+		if len(*v) < 2 {
+			continue
+		}
+		val := strings.TrimSpace(string((*v)[0]))
+		if strings.HasPrefix(val, `Body:`) {
+			val = val[len(`Body:`):]
+		}
+		ret[string((*v)[len(*v)-1])] = macro(strings.Trim(val, "\t\r\n \"`"), par.Workspace.Vars)
+	}
+	return ret
+}
+
+func (comp *component) render(par parFunc) string {
+	level := (*par.Workspace.Vars)[`_component`]
+	if len(level) >= maxComponentDepth {
+		return fmt.Sprintf(`too deep nesting of component %s`, comp.Name)
+	}
+	vals := make(map[string]string)
+	for _, name := range comp.Params {
+		vals[name] = (*par.Pars)[name]
+	}
+	for name, val := range comp.slots(par) {
+		vals[name] = val
+	}
+	// the parameters are bound as the variables of the component body and the outer values
+	// are restored after rendering, so the values are never parsed as the part of the body
+	vars := *par.Workspace.Vars
+	outer := make(map[string]*string)
+	for name, val := range vals {
+		if prev, ok := vars[name]; ok {
+			outer[name] = &prev
+		} else {
+			outer[name] = nil
+		}
+		vars[name] = val
+	}
+	vars[`_component`] = level + `1`
+	process(comp.Body, par.Owner, par.Workspace)
+	vars[`_component`] = level
+	for name, prev := range outer {
+		if prev == nil {
+			delete(vars, name)
+		} else {
+			vars[name] = *prev
+		}
+	}
+	return ``
+}
+
+func (comp *component) full(par parFunc) string {
+	setAllAttr(par)
+	for name, val := range comp.slots(par) {
+		tail := node{Tag: strings.ToLower(name)}
+		process(val, &tail, par.Workspace)
+		par.Node.Tail = append(par.Node.Tail, &tail)
+	}
+	par.Owner.Children = append(par.Owner.Children, par.Node)
+	return ``
+}
+
+// getTplFunc returns the built-in function or the component which has been declared in the workspace
+func getTplFunc(name string, workspace *Workspace) (tplFunc, bool) {
+	if curFunc, ok := funcs[name]; ok {
+		return curFunc, true
+	}
+	if workspace.Components != nil {
+		if comp, ok := (*workspace.Components)[strings.ToLower(name)]; ok && comp.Name == name {
+			return comp.tplFunc(), true
+		}
+	}
+	return tplFunc{}, false
+}
+
+// getTails returns the tails of built-in function or the component with the specified tag.
+// Each parameter of the component can be passed as a tail
+func getTails(tag string, workspace *Workspace) (forTails, bool) {
+	if tail, ok := tails[tag]; ok {
+		return tail, true
+	}
+	if workspace == nil || workspace.Components == nil {
+		return forTails{}, false
+	}
+	comp, ok := (*workspace.Components)[tag]
+	if !ok || len(comp.Params) == 0 {
+		return forTails{}, false
+	}
+	ret := forTails{make(map[string]tailInfo)}
+	for _, name := range comp.Params {
+		ret.Tails[name] = tailInfo{tplFunc{defaultTag, defaultTag, strings.ToLower(name), `Body`}, false}
+	}
+	return ret, true
+}
+
+func isComponent(tag string, workspace *Workspace) bool {
+	if workspace.Components == nil {
+		return false
+	}
+	_, ok := (*workspace.Components)[tag]
+	return ok
+}
+
+func checkComponentName(name string) error {
+	if len(name) == 0 {
+		return fmt.Errorf(`component name is empty`)
+	}
+	for _, ch := range name {
+		if (ch < 'A' || ch > 'Z') && (ch < 'a' || ch > 'z') {
+			return fmt.Errorf(`component name %s must contain only latin letters`, name)
+		}
+	}
+	tag := strings.ToLower(name)
+	if tag == tagText || tag == tagData {
+		return fmt.Errorf(`component %s conflicts with built-in function`, name)
+	}
+	for fname, item := range funcs {
+		if fname == name || item.Tag == tag {
+			return fmt.Errorf(`component %s conflicts with built-in function`, name)
+		}
+	}
+	return nil
+}
+
+func defineComponent(par parFunc) (*component, error) {
+	name := strings.TrimSpace((*par.Pars)[`Name`])
+	if err := checkComponentName(name); err != nil {
+		return nil, err
+	}
+	comp := &component{Name: name, Body: (*par.Pars)[`Body`]}
+	for _, param := range strings.Split((*par.Pars)[`Params`], `,`) {
+		param = strings.TrimSpace(param)
+		if len(param) == 0 {
+			continue
+		}
+		for _, ch := range param {
+			if (ch < 'A' || ch > 'Z') && (ch < 'a' || ch > 'z') && (ch < '0' || ch > '9') && ch != '_' {
+				return nil, fmt.Errorf(`wrong parameter %s of component %s`, param, name)
+			}
+		}
+		comp.Params = append(comp.Params, param)
+	}
+	if par.Workspace.Components == nil {
+		components := make(map[string]*component)
+		par.Workspace.Components = &components
+	}
+	(*par.Workspace.Components)[strings.ToLower(name)] = comp
+	return comp, nil
+}
+
+func defineComponentTag(par parFunc) string {
+	if _, err := defineComponent(par); err != nil {
+		return err.Error()
+	}
+	return ``
+}
+
+func defineComponentFull(par parFunc) string {
+	comp, err := defineComponent(par)
+	if err != nil {
+		return err.Error()
+	}
+	par.Node.Attr[`name`] = comp.Name
+	par.Node.Attr[`params`] = comp.Params
+	process(comp.Body, par.Node, par.Workspace)
+	par.Owner.Children = append(par.Owner.Children, par.Node)
+	return ``
+}
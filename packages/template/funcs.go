@@ -29,6 +29,7 @@ import (
 	"fmt"
 	"html"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -37,7 +38,6 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/config/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
-	"github.com/GenesisKernel/go-genesis/packages/language"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
 
@@ -48,6 +48,19 @@ var (
 	funcs = make(map[string]tplFunc)
 	tails = make(map[string]forTails)
 	modes = [][]rune{{'(', ')'}, {'{', '}'}}
+
+	// filterOps contains the allowed operators of DBFind().Filter
+	filterOps = map[string]string{
+		`=`: `=`, `eq`: `=`, `!=`: `<>`, `<>`: `<>`, `neq`: `<>`,
+		`>`: `>`, `gt`: `>`, `>=`: `>=`, `gte`: `>=`,
+		`<`: `<`, `lt`: `<`, `<=`: `<=`, `lte`: `<=`,
+		`like`: `like`, `ilike`: `ilike`, `in`: `in`,
+	}
+)
+
+const (
+	dbfindLimit    = 25
+	dbfindMaxLimit = 250
 )
 
 func init() {
@@ -91,6 +104,7 @@ func init() {
 	funcs[`Table`] = tplFunc{tableTag, defaultTailTag, `table`, `Source,Columns`}
 	funcs[`Select`] = tplFunc{defaultTailTag, defaultTailTag, `select`, `Name,Source,NameColumn,ValueColumn,Value,Class`}
 	funcs[`Chart`] = tplFunc{chartTag, defaultTailTag, `chart`, `Type,Source,FieldLabel,FieldValue,Colors`}
+	funcs[`DefineComponent`] = tplFunc{defineComponentTag, defineComponentFull, tagDefineComponent, `Name,Params,Body`}
 
 	tails[`button`] = forTails{map[string]tailInfo{
 		`Alert`: {tplFunc{alertTag, defaultTailFull, `alert`, `Text,ConfirmButton,CancelButton,Icon`}, true},
@@ -129,6 +143,8 @@ func init() {
 		`Order`:     {tplFunc{tailTag, defaultTailFull, `order`, `Order`}, false},
 		`Limit`:     {tplFunc{tailTag, defaultTailFull, `limit`, `Limit`}, false},
 		`Offset`:    {tplFunc{tailTag, defaultTailFull, `offset`, `Offset`}, false},
+		`Filter`:    {tplFunc{filterTag, defaultTailFull, `filter`, `Column,Op,Value`}, false},
+		`Count`:     {tplFunc{countTag, defaultTailFull, `count`, `Count`}, false},
 		`Ecosystem`: {tplFunc{tailTag, defaultTailFull, `ecosystem`, `Ecosystem`}, false},
 		`Custom`:    {tplFunc{customTag, defaultTailFull, `custom`, `Column,Body`}, false},
 		`Vars`:      {tplFunc{tailTag, defaultTailFull, `vars`, `Prefix`}, false},
@@ -225,21 +241,25 @@ func ecosysparTag(par parFunc) string {
 	if par.Workspace.SmartContract.VDE {
 		prefix += `_vde`
 	}
-	sp := &model.StateParameter{}
-	sp.SetTablePrefix(prefix)
-	_, err := sp.Get(nil, (*par.Pars)[`Name`])
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting ecosystem param")
-		return err.Error()
+	var val string
+	if par.Workspace.Stub != nil {
+		val = par.Workspace.Stub.Params[(*par.Pars)[`Name`]]
+	} else {
+		sp := &model.StateParameter{}
+		sp.SetTablePrefix(prefix)
+		_, err := sp.Get(nil, (*par.Pars)[`Name`])
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting ecosystem param")
+			return err.Error()
+		}
+		val = sp.Value
 	}
-	val := sp.Value
 	if len((*par.Pars)[`Source`]) > 0 {
 		data := make([][]string, 0)
 		cols := []string{`id`, `name`}
 		types := []string{`text`, `text`}
 		for key, item := range strings.Split(val, `,`) {
-			item, _ = language.LangText(item, state, (*par.Workspace.Vars)[`lang`],
-				par.Workspace.SmartContract.VDE)
+			item, _ = langText(par.Workspace, item, state, (*par.Workspace.Vars)[`lang`])
 			data = append(data, []string{converter.IntToStr(key + 1), item})
 		}
 		node := node{Tag: `data`, Attr: map[string]interface{}{`columns`: &cols, `types`: &types,
@@ -250,8 +270,7 @@ func ecosysparTag(par parFunc) string {
 	if len((*par.Pars)[`Index`]) > 0 {
 		ind := converter.StrToInt((*par.Pars)[`Index`])
 		if alist := strings.Split(val, `,`); ind > 0 && len(alist) >= ind {
-			val, _ = language.LangText(alist[ind-1], state, (*par.Workspace.Vars)[`lang`],
-				par.Workspace.SmartContract.VDE)
+			val, _ = langText(par.Workspace, alist[ind-1], state, (*par.Workspace.Vars)[`lang`])
 		} else {
 			val = ``
 		}
@@ -264,8 +283,8 @@ func langresTag(par parFunc) string {
 	if len(lang) == 0 {
 		lang = (*par.Workspace.Vars)[`lang`]
 	}
-	ret, _ := language.LangText((*par.Pars)[`Name`], int(converter.StrToInt64((*par.Workspace.Vars)[`ecosystem_id`])),
-		lang, par.Workspace.SmartContract.VDE)
+	ret, _ := langText(par.Workspace, (*par.Pars)[`Name`], int(converter.StrToInt64((*par.Workspace.Vars)[`ecosystem_id`])),
+		lang)
 	return ret
 }
 
@@ -284,6 +303,9 @@ func nowTag(par parFunc) string {
 	)
 	interval := (*par.Pars)[`Interval`]
 	format := (*par.Pars)[`Format`]
+	if par.Workspace.Stub != nil {
+		return par.Workspace.Stub.now(format, interval)
+	}
 	if len(interval) > 0 {
 		if interval[0] != '-' && interval[0] != '+' {
 			interval = `+` + interval
@@ -418,6 +440,9 @@ func dbfindTag(par parFunc) string {
 		state  int64
 		err    error
 		perm   map[string]string
+		args   []interface{}
+		offset int64
+		count  *int64
 	)
 	if len((*par.Pars)[`Name`]) == 0 {
 		return ``
@@ -426,18 +451,31 @@ func dbfindTag(par parFunc) string {
 	prefix := ``
 	where := ``
 	order := ``
-	limit := 25
+	limit := dbfindLimit
 	if par.Node.Attr[`columns`] != nil {
 		fields = converter.Escape(par.Node.Attr[`columns`].(string))
 	}
 	if len(fields) == 0 {
 		fields = `*`
 	}
+	conds := make([]string, 0)
+	if par.Node.Attr[`filters`] != nil {
+		var filter string
+		filter, args, err = filterToSQL(par.Node.Attr[`filters`].([]map[string]string))
+		if err != nil {
+			return err.Error()
+		}
+		conds = append(conds, filter)
+	}
 	if par.Node.Attr[`where`] != nil {
-		where = ` where ` + converter.Escape(par.Node.Attr[`where`].(string))
+		conds = append(conds, `(`+converter.Escape(par.Node.Attr[`where`].(string))+`)`)
 	}
 	if par.Node.Attr[`whereid`] != nil {
-		where = fmt.Sprintf(` where id='%d'`, converter.StrToInt64(par.Node.Attr[`whereid`].(string)))
+		conds = []string{fmt.Sprintf(`id='%d'`, converter.StrToInt64(par.Node.Attr[`whereid`].(string)))}
+		args = nil
+	}
+	if len(conds) > 0 {
+		where = ` where ` + strings.Join(conds, ` and `)
 	}
 	if par.Node.Attr[`order`] != nil {
 		order = ` order by ` + converter.EscapeName(par.Node.Attr[`order`].(string))
@@ -445,8 +483,14 @@ func dbfindTag(par parFunc) string {
 	if par.Node.Attr[`limit`] != nil {
 		limit = converter.StrToInt(par.Node.Attr[`limit`].(string))
 	}
-	if limit > 250 {
-		limit = 250
+	if limit > dbfindMaxLimit {
+		limit = dbfindMaxLimit
+	}
+	if par.Node.Attr[`offset`] != nil {
+		offset = converter.StrToInt64(par.Node.Attr[`offset`].(string))
+		if offset < 0 {
+			offset = 0
+		}
 	}
 	if par.Node.Attr[`prefix`] != nil {
 		prefix = par.Node.Attr[`prefix`].(string)
@@ -470,10 +514,35 @@ func dbfindTag(par parFunc) string {
 	if fields != `*` && !strings.Contains(fields, `id`) {
 		fields += `, id`
 	}
-	list, err := model.GetAll(`select `+fields+` from "`+tblname+`"`+where+order, limit)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting all from db")
-		return err.Error()
+	var (
+		list  []map[string]string
+		total int64
+	)
+	if par.Workspace.Stub != nil {
+		list, total, err = par.Workspace.Stub.find((*par.Pars)[`Name`], fields, par.Node.Attr, offset, limit)
+		if err != nil {
+			return err.Error()
+		}
+	} else {
+		if par.Node.Attr[`count`] != nil {
+			total, err = model.Single(`select count(*) from "`+tblname+`"`+where, args...).Int64()
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting count from db")
+				return err.Error()
+			}
+		}
+		list, err = model.GetAll(`select `+fields+` from "`+tblname+`"`+where+order+
+			fmt.Sprintf(` limit %d offset %d`, limit, offset), limit, args...)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting all from db")
+			return err.Error()
+		}
+	}
+	if par.Node.Attr[`count`] != nil {
+		if name := par.Node.Attr[`count`].(string); len(name) > 0 {
+			(*par.Workspace.Vars)[name] = converter.Int64ToStr(total)
+		}
+		count = &total
 	}
 	data := make([][]string, 0)
 	cols := make([]string, 0)
@@ -482,7 +551,7 @@ func dbfindTag(par parFunc) string {
 	defcol := 0
 	for _, item := range list {
 		if lencol == 0 {
-			for key := range item {
+			for _, key := range columnsOrder(fields, item) {
 				cols = append(cols, key)
 				types = append(types, `text`)
 			}
@@ -557,14 +626,123 @@ func dbfindTag(par parFunc) string {
 	delete(par.Node.Attr, `customs`)
 	delete(par.Node.Attr, `custombody`)
 	delete(par.Node.Attr, `prefix`)
+	delete(par.Node.Attr, `filters`)
+	delete(par.Node.Attr, `count`)
 	par.Node.Attr[`columns`] = &cols
 	par.Node.Attr[`types`] = &types
 	par.Node.Attr[`data`] = &data
+	pager := &Pager{Offset: offset, Limit: int64(limit), Count: count,
+		Sort: parseOrder(par.Node.Attr[`order`])}
+	if count != nil {
+		par.Node.Attr[`count`] = *count
+	}
 	newSource(par)
+	if par.Node.Attr[`source`] != nil {
+		src := (*par.Workspace.Sources)[par.Node.Attr[`source`].(string)]
+		src.Pager = pager
+		(*par.Workspace.Sources)[par.Node.Attr[`source`].(string)] = src
+	}
 	par.Owner.Children = append(par.Owner.Children, par.Node)
 	return ``
 }
 
+// columnsOrder returns the columns of row in the order of fields,
+// the rest columns are sorted by name
+func columnsOrder(fields string, row map[string]string) []string {
+	ret := make([]string, 0, len(row))
+	used := make(map[string]bool)
+	for _, field := range strings.Split(fields, `,`) {
+		field = strings.Trim(strings.TrimSpace(field), `"`)
+		if _, ok := row[field]; ok && !used[field] {
+			ret = append(ret, field)
+			used[field] = true
+		}
+	}
+	rest := make([]string, 0)
+	for key := range row {
+		if !used[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(ret, rest...)
+}
+
+func filterTag(par parFunc) string {
+	if par.Owner.Attr[`filters`] == nil {
+		par.Owner.Attr[`filters`] = make([]map[string]string, 0)
+	}
+	par.Owner.Attr[`filters`] = append(par.Owner.Attr[`filters`].([]map[string]string),
+		map[string]string{`column`: (*par.Pars)[`Column`], `op`: (*par.Pars)[`Op`],
+			`value`: (*par.Pars)[`Value`]})
+	return ``
+}
+
+func countTag(par parFunc) string {
+	par.Owner.Attr[`count`] = (*par.Pars)[`Count`]
+	return ``
+}
+
+// filterToSQL compiles the list of DBFind filters to the parameterized condition
+func filterToSQL(filters []map[string]string) (string, []interface{}, error) {
+	conds := make([]string, 0, len(filters))
+	args := make([]interface{}, 0, len(filters))
+	for _, item := range filters {
+		column := strings.ToLower(strings.TrimSpace(item[`column`]))
+		if !isColumnName(column) {
+			return ``, nil, fmt.Errorf(`wrong filter column %s`, item[`column`])
+		}
+		op, ok := filterOps[strings.ToLower(strings.TrimSpace(item[`op`]))]
+		if !ok {
+			return ``, nil, fmt.Errorf(`wrong filter operator %s`, item[`op`])
+		}
+		if op == `in` {
+			vals := strings.Split(item[`value`], `,`)
+			for i, v := range vals {
+				vals[i] = strings.TrimSpace(v)
+			}
+			conds = append(conds, fmt.Sprintf(`"%s" in (?)`, column))
+			args = append(args, vals)
+			continue
+		}
+		conds = append(conds, fmt.Sprintf(`"%s" %s ?`, column, op))
+		args = append(args, item[`value`])
+	}
+	return strings.Join(conds, ` and `), args, nil
+}
+
+func isColumnName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for _, ch := range name {
+		if (ch < 'a' || ch > 'z') && (ch < '0' || ch > '9') && ch != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseOrder converts the order attribute of DBFind to the list of sort fields
+func parseOrder(order interface{}) []*sortField {
+	if order == nil {
+		return nil
+	}
+	ret := make([]*sortField, 0)
+	for _, item := range strings.Split(order.(string), `,`) {
+		fields := strings.Fields(strings.Trim(strings.TrimSpace(item), `"`))
+		if len(fields) == 0 {
+			continue
+		}
+		field := &sortField{Column: strings.Trim(fields[0], `"`), Direction: `asc`}
+		if len(fields) > 1 && strings.ToLower(fields[1]) == `desc` {
+			field.Direction = `desc`
+		}
+		ret = append(ret, field)
+	}
+	return ret
+}
+
 func customTag(par parFunc) string {
 	setAllAttr(par)
 	if par.Owner.Attr[`customs`] == nil {
@@ -586,10 +764,18 @@ func tailTag(par parFunc) string {
 
 func includeTag(par parFunc) string {
 	if len((*par.Pars)[`Name`]) >= 0 && len((*par.Workspace.Vars)[`_include`]) < 5 {
-		pattern, err := model.Single(`select value from "`+(*par.Workspace.Vars)[`ecosystem_id`]+`_blocks" where name=?`, (*par.Pars)[`Name`]).String()
-		if err != nil {
-			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block by name")
-			return err.Error()
+		var (
+			pattern string
+			err     error
+		)
+		if par.Workspace.Stub != nil {
+			pattern = par.Workspace.Stub.Blocks[(*par.Pars)[`Name`]]
+		} else {
+			pattern, err = model.Single(`select value from "`+(*par.Workspace.Vars)[`ecosystem_id`]+`_blocks" where name=?`, (*par.Pars)[`Name`]).String()
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block by name")
+				return err.Error()
+			}
 		}
 		if len(pattern) > 0 {
 			root := node{}
@@ -638,6 +824,14 @@ func tableTag(par parFunc) string {
 			par.Node.Attr[`columns`] = imap
 		}
 	}
+	if par.Node.Attr[`source`] != nil && par.Workspace.Sources != nil {
+		if src, ok := (*par.Workspace.Sources)[par.Node.Attr[`source`].(string)]; ok && src.Pager != nil {
+			par.Node.Attr[`pager`] = src.Pager
+			if len(src.Pager.Sort) > 0 {
+				par.Node.Attr[`sort`] = src.Pager.Sort
+			}
+		}
+	}
 	return ``
 }
 
@@ -757,21 +951,26 @@ func dateTimeTag(par parFunc) string {
 	}
 	format := (*par.Pars)[`Format`]
 	if len(format) == 0 {
-		format, _ = language.LangText(`timeformat`, converter.StrToInt((*par.Workspace.Vars)[`ecosystem_id`]),
-			(*par.Workspace.Vars)[`lang`], par.Workspace.SmartContract.VDE)
+		format, _ = langText(par.Workspace, `timeformat`, converter.StrToInt((*par.Workspace.Vars)[`ecosystem_id`]),
+			(*par.Workspace.Vars)[`lang`])
 		if format == `timeformat` {
 			format = `2006-01-02 15:04:05`
 		}
 	}
+	return itime.Format(timeFormat(format))
+}
+
+// timeFormat converts the template format of the time to Go layout
+func timeFormat(format string) string {
 	format = strings.Replace(format, `YYYY`, `2006`, -1)
 	format = strings.Replace(format, `YY`, `06`, -1)
 	format = strings.Replace(format, `MM`, `01`, -1)
 	format = strings.Replace(format, `DD`, `02`, -1)
+	format = strings.Replace(format, `HH24`, `HH`, -1)
 	format = strings.Replace(format, `HH`, `15`, -1)
 	format = strings.Replace(format, `MI`, `04`, -1)
 	format = strings.Replace(format, `SS`, `05`, -1)
-
-	return itime.Format(format)
+	return format
 }
 
 func cmpTimeTag(par parFunc) string {
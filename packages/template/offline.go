@@ -0,0 +1,462 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package template
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/language"
+)
+
+// Stub contains the predefined data which is used instead of database queries
+// when a template is rendered offline
+type Stub struct {
+	Now    time.Time
+	Vars   map[string]string
+	Tables map[string][]map[string]string
+	Blocks map[string]string
+	Params map[string]string
+	Lang   map[string]map[string]string
+}
+
+type stubFile struct {
+	Now    string                              `json:"now"`
+	Vars   map[string]string                   `json:"vars"`
+	Tables map[string][]map[string]interface{} `json:"tables"`
+	CSV    map[string]string                   `json:"csv"`
+	Blocks map[string]string                   `json:"blocks"`
+	Params map[string]string                   `json:"params"`
+	Lang   map[string]map[string]string        `json:"lang"`
+}
+
+// LoadStub reads the stub from JSON file. The rows of tables can be specified in the file
+// or in CSV files which paths are relative to the stub file
+func LoadStub(filename string) (*Stub, error) {
+	var in stubFile
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf(`%s: %s`, filename, err)
+	}
+	stub := &Stub{
+		Vars:   in.Vars,
+		Tables: make(map[string][]map[string]string),
+		Blocks: in.Blocks,
+		Params: in.Params,
+		Lang:   in.Lang,
+	}
+	if len(in.Now) > 0 {
+		if stub.Now, err = time.Parse(time.RFC3339, in.Now); err != nil {
+			return nil, fmt.Errorf(`%s: %s`, filename, err)
+		}
+	}
+	for table, rows := range in.Tables {
+		list := make([]map[string]string, len(rows))
+		for i, row := range rows {
+			list[i] = make(map[string]string)
+			for key, val := range row {
+				if val == nil {
+					list[i][key] = `NULL`
+				} else {
+					list[i][key] = fmt.Sprint(val)
+				}
+			}
+		}
+		stub.Tables[table] = list
+	}
+	for table, csvfile := range in.CSV {
+		if !filepath.IsAbs(csvfile) {
+			csvfile = filepath.Join(filepath.Dir(filename), csvfile)
+		}
+		if err = stub.LoadCSV(table, csvfile); err != nil {
+			return nil, err
+		}
+	}
+	return stub, nil
+}
+
+// LoadCSV reads the rows of table from CSV file. The first line must contain the names of columns
+func (stub *Stub) LoadCSV(table, filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	list, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return fmt.Errorf(`%s: %s`, filename, err)
+	}
+	if stub.Tables == nil {
+		stub.Tables = make(map[string][]map[string]string)
+	}
+	rows := make([]map[string]string, 0)
+	for i, item := range list {
+		if i == 0 {
+			continue
+		}
+		row := make(map[string]string)
+		for j, col := range list[0] {
+			row[strings.TrimSpace(col)] = item[j]
+		}
+		rows = append(rows, row)
+	}
+	stub.Tables[table] = rows
+	return nil
+}
+
+// find returns the rows of the stub table like DBFind does. It returns the total count of rows too.
+// WhereId, Where, Filter and Order are taken from attributes of DBFind
+func (stub *Stub) find(table, fields string, attr map[string]interface{}, offset int64,
+	limit int) ([]map[string]string, int64, error) {
+	rows, ok := stub.Tables[table]
+	if !ok {
+		rows = stub.Tables[strings.ToLower(table)]
+	}
+	if attr[`whereid`] != nil {
+		id := converter.Int64ToStr(converter.StrToInt64(attr[`whereid`].(string)))
+		filtered := make([]map[string]string, 0)
+		for _, row := range rows {
+			if row[`id`] == id {
+				filtered = append(filtered, row)
+			}
+		}
+		rows = filtered
+	} else {
+		if attr[`where`] != nil {
+			return nil, 0, fmt.Errorf(`Where is not supported offline, use Filter instead`)
+		}
+		if attr[`filters`] != nil {
+			filtered := make([]map[string]string, 0)
+			for _, row := range rows {
+				match, err := stubMatch(row, attr[`filters`].([]map[string]string))
+				if err != nil {
+					return nil, 0, err
+				}
+				if match {
+					filtered = append(filtered, row)
+				}
+			}
+			rows = filtered
+		}
+	}
+	if order := parseOrder(attr[`order`]); len(order) > 0 {
+		for _, field := range order {
+			if !isColumnName(field.Column) {
+				return nil, 0, fmt.Errorf(`wrong order column %s`, field.Column)
+			}
+		}
+		rows = append([]map[string]string{}, rows...)
+		stubSort(rows, order)
+	}
+	total := int64(len(rows))
+	if offset >= total {
+		return nil, total, nil
+	}
+	rows = rows[offset:]
+	if limit >= 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	var cols []string
+	if fields != `*` {
+		for _, field := range strings.Split(fields, `,`) {
+			cols = append(cols, strings.Trim(strings.TrimSpace(field), `"`))
+		}
+	}
+	list := make([]map[string]string, len(rows))
+	for i, row := range rows {
+		list[i] = make(map[string]string)
+		if cols == nil {
+			for key, val := range row {
+				list[i][key] = val
+			}
+			continue
+		}
+		for _, col := range cols {
+			if val, ok := row[col]; ok {
+				list[i][col] = val
+			} else {
+				list[i][col] = `NULL`
+			}
+		}
+	}
+	return list, total, nil
+}
+
+// stubMatch checks if the row of stub satisfies all DBFind filters like filterToSQL condition does
+func stubMatch(row map[string]string, filters []map[string]string) (bool, error) {
+	for _, item := range filters {
+		column := strings.ToLower(strings.TrimSpace(item[`column`]))
+		if !isColumnName(column) {
+			return false, fmt.Errorf(`wrong filter column %s`, item[`column`])
+		}
+		op, ok := filterOps[strings.ToLower(strings.TrimSpace(item[`op`]))]
+		if !ok {
+			return false, fmt.Errorf(`wrong filter operator %s`, item[`op`])
+		}
+		val, ok := row[column]
+		if !ok || val == `NULL` {
+			return false, nil
+		}
+		var match bool
+		switch op {
+		case `in`:
+			for _, v := range strings.Split(item[`value`], `,`) {
+				if stubCompare(val, strings.TrimSpace(v)) == 0 {
+					match = true
+					break
+				}
+			}
+		case `like`, `ilike`:
+			re, err := likeRegexp(item[`value`], op == `ilike`)
+			if err != nil {
+				return false, err
+			}
+			match = re.MatchString(val)
+		default:
+			cmp := stubCompare(val, item[`value`])
+			switch op {
+			case `=`:
+				match = cmp == 0
+			case `<>`:
+				match = cmp != 0
+			case `>`:
+				match = cmp > 0
+			case `>=`:
+				match = cmp >= 0
+			case `<`:
+				match = cmp < 0
+			case `<=`:
+				match = cmp <= 0
+			}
+		}
+		if !match {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// stubCompare compares the values as numbers if both of them are numbers and as strings otherwise
+func stubCompare(left, right string) int {
+	lnum, lerr := strconv.ParseFloat(left, 64)
+	rnum, rerr := strconv.ParseFloat(right, 64)
+	if lerr == nil && rerr == nil {
+		switch {
+		case lnum < rnum:
+			return -1
+		case lnum > rnum:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(left, right)
+}
+
+// likeRegexp converts the pattern of SQL LIKE to the regular expression
+func likeRegexp(pattern string, ignoreCase bool) (*regexp.Regexp, error) {
+	var expr bytes.Buffer
+	if ignoreCase {
+		expr.WriteString(`(?i)`)
+	}
+	expr.WriteString(`^`)
+	for _, ch := range pattern {
+		switch ch {
+		case '%':
+			expr.WriteString(`.*`)
+		case '_':
+			expr.WriteString(`.`)
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	expr.WriteString(`$`)
+	return regexp.Compile(expr.String())
+}
+
+// stubSort sorts the rows of stub by the fields of DBFind order, NULL values go last like in PostgreSQL
+func stubSort(rows []map[string]string, fields []*sortField) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, field := range fields {
+			left, right := rows[i][field.Column], rows[j][field.Column]
+			if left == right {
+				continue
+			}
+			var less bool
+			switch {
+			case left == `NULL`:
+				less = false
+			case right == `NULL`:
+				less = true
+			default:
+				cmp := stubCompare(left, right)
+				if cmp == 0 {
+					continue
+				}
+				less = cmp < 0
+			}
+			if field.Direction == `desc` {
+				return !less
+			}
+			return less
+		}
+		return false
+	})
+}
+
+// langText looks for the language resource in the stub if the template is rendered offline
+func langText(workspace *Workspace, in string, state int, accept string) (string, bool) {
+	if workspace.Stub != nil {
+		return language.Resources(workspace.Stub.Lang).Text(in, accept)
+	}
+	return language.LangText(in, state, accept, workspace.SmartContract.VDE)
+}
+
+// langMacro replaces the language resources in the text, they are taken from the stub if
+// the template is rendered offline
+func langMacro(workspace *Workspace, input string, state int, accept string) string {
+	if workspace.Stub != nil {
+		return language.Resources(workspace.Stub.Lang).Macro(input, accept)
+	}
+	return language.LangMacro(input, state, accept, workspace.SmartContract.VDE)
+}
+
+// now returns the fixed time of the stub like Now function does
+func (stub *Stub) now(format, interval string) string {
+	cur := stub.Now
+	if len(interval) > 0 {
+		cur = addInterval(cur, interval)
+	}
+	switch format {
+	case ``:
+		return strconv.FormatInt(cur.Unix(), 10)
+	case `datetime`:
+		return cur.Format(`2006-01-02 15:04:05`)
+	}
+	return cur.Format(timeFormat(format))
+}
+
+// addInterval adds the interval in PostgreSQL format like '+2 days' to the time
+func addInterval(cur time.Time, interval string) time.Time {
+	interval = strings.TrimSpace(interval)
+	sign := 1
+	if len(interval) > 0 && (interval[0] == '-' || interval[0] == '+') {
+		if interval[0] == '-' {
+			sign = -1
+		}
+		interval = interval[1:]
+	}
+	items := strings.Fields(interval)
+	for i := 0; i+1 < len(items); i += 2 {
+		count := sign * converter.StrToInt(items[i])
+		switch strings.TrimSuffix(strings.ToLower(items[i+1]), `s`) {
+		case `second`:
+			cur = cur.Add(time.Duration(count) * time.Second)
+		case `minute`:
+			cur = cur.Add(time.Duration(count) * time.Minute)
+		case `hour`:
+			cur = cur.Add(time.Duration(count) * time.Hour)
+		case `day`:
+			cur = cur.AddDate(0, 0, count)
+		case `week`:
+			cur = cur.AddDate(0, 0, 7*count)
+		case `month`:
+			cur = cur.AddDate(0, count, 0)
+		case `year`:
+			cur = cur.AddDate(count, 0, 0)
+		}
+	}
+	return cur
+}
+
+// Render converts the template to JSON tree without database. All data which templates
+// read from database are taken from stub. vars overrides the variables of stub
+func Render(input string, vars map[string]string, stub *Stub) []byte {
+	var timeout bool
+	if stub == nil {
+		stub = &Stub{}
+	}
+	if stub.Now.IsZero() {
+		stub.Now = time.Now()
+	}
+	wvars := map[string]string{`ecosystem_id`: `1`, `key_id`: `0`, `lang`: `en`, `_full`: `0`}
+	for key, val := range stub.Vars {
+		wvars[key] = val
+	}
+	for key, val := range vars {
+		wvars[key] = val
+	}
+	return template2JSON(input, &timeout, &wvars, stub)
+}
+
+// CompareGolden compares the JSON tree with the golden file. If update is true then
+// the golden file is overwritten with the tree
+func CompareGolden(tree []byte, filename string, update bool) error {
+	var out bytes.Buffer
+	if err := json.Indent(&out, tree, ``, `  `); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	if update {
+		return ioutil.WriteFile(filename, out.Bytes(), 0644)
+	}
+	golden, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(golden, out.Bytes()) {
+		return fmt.Errorf("%s does not match\n%s", filename, firstDiff(golden, out.Bytes()))
+	}
+	return nil
+}
+
+func firstDiff(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var wline, gline string
+		if i < len(wantLines) {
+			wline = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gline = gotLines[i]
+		}
+		if wline != gline {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, wline, gline)
+		}
+	}
+	return ``
+}
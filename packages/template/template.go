@@ -32,7 +32,6 @@ import (
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
-	"github.com/GenesisKernel/go-genesis/packages/language"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
 	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 
@@ -41,8 +40,9 @@ import (
 )
 
 const (
-	tagText = `text`
-	tagData = `data`
+	tagText            = `text`
+	tagData            = `data`
+	tagDefineComponent = `definecomponent`
 )
 
 type node struct {
@@ -57,6 +57,20 @@ type node struct {
 type Source struct {
 	Columns *[]string
 	Data    *[][]string
+	Pager   *Pager
+}
+
+// Pager describes the window of rows which has been selected by dbfind
+type Pager struct {
+	Offset int64        `json:"offset"`
+	Limit  int64        `json:"limit"`
+	Count  *int64       `json:"count,omitempty"`
+	Sort   []*sortField `json:"-"`
+}
+
+type sortField struct {
+	Column    string `json:"column"`
+	Direction string `json:"direction"`
 }
 
 type Workspace struct {
@@ -64,6 +78,8 @@ type Workspace struct {
 	Vars          *map[string]string
 	SmartContract *smart.SmartContract
 	Timeout       *bool
+	Stub          *Stub
+	Components    *map[string]*component
 }
 
 type parFunc struct {
@@ -319,7 +335,10 @@ func callFunc(curFunc *tplFunc, owner *node, workspace *Workspace, params *[][]r
 	} else {
 		for i, v := range strings.Split(curFunc.Params, `,`) {
 			if i < len(*params) {
-				val := macro(strings.TrimSpace(string((*params)[i])), workspace.Vars)
+				val := strings.TrimSpace(string((*params)[i]))
+				if curFunc.Tag != tagDefineComponent {
+					val = macro(val, workspace.Vars)
+				}
 				off := strings.IndexByte(val, ':')
 				if off != -1 && strings.Contains(curFunc.Params, val[:off]) {
 					cut := "\t\r\n \"`"
@@ -336,10 +355,9 @@ func callFunc(curFunc *tplFunc, owner *node, workspace *Workspace, params *[][]r
 		}
 	}
 	state := int(converter.StrToInt64((*workspace.Vars)[`ecosystem_id`]))
-	if (*workspace.Vars)[`_full`] != `1` {
+	if (*workspace.Vars)[`_full`] != `1` && curFunc.Tag != tagDefineComponent {
 		for i, v := range pars {
-			pars[i] = language.LangMacro(v, state, (*workspace.Vars)[`lang`],
-				workspace.SmartContract.VDE)
+			pars[i] = langMacro(workspace, v, state, (*workspace.Vars)[`lang`])
 			if pars[i] != v {
 				if parFunc.RawPars == nil {
 					rawpars := make(map[string]string)
@@ -352,7 +370,8 @@ func callFunc(curFunc *tplFunc, owner *node, workspace *Workspace, params *[][]r
 	if len(curFunc.Tag) > 0 {
 		curNode.Tag = curFunc.Tag
 		curNode.Attr = make(map[string]interface{})
-		if len(pars[`Body`]) > 0 && curFunc.Tag != `custom` {
+		if len(pars[`Body`]) > 0 && curFunc.Tag != `custom` && curFunc.Tag != tagDefineComponent &&
+			((*workspace.Vars)[`_full`] == `1` || !isComponent(curFunc.Tag, workspace)) {
 			process(pars[`Body`], &curNode, workspace)
 		}
 		parFunc.Owner = owner
@@ -377,7 +396,7 @@ func callFunc(curFunc *tplFunc, owner *node, workspace *Workspace, params *[][]r
 	}
 }
 
-func getFunc(input string, curFunc tplFunc) (*[][]rune, int, *[]*[][]rune) {
+func getFunc(input string, curFunc tplFunc, workspace *Workspace) (*[][]rune, int, *[]*[][]rune) {
 	var (
 		curp, skip, off, mode, lenParams int
 		quote                            bool
@@ -483,7 +502,7 @@ main:
 						continue
 					}
 				}
-				for tail, ok := tails[curFunc.Tag]; ok && off+2 < len(input) && input[off+1] == '.'; {
+				for tail, ok := getTails(curFunc.Tag, workspace); ok && off+2 < len(input) && input[off+1] == '.'; {
 					var found bool
 					for key, tailFunc := range tail.Tails {
 						next := off + 2
@@ -502,7 +521,7 @@ main:
 								break
 							}
 							if isTail {
-								parTail, shift, _ := getFunc(input[next:], tailFunc.tplFunc)
+								parTail, shift, _ := getFunc(input[next:], tailFunc.tplFunc, workspace)
 								off = next
 								for ; shift > 0; shift-- {
 									_, size := utf8.DecodeRuneInString(input[off:])
@@ -550,18 +569,18 @@ func process(input string, owner *node, workspace *Workspace) {
 			continue
 		}
 		if ch == '(' {
-			if curFunc, isFunc = funcs[string(name[nameOff:])]; isFunc {
+			if curFunc, isFunc = getTplFunc(string(name[nameOff:]), workspace); isFunc {
 				if *workspace.Timeout {
 					return
 				}
 				appendText(owner, string(name[:nameOff]))
 				name = name[:0]
 				nameOff = 0
-				params, shift, tailpars = getFunc(input[off:], curFunc)
+				params, shift, tailpars = getFunc(input[off:], curFunc, workspace)
 				callFunc(&curFunc, owner, workspace, params, tailpars)
 				for off+shift+3 < len(input) && input[off+shift+1:off+shift+3] == `.(` {
 					var next int
-					params, next, tailpars = getFunc(input[off+shift+2:], curFunc)
+					params, next, tailpars = getFunc(input[off+shift+2:], curFunc, workspace)
 					callFunc(&curFunc, owner, workspace, params, tailpars)
 					shift += next + 2
 				}
@@ -578,6 +597,10 @@ func process(input string, owner *node, workspace *Workspace) {
 
 // Template2JSON converts templates to JSON data
 func Template2JSON(input string, timeout *bool, vars *map[string]string) []byte {
+	return template2JSON(input, timeout, vars, nil)
+}
+
+func template2JSON(input string, timeout *bool, vars *map[string]string, stub *Stub) []byte {
 	root := node{}
 	isvde := (*vars)[`vde`] == `true` || (*vars)[`vde`] == `1`
 
@@ -587,7 +610,7 @@ func Template2JSON(input string, timeout *bool, vars *map[string]string) []byte
 		TxSmart: tx.SmartContract{Header: tx.Header{EcosystemID: converter.StrToInt64((*vars)[`ecosystem_id`]),
 			KeyID: converter.StrToInt64((*vars)[`key_id`])}},
 	}
-	process(input, &root, &Workspace{Vars: vars, Timeout: timeout, SmartContract: &sc})
+	process(input, &root, &Workspace{Vars: vars, Timeout: timeout, SmartContract: &sc, Stub: stub})
 	if root.Children == nil || *timeout {
 		return []byte(`[]`)
 	}
@@ -221,3 +221,110 @@ var forFullTest = tplList{
 			}.Else {Fourth}If(0).Else{ALL right}.What`,
 		`[{"tag":"if","attr":{"condition":"true"},"children":[{"tag":"text","text":"OK"}],"tail":[{"tag":"else","children":[{"tag":"text","text":"false"}]}]},{"tag":"if","attr":{"condition":"false"},"children":[{"tag":"text","text":"FALSE"}],"tail":[{"tag":"elseif","attr":{"condition":"1"},"children":[{"tag":"text","text":"Else OK"}]},{"tag":"else","children":[{"tag":"text","text":"Fourth"}]}]},{"tag":"if","attr":{"condition":"0"},"tail":[{"tag":"else","children":[{"tag":"text","text":"ALL right"}]}]},{"tag":"text","text":".What"}]`},
 }
+
+func TestFilterToSQL(t *testing.T) {
+	where, args, err := filterToSQL([]map[string]string{
+		{`column`: `Name`, `op`: `like`, `value`: `%john%`},
+		{`column`: `amount`, `op`: `gte`, `value`: `100`},
+		{`column`: `id`, `op`: `in`, `value`: `1, 2,3`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if where != `"name" like ? and "amount" >= ? and "id" in (?)` {
+		t.Errorf(`wrong where %s`, where)
+	}
+	if len(args) != 3 || args[0] != `%john%` || args[1] != `100` || len(args[2].([]string)) != 3 {
+		t.Errorf(`wrong args %v`, args)
+	}
+	for _, item := range []map[string]string{
+		{`column`: `id;drop`, `op`: `=`, `value`: `1`},
+		{`column`: `id`, `op`: `or 1=1 --`, `value`: `1`},
+	} {
+		if _, _, err = filterToSQL([]map[string]string{item}); err == nil {
+			t.Errorf(`filter %v must be rejected`, item)
+		}
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	sort := parseOrder(`name desc, "id"`)
+	if len(sort) != 2 || sort[0].Column != `name` || sort[0].Direction != `desc` ||
+		sort[1].Column != `id` || sort[1].Direction != `asc` {
+		t.Errorf(`wrong sort %v`, sort)
+	}
+}
+
+func TestRender(t *testing.T) {
+	stub, err := LoadStub(`testdata/stub.json`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := Render(`Include(header)Now(DD.MM.YYYY HH:MI, -2 days)EcosysParam(money_digit)LangRes(hello,ru)
+	DBFind(members, src).Columns("name").WhereId(2)Table(src)
+	DBFind(pages, pgs).Columns("name,menu").Offset(1).Limit(1).Count(total)Table(pgs)GetVar(total)`,
+		map[string]string{`lang`: `ru`}, stub)
+	if err = CompareGolden(tree, `testdata/render.json`, false); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStubFind(t *testing.T) {
+	stub, err := LoadStub(`testdata/stub.json`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range []tplItem{
+		{`DBFind(pages, src).Columns("name").Where("menu='default_menu'")`,
+			`[{"tag":"text","text":"Where is not supported offline, use Filter instead"}]`},
+		{`DBFind(pages, src).Columns("name").Filter(menu, =, default_menu).Order("name desc")Table(src)`,
+			`[{"tag":"dbfind","attr":{"columns":["name","id"],"data":[["profile","3"],["default_page","1"]],"name":"pages","order":"name desc","source":"src","types":["text","text"]}},{"tag":"table","attr":{"pager":{"offset":0,"limit":25},"sort":[{"column":"name","direction":"desc"}],"source":"src"}}]`},
+		{`DBFind(pages, src).Columns("name").Filter(id, >=, 2).Filter(name, like, "%_page")`,
+			`[{"tag":"dbfind","attr":{"columns":["name","id"],"data":[["admin_page","2"]],"name":"pages","source":"src","types":["text","text"]}}]`},
+		{`DBFind(pages, src).Columns("id").Filter(id, in, "3,1").Order("id desc")`,
+			`[{"tag":"dbfind","attr":{"columns":["id"],"data":[["3"],["1"]],"name":"pages","order":"id desc","source":"src","types":["text"]}}]`},
+		{`DBFind(pages, src).Filter(id, or, 1)`,
+			`[{"tag":"text","text":"wrong filter operator or"}]`},
+	} {
+		if tree := Render(item.input, nil, stub); string(tree) != item.want {
+			t.Errorf("wrong json %s\r\n%s != \r\n%s", item.input, tree, item.want)
+		}
+	}
+}
+
+func TestComponent(t *testing.T) {
+	var timeout bool
+	input := `DefineComponent(Card, "Title,Body,Footer"){
+		Div(card){
+			Div(card-title, #Title#)
+			Div(card-body){#Body#}
+			If(#Footer#){ Div(card-footer, #Footer#) }
+		}
+	}
+	Card(Title: First){ Span(Hello) }
+	Card(Second, Em(text)).Footer{ Strong(#Title#) }
+	P(#Title#)`
+	vars := map[string]string{`_full`: `0`, `Title`: `global`}
+	want := `[{"tag":"div","attr":{"class":"card"},"children":[{"tag":"div","attr":{"class":"card-title"},"children":[{"tag":"text","text":"First"}]},{"tag":"div","attr":{"class":"card-body"},"children":[{"tag":"span","children":[{"tag":"text","text":"Hello"}]}]}]},{"tag":"div","attr":{"class":"card"},"children":[{"tag":"div","attr":{"class":"card-title"},"children":[{"tag":"text","text":"Second"}]},{"tag":"div","attr":{"class":"card-body"},"children":[{"tag":"em","children":[{"tag":"text","text":"text"}]}]},{"tag":"div","attr":{"class":"card-footer"},"children":[{"tag":"strong","children":[{"tag":"text","text":"global"}]}]}]},{"tag":"p","children":[{"tag":"text","text":"global"}]}]`
+	if templ := Template2JSON(input, &timeout, &vars); string(templ) != want {
+		t.Errorf("wrong json \r\n%s != \r\n%s", templ, want)
+	}
+	vars[`_full`] = `1`
+	want = `[{"tag":"definecomponent","attr":{"name":"Card","params":["Title","Body","Footer"]},"children":[{"tag":"div","attr":{"class":"card"},"children":[{"tag":"div","attr":{"class":"card-title"},"children":[{"tag":"text","text":"#Title#"}]},{"tag":"div","attr":{"class":"card-body"},"children":[{"tag":"text","text":"#Body#"}]},{"tag":"if","attr":{"condition":"#Footer#"},"children":[{"tag":"div","attr":{"class":"card-footer"},"children":[{"tag":"text","text":"#Footer#"}]}]}]}]},{"tag":"card","attr":{"title":"First"},"children":[{"tag":"span","children":[{"tag":"text","text":"Hello"}]}]},{"tag":"card","attr":{"title":"Second"},"children":[{"tag":"em","children":[{"tag":"text","text":"text"}]}],"tail":[{"tag":"footer","children":[{"tag":"strong","children":[{"tag":"text","text":"#Title#"}]}]}]},{"tag":"p","children":[{"tag":"text","text":"#Title#"}]}]`
+	if templ := Template2JSON(input, &timeout, &vars); string(templ) != want {
+		t.Errorf("wrong full json \r\n%s != \r\n%s", templ, want)
+	}
+	vars[`_full`] = `0`
+	for _, item := range []tplItem{
+		{`DefineComponent(Div, "Body"){Span(#Body#)}`,
+			`[{"tag":"text","text":"component Div conflicts with built-in function"}]`},
+		{`DefineComponent(Loop){Loop()}Loop()`,
+			`[{"tag":"text","text":"too deep nesting of component Loop"}]`},
+		{`DefineComponent(Caption, "Text"){Div(#Text#, ok)}SetVar(val, "a, b) c")Caption(#val#)Span(#Text#)`,
+			`[{"tag":"div","attr":{"class":"a, b) c"},"children":[{"tag":"text","text":"ok"}]},{"tag":"span","children":[{"tag":"text","text":"#Text#"}]}]`},
+	} {
+		if templ := Template2JSON(item.input, &timeout, &vars); string(templ) != item.want {
+			t.Errorf("wrong json \r\n%s != \r\n%s", templ, item.want)
+		}
+	}
+}
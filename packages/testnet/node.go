@@ -0,0 +1,243 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package testnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/client"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/faults"
+)
+
+// Node is the node of the test network
+type Node struct {
+	Index     int
+	Dir       string
+	TCPPort   int
+	HTTPPort  int
+	AdminPort int
+	DBName    string
+	KeyID     int64
+	// PrivateKey and NodePrivateKey are in hex
+	PrivateKey     string
+	PublicKey      []byte
+	NodePrivateKey string
+	NodePublicKey  []byte
+
+	net    *Network
+	cmd    *exec.Cmd
+	done   chan error
+	faults faults.State
+}
+
+// URL returns the address of HTTP server of the node
+func (node *Node) URL() string {
+	return fmt.Sprintf(`http://127.0.0.1:%d`, node.HTTPPort)
+}
+
+// TCPAddr returns the address of TCP server of the node which is in full_nodes
+func (node *Node) TCPAddr() string {
+	return fmt.Sprintf(`127.0.0.1:%d`, node.TCPPort)
+}
+
+// AdminURL returns the address of the admin API of the node
+func (node *Node) AdminURL() string {
+	return fmt.Sprintf(`http://127.0.0.1:%d`, node.AdminPort)
+}
+
+// Client returns the client of the node without the key, it can be used for the public requests
+func (node *Node) Client() *client.Client {
+	return client.New(node.URL(), nil)
+}
+
+func (node *Node) configPath() string {
+	return filepath.Join(node.Dir, consts.DefaultConfigFile)
+}
+
+func (node *Node) command(args ...string) (*exec.Cmd, error) {
+	logFile, err := os.OpenFile(filepath.Join(node.Dir, `node.log`), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(node.net.cfg.Binary, append([]string{
+		`-configPath`, node.configPath(),
+		`-firstBlockPath`, node.net.firstBlock,
+	}, args...)...)
+	cmd.Dir = node.Dir
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	cmd.Env = append(append(os.Environ(), `GENESIS_ADMIN_FAULTS=true`, `GENESIS_ADMIN_HOST=127.0.0.1`,
+		`GENESIS_ADMIN_PORT=`+strconv.Itoa(node.AdminPort)), node.net.cfg.Env...)
+	return cmd, nil
+}
+
+// init writes the config and creates the tables of the node
+func (node *Node) init(args []string) error {
+	db := node.net.cfg.DB
+	cmd, err := node.command(append([]string{
+		`-workDir`, node.Dir,
+		`-privateDir`, node.Dir,
+		`-tcpHost`, `127.0.0.1`,
+		`-tcpPort`, strconv.Itoa(node.TCPPort),
+		`-httpHost`, `127.0.0.1`,
+		`-httpPort`, strconv.Itoa(node.HTTPPort),
+		`-dbHost`, db.Host,
+		`-dbPort`, strconv.Itoa(db.Port),
+		`-dbUser`, db.User,
+		`-dbPassword`, db.Password,
+		`-dbName`, node.DBName,
+		`-logLevel`, node.net.cfg.LogLevel,
+	}, args...)...)
+	if err != nil {
+		return err
+	}
+	defer cmd.Stdout.(*os.File).Close()
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("initializing node %d: %v, see %s", node.Index, err, cmd.Stdout.(*os.File).Name())
+	}
+	return nil
+}
+
+// Running returns true if the process of the node is running
+func (node *Node) Running() bool {
+	return node.cmd != nil
+}
+
+// Start starts the node and waits until it loads the first block. The injected faults are restored
+func (node *Node) Start() error {
+	if node.Running() {
+		return nil
+	}
+	cmd, err := node.command()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		cmd.Stdout.(*os.File).Close()
+		return err
+	}
+	node.cmd, node.done = cmd, make(chan error, 1)
+	go func() {
+		node.done <- cmd.Wait()
+		cmd.Stdout.(*os.File).Close()
+	}()
+	if err = node.WaitHeight(1, startTimeout); err != nil {
+		node.Stop()
+		return fmt.Errorf("starting node %d: %v", node.Index, err)
+	}
+	if len(node.faults.BlockedHosts) > 0 || node.faults.ClockOffset != 0 {
+		return node.setFaults()
+	}
+	return nil
+}
+
+// Stop stops the node, the data of the node is kept and it can be started again
+func (node *Node) Stop() error {
+	if !node.Running() {
+		return nil
+	}
+	defer func() { node.cmd = nil }()
+	node.cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-node.done:
+		return nil
+	case <-time.After(stopTimeout):
+	}
+	if err := node.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	<-node.done
+	return nil
+}
+
+// MaxBlockID returns the last block of the node
+func (node *Node) MaxBlockID() (int64, error) {
+	return node.Client().MaxBlockID()
+}
+
+// Block returns the header of the block of the node
+func (node *Node) Block(id int64) (*client.BlockInfo, error) {
+	return node.Client().Block(id)
+}
+
+// WaitHeight waits until the node reaches the block
+func (node *Node) WaitHeight(blockID int64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	var (
+		last int64
+		err  error
+	)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-node.done:
+			node.done <- err
+			return fmt.Errorf("node %d has exited: %v", node.Index, err)
+		default:
+		}
+		if last, err = node.MaxBlockID(); err == nil && last >= blockID {
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+	if err != nil {
+		return fmt.Errorf("node %d hasn't reached block %d: %v", node.Index, blockID, err)
+	}
+	return fmt.Errorf("node %d hasn't reached block %d, last block %d", node.Index, blockID, last)
+}
+
+// SetClockSkew shifts the clock of the node
+func (node *Node) SetClockSkew(skew time.Duration) error {
+	node.faults.ClockOffset = int64(skew / time.Second)
+	return node.setFaults()
+}
+
+// setFaults sends the faults to the admin API of the running node
+func (node *Node) setFaults() error {
+	if !node.Running() {
+		return nil
+	}
+	data, err := json.Marshal(node.faults)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(node.AdminURL()+`/admin/faults`, `application/json`, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("setting faults of node %d: %d %s", node.Index, resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package testnet starts the network of several nodes for the integration tests of forks, rollbacks
+// and dissemination. The node keeps its state in the global variables, so each node is the subprocess
+// of go-genesis binary with its own directory, keys, ports and temporary database. The first block
+// lists all nodes in full_nodes
+package testnet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/client"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+)
+
+const (
+	// BinaryEnv is the environment variable with the path of go-genesis binary
+	BinaryEnv = `GENESIS_TESTNET_BINARY`
+
+	startTimeout = time.Minute
+	stopTimeout  = 15 * time.Second
+	pollInterval = 200 * time.Millisecond
+)
+
+// Config is the parameters of the test network
+type Config struct {
+	// Nodes is the count of nodes
+	Nodes int
+	// Binary is the path of go-genesis binary
+	Binary string
+	// DB is the connection to PostgreSQL which is used to create the databases of nodes
+	DB conf.DBConfig
+	// Dir is the directory of nodes, the temporary directory is created and removed if it is empty
+	Dir string
+	// LogLevel is the log level of nodes
+	LogLevel string
+	// Env is the additional environment of nodes, e.g. GENESIS_* overrides of the config
+	Env []string
+	// TxTimeout is the time of waiting for the transaction
+	TxTimeout time.Duration
+}
+
+// ConfigFromEnv returns the config with the binary from GENESIS_TESTNET_BINARY and the database
+// from PGHOST, PGPORT, PGUSER, PGPASSWORD and PGDATABASE. It returns false if the binary is not set
+func ConfigFromEnv(nodes int) (Config, bool) {
+	cfg := Config{
+		Nodes:  nodes,
+		Binary: os.Getenv(BinaryEnv),
+		DB: conf.DBConfig{
+			Host:     `localhost`,
+			Port:     5432,
+			User:     os.Getenv(`PGUSER`),
+			Password: os.Getenv(`PGPASSWORD`),
+			Name:     `postgres`,
+		},
+	}
+	if host := os.Getenv(`PGHOST`); len(host) > 0 {
+		cfg.DB.Host = host
+	}
+	if port, err := strconv.Atoi(os.Getenv(`PGPORT`)); err == nil {
+		cfg.DB.Port = port
+	}
+	if name := os.Getenv(`PGDATABASE`); len(name) > 0 {
+		cfg.DB.Name = name
+	}
+	return cfg, len(cfg.Binary) > 0
+}
+
+// Network is the running test network
+type Network struct {
+	Nodes []*Node
+
+	cfg        Config
+	dir        string
+	removeDir  bool
+	firstBlock string
+	db         *model.DbTransaction
+}
+
+// Start creates the nodes, generates the first block and starts the nodes
+func Start(cfg Config) (n *Network, err error) {
+	if cfg.Nodes < 1 {
+		return nil, fmt.Errorf("count of nodes must be positive")
+	}
+	if len(cfg.LogLevel) == 0 {
+		cfg.LogLevel = `ERROR`
+	}
+	if cfg.TxTimeout == 0 {
+		cfg.TxTimeout = time.Minute
+	}
+	n = &Network{cfg: cfg, dir: cfg.Dir}
+	if len(n.dir) == 0 {
+		if n.dir, err = ioutil.TempDir(``, `testnet`); err != nil {
+			return nil, err
+		}
+		n.removeDir = true
+	}
+	defer func() {
+		if err != nil {
+			n.Stop()
+		}
+	}()
+	if n.db, err = model.OpenDB(cfg.DB); err != nil {
+		return nil, err
+	}
+	n.firstBlock = filepath.Join(n.dir, consts.FirstBlockFilename)
+
+	suffix := make([]byte, 4)
+	if _, err = rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	var fullNodes [][]string
+	for i := 0; i < cfg.Nodes; i++ {
+		node, err := n.newNode(i, fmt.Sprintf(`testnet_%x_%d`, suffix, i))
+		if err != nil {
+			return nil, err
+		}
+		n.Nodes = append(n.Nodes, node)
+		fullNodes = append(fullNodes, []string{node.TCPAddr(), strconv.FormatInt(node.KeyID, 10),
+			hex.EncodeToString(node.NodePublicKey)})
+	}
+	nodes, err := json.Marshal(fullNodes)
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range n.Nodes {
+		args := []string{`-initConfig`, `-initDatabase`, `-noStart`}
+		if i == 0 {
+			args = append(args, `-generateFirstBlock`,
+				`-firstBlockPublicKey`, hex.EncodeToString(node.PublicKey),
+				`-firstBlockNodePublicKey`, hex.EncodeToString(node.NodePublicKey),
+				`-firstBlockHost`, node.TCPAddr(),
+				`-firstBlockNodes`, string(nodes))
+		}
+		if err = node.init(args); err != nil {
+			return nil, err
+		}
+	}
+	for _, node := range n.Nodes {
+		if err = node.Start(); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// Stop stops the nodes and removes their databases
+func (n *Network) Stop() error {
+	var result error
+	for _, node := range n.Nodes {
+		if err := node.Stop(); err != nil && result == nil {
+			result = err
+		}
+		if n.db != nil {
+			err := model.GetDB(n.db).Exec(`DROP DATABASE IF EXISTS "` + node.DBName + `"`).Error
+			if err != nil && result == nil {
+				result = err
+			}
+		}
+	}
+	if n.db != nil {
+		n.db.Close()
+	}
+	if n.removeDir {
+		os.RemoveAll(n.dir)
+	}
+	return result
+}
+
+// Founder returns the client of the node with the key of the founder of the first ecosystem
+func (n *Network) Founder(node int) *client.Client {
+	return client.New(n.Nodes[node].URL(), &client.KeySigner{PrivateKey: n.Nodes[0].PrivateKey})
+}
+
+// SubmitTx calls the contract on the node by the founder and waits for the result
+func (n *Network) SubmitTx(node int, contract string, params url.Values) (*client.TxStatus, error) {
+	return n.Founder(node).CallContract(contract, params, n.cfg.TxTimeout)
+}
+
+// WaitHeight waits until all running nodes reach the block
+func (n *Network) WaitHeight(blockID int64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for _, node := range n.Nodes {
+		if !node.Running() {
+			continue
+		}
+		if err := node.WaitHeight(blockID, time.Until(deadline)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Partition splits the network into the groups of node indexes. The nodes of different groups
+// can't connect to each other, the nodes which are not in the groups are isolated
+func (n *Network) Partition(groups ...[]int) error {
+	group := make(map[int]int)
+	for i, items := range groups {
+		for _, index := range items {
+			group[index] = i + 1
+		}
+	}
+	for i, node := range n.Nodes {
+		var blocked []string
+		for j, other := range n.Nodes {
+			if i != j && (group[i] == 0 || group[i] != group[j]) {
+				blocked = append(blocked, other.TCPAddr())
+			}
+		}
+		node.faults.BlockedHosts = blocked
+		if err := node.setFaults(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Heal removes the network partition
+func (n *Network) Heal() error {
+	for _, node := range n.Nodes {
+		node.faults.BlockedHosts = nil
+		if err := node.setFaults(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func writeKey(dir, name string, key []byte) error {
+	return ioutil.WriteFile(filepath.Join(dir, name), []byte(hex.EncodeToString(key)), 0600)
+}
+
+// newNode creates the directory, the keys and the database of the node
+func (n *Network) newNode(index int, dbName string) (*Node, error) {
+	node := &Node{Index: index, Dir: filepath.Join(n.dir, fmt.Sprintf(`node%d`, index)), DBName: dbName, net: n}
+	if err := os.MkdirAll(node.Dir, 0755); err != nil {
+		return nil, err
+	}
+	var err error
+	if node.TCPPort, err = freePort(); err != nil {
+		return nil, err
+	}
+	if node.HTTPPort, err = freePort(); err != nil {
+		return nil, err
+	}
+	if node.AdminPort, err = freePort(); err != nil {
+		return nil, err
+	}
+	priv, pub, err := crypto.GenBytesKeys()
+	if err != nil {
+		return nil, err
+	}
+	nodePriv, nodePub, err := crypto.GenBytesKeys()
+	if err != nil {
+		return nil, err
+	}
+	node.PrivateKey, node.PublicKey = hex.EncodeToString(priv), pub
+	node.NodePrivateKey, node.NodePublicKey = hex.EncodeToString(nodePriv), nodePub
+	node.KeyID = crypto.Address(pub)
+	for name, key := range map[string][]byte{
+		consts.PrivateKeyFilename:     priv,
+		consts.PublicKeyFilename:      pub,
+		consts.NodePrivateKeyFilename: nodePriv,
+		consts.NodePublicKeyFilename:  nodePub,
+	} {
+		if err = writeKey(node.Dir, name, key); err != nil {
+			return nil, err
+		}
+	}
+	err = model.GetDB(n.db).Exec(`CREATE DATABASE "` + dbName + `"`).Error
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -0,0 +1,105 @@
+// MIT License
+//
+// Copyright (c) 2016-2018 GenesisKernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package testnet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+const waitTimeout = 2 * time.Minute
+
+// startNetwork starts the network or skips the test if GENESIS_TESTNET_BINARY is not set
+func startNetwork(t *testing.T, nodes int) *Network {
+	cfg, ok := ConfigFromEnv(nodes)
+	if !ok {
+		t.Skip(BinaryEnv + " is not set")
+	}
+	n, err := Start(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func sameBlock(t *testing.T, n *Network, blockID int64) {
+	first, err := n.Nodes[0].Block(blockID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range n.Nodes[1:] {
+		block, err := node.Block(blockID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first.Hash, block.Hash) {
+			t.Errorf("block %d of node %d differs: %x != %x", blockID, node.Index, block.Hash, first.Hash)
+		}
+	}
+}
+
+func TestPartitionHeal(t *testing.T) {
+	n := startNetwork(t, 3)
+	defer n.Stop()
+
+	if err := n.WaitHeight(3, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Partition([]int{0, 1}, []int{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Nodes[0].WaitHeight(6, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Heal(); err != nil {
+		t.Fatal(err)
+	}
+	last, err := n.Nodes[0].MaxBlockID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n.WaitHeight(last+2, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	sameBlock(t, n, last+1)
+}
+
+func TestClockSkew(t *testing.T) {
+	n := startNetwork(t, 2)
+	defer n.Stop()
+
+	if err := n.Nodes[1].SetClockSkew(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Nodes[0].WaitHeight(4, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Nodes[1].SetClockSkew(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.WaitHeight(6, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	sameBlock(t, n, 5)
+}
@@ -43,6 +43,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/faults"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -289,6 +290,10 @@ func TypeInt(txType string) int64 {
 
 // TCPConn connects to the address
 func TCPConn(Addr string) (net.Conn, error) {
+	if err := faults.CheckDial(Addr); err != nil {
+		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "address": Addr}).Debug("dialing tcp")
+		return nil, ErrInfo(err)
+	}
 	conn, err := net.DialTimeout("tcp", Addr, 10*time.Second)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "address": Addr}).Debug("dialing tcp")
@@ -0,0 +1,130 @@
+// Package alert sends the alerts of desync monitor through email, webhooks and Slack
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/tools/desync_monitor/config"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const sendTimeout = 30 * time.Second
+
+var client = &http.Client{Timeout: sendTimeout}
+
+// Sender is the channel of alerts
+type Sender interface {
+	Name() string
+	Send(subject, message string) error
+}
+
+// Senders returns the channels which are set in the config
+func Senders(conf *config.Config) []Sender {
+	var senders []Sender
+	if len(conf.Smtp.Host) > 0 {
+		senders = append(senders, &Email{Smtp: conf.Smtp, Message: conf.AlertMessage})
+	}
+	if len(conf.Webhook.URL) > 0 {
+		senders = append(senders, &Webhook{URL: conf.Webhook.URL})
+	}
+	if len(conf.Slack.URL) > 0 {
+		senders = append(senders, &Slack{URL: conf.Slack.URL, Channel: conf.Slack.Channel})
+	}
+	return senders
+}
+
+// Email sends the alerts through SMTP
+type Email struct {
+	Smtp    config.Smtp
+	Message config.AlertMessage
+}
+
+func (e *Email) Name() string {
+	return "email"
+}
+
+func (e *Email) Send(subject, message string) error {
+	auth := smtp.PlainAuth("", e.Smtp.Username, e.Smtp.Password, e.Smtp.Host)
+	to := []string{e.Message.To}
+	msg := []byte(fmt.Sprintf("From: %s\r\n", e.Message.From) +
+		fmt.Sprintf("To: %s\r\n", e.Message.To) +
+		fmt.Sprintf("Subject: %s\r\n", subject) +
+		"\r\n" +
+		fmt.Sprintf("%s\r\n", message))
+	return smtp.SendMail(fmt.Sprintf("%s:%d", e.Smtp.Host, e.Smtp.Port), auth, e.Message.From, to, msg)
+}
+
+// WebhookPayload is the body of the webhook request
+type WebhookPayload struct {
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+	Time    int64  `json:"time"`
+}
+
+// Webhook posts the alerts as json
+type Webhook struct {
+	URL string
+}
+
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+func (w *Webhook) Send(subject, message string) error {
+	return postJSON(w.URL, &WebhookPayload{Subject: subject, Message: message, Time: time.Now().Unix()})
+}
+
+// SlackPayload is the message of Slack incoming webhook
+type SlackPayload struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+// Slack posts the alerts to Slack compatible incoming webhook
+type Slack struct {
+	URL     string
+	Channel string
+}
+
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+func (s *Slack) Send(subject, message string) error {
+	return postJSON(s.URL, &SlackPayload{Channel: s.Channel, Text: fmt.Sprintf("*%s*\n%s", subject, message)})
+}
+
+func postJSON(url string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status code is not OK %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SendAll sends the alert to all channels, onResult is called with the result of each channel
+func SendAll(senders []Sender, subject, message string, onResult func(sender Sender, err error)) {
+	for _, sender := range senders {
+		err := sender.Send(subject, message)
+		if err != nil {
+			log.WithFields(log.Fields{"channel": sender.Name(), "error": err}).Error("sending alert")
+		}
+		if onResult != nil {
+			onResult(sender, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// hashGroup is the nodes which have the same value
+type hashGroup struct {
+	Hash  string
+	Nodes []string
+}
+
+// divergence is the result of the comparing of the values of nodes
+type divergence struct {
+	Check    string
+	Majority *hashGroup
+	// Minority are the groups except the majority. If there is no majority then all groups are here
+	Minority []hashGroup
+}
+
+// groupNodes groups the nodes by the values, the largest group is the first one
+func groupNodes(hashes map[string]string) []hashGroup {
+	byHash := map[string][]string{}
+	for node, hash := range hashes {
+		byHash[hash] = append(byHash[hash], node)
+	}
+	groups := make([]hashGroup, 0, len(byHash))
+	for hash, nodes := range byHash {
+		sort.Strings(nodes)
+		groups = append(groups, hashGroup{Hash: hash, Nodes: nodes})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Nodes) != len(groups[j].Nodes) {
+			return len(groups[i].Nodes) > len(groups[j].Nodes)
+		}
+		return groups[i].Hash < groups[j].Hash
+	})
+	return groups
+}
+
+// compareHashes returns nil if all nodes have the same value. The nodes which are not in the largest group
+// are in the minority. If several groups are the largest then the majority is unknown
+func compareHashes(check string, hashes map[string]string) *divergence {
+	groups := groupNodes(hashes)
+	if len(groups) < 2 {
+		return nil
+	}
+	div := &divergence{Check: check, Minority: groups}
+	if len(groups[0].Nodes) > len(groups[1].Nodes) {
+		div.Majority = &groups[0]
+		div.Minority = groups[1:]
+	}
+	return div
+}
+
+// minorityNodes returns the nodes which are in the minority
+func (d *divergence) minorityNodes() []string {
+	var nodes []string
+	for _, group := range d.Minority {
+		nodes = append(nodes, group.Nodes...)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+func (d *divergence) String() string {
+	var groups []string
+	for _, group := range d.Minority {
+		groups = append(groups, fmt.Sprintf("%s: %s", group.Hash, strings.Join(group.Nodes, ",")))
+	}
+	if d.Majority == nil {
+		return fmt.Sprintf("%s differs, there is no majority. %s", d.Check, strings.Join(groups, "; "))
+	}
+	return fmt.Sprintf("%s differs, nodes %s are in the minority (%s), majority %s: %s", d.Check,
+		strings.Join(d.minorityNodes(), ","), strings.Join(groups, "; "), d.Majority.Hash,
+		strings.Join(d.Majority.Nodes, ","))
+}
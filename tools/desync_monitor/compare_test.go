@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareHashes(t *testing.T) {
+	if div := compareHashes("block", map[string]string{"a": "1", "b": "1"}); div != nil {
+		t.Errorf("equal hashes must not diverge %v", div)
+	}
+	div := compareHashes("block", map[string]string{"a": "1", "b": "2", "c": "1", "d": "3"})
+	if div == nil || div.Majority == nil || div.Majority.Hash != "1" {
+		t.Fatalf("wrong majority %v", div)
+	}
+	if nodes := div.minorityNodes(); !reflect.DeepEqual(nodes, []string{"b", "d"}) {
+		t.Errorf("wrong minority %v", nodes)
+	}
+	div = compareHashes("block", map[string]string{"a": "1", "b": "2"})
+	if div == nil || div.Majority != nil || len(div.minorityNodes()) != 2 {
+		t.Errorf("there must be no majority %v", div)
+	}
+}
+
+func TestAlertKey(t *testing.T) {
+	m := &monitor{}
+	hashes := map[string]string{"a": "1", "b": "2", "c": "1"}
+	first := m.compare("block_hash", "hash of block 5", hashes)
+	next := m.compare("block_hash", "hash of block 6", hashes)
+	if alertKey(first) != alertKey(next) || first[0].Text == next[0].Text {
+		t.Errorf("the key must not depend on the block %v %v", first, next)
+	}
+	hashes["c"] = "3"
+	if alertKey(m.compare("block_hash", "hash of block 6", hashes)) == alertKey(next) {
+		t.Error("the key must depend on the minority nodes")
+	}
+}
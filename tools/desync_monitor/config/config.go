@@ -22,11 +22,35 @@ type Smtp struct {
 	Password string `toml:"password"`
 }
 
+// Webhook is the url which receives the alerts as json
+type Webhook struct {
+	URL string `toml:"url"`
+}
+
+// Slack is the incoming webhook of Slack or compatible messenger
+type Slack struct {
+	URL     string `toml:"url"`
+	Channel string `toml:"channel"`
+}
+
+// Tables are the ecosystem tables whose content is compared
+type Tables struct {
+	Ecosystem int64    `toml:"ecosystem"`
+	Names     []string `toml:"names"`
+	// Tokens are the service tokens of the nodes by the node urls, the table hashes require authorization
+	Tokens map[string]string `toml:"tokens"`
+}
+
 type Config struct {
 	Daemon       Daemon       `toml:"daemon"`
 	AlertMessage AlertMessage `toml:"alert_message"`
 	Smtp         Smtp         `toml:"smtp"`
+	Webhook      Webhook      `toml:"webhook"`
+	Slack        Slack        `toml:"slack"`
+	Tables       Tables       `toml:"tables"`
 	NodesList    []string     `toml:"nodes_list"`
+	// MetricsAddr is the address of Prometheus metrics, e.g. ":9100"
+	MetricsAddr string `toml:"metrics_addr"`
 }
 
 func (c *Config) Read(fileName string) error {
@@ -4,10 +4,13 @@ import (
 	"flag"
 	"fmt"
 	"math"
-	"net/smtp"
+	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/GenesisKernel/go-genesis/packages/metrics"
+	"github.com/GenesisKernel/go-genesis/tools/desync_monitor/alert"
 	"github.com/GenesisKernel/go-genesis/tools/desync_monitor/config"
 	"github.com/GenesisKernel/go-genesis/tools/desync_monitor/query"
 
@@ -25,6 +28,12 @@ const smtpHostFlagName = "smtpHost"
 const smtpPortFlagName = "smtpPort"
 const smtpUsernameFlagName = "smtpUsername"
 const smtpPasswordFlagName = "smtpPassword"
+const webhookURLFlagName = "webhookUrl"
+const slackURLFlagName = "slackUrl"
+const slackChannelFlagName = "slackChannel"
+const tablesFlagName = "tables"
+const tablesEcosystemFlagName = "tablesEcosystem"
+const metricsAddrFlagName = "metricsAddr"
 
 var configPath *string = flag.String(confPathFlagName, "config.toml", "path to desync monitor config")
 var nodesList *string = flag.String(nodesListFlagName, "127.0.0.1:7079", "which nodes to query, in format url1,url2,url3")
@@ -40,6 +49,33 @@ var smtpPort *int = flag.Int(smtpPortFlagName, 25, "port of smtp server")
 var smtpUsername *string = flag.String(smtpUsernameFlagName, "", "login to smtp server")
 var smtpPassword *string = flag.String(smtpPasswordFlagName, "", "password to smtp server")
 
+var webhookURL *string = flag.String(webhookURLFlagName, "", "url to post alerts as json")
+var slackURL *string = flag.String(slackURLFlagName, "", "url of Slack compatible incoming webhook")
+var slackChannel *string = flag.String(slackChannelFlagName, "", "channel of Slack alerts, the default channel of webhook is used if it is empty")
+
+var tables *string = flag.String(tablesFlagName, "", "ecosystem tables to compare, in format keys,contracts")
+var tablesEcosystem *int64 = flag.Int64(tablesEcosystemFlagName, 1, "ecosystem of compared tables")
+var metricsAddr *string = flag.String(metricsAddrFlagName, "", "address of Prometheus metrics, e.g. :9100, if started as daemon")
+
+var (
+	registry = metrics.NewRegistry()
+
+	nodeUp = registry.NewGauge("desync_monitor_node_up",
+		"Whether the node has answered the last query", "node")
+	nodeMaxBlockID = registry.NewGauge("desync_monitor_node_max_block_id",
+		"Last block of the node", "node")
+	commonBlockID = registry.NewGauge("desync_monitor_common_block_id",
+		"Block at which the nodes are compared")
+	nodeDiverged = registry.NewGauge("desync_monitor_node_diverged",
+		"Whether the node is in the minority by the check", "node", "check")
+	checkDiverged = registry.NewGauge("desync_monitor_diverged",
+		"Whether the nodes have different results of the check", "check")
+	checksTotal = registry.NewCounter("desync_monitor_checks_total",
+		"Number of monitoring rounds")
+	alertsTotal = registry.NewCounter("desync_monitor_alerts_total",
+		"Number of sent alerts", "channel", "result")
+)
+
 func minElement(slice []int64) int64 {
 	var min int64 = math.MaxInt64
 	for _, blockID := range slice {
@@ -74,51 +110,173 @@ func flagsOverrideConfig(conf *config.Config) {
 			conf.Smtp.Username = *smtpUsername
 		case smtpPasswordFlagName:
 			conf.Smtp.Password = *smtpPassword
+		case webhookURLFlagName:
+			conf.Webhook.URL = *webhookURL
+		case slackURLFlagName:
+			conf.Slack.URL = *slackURL
+		case slackChannelFlagName:
+			conf.Slack.Channel = *slackChannel
+		case tablesFlagName:
+			conf.Tables.Names = strings.Split(*tables, ",")
+		case tablesEcosystemFlagName:
+			conf.Tables.Ecosystem = *tablesEcosystem
+		case metricsAddrFlagName:
+			conf.MetricsAddr = *metricsAddr
 		}
 	})
+	if conf.Tables.Ecosystem == 0 {
+		conf.Tables.Ecosystem = 1
+	}
 }
 
-func sendEmail(smtpConf *config.Smtp, alertConf *config.AlertMessage, message string) error {
-	auth := smtp.PlainAuth("", smtpConf.Username, smtpConf.Password, smtpConf.Host)
-	to := []string{alertConf.To}
-	msg := []byte(fmt.Sprintf("From: %s\r\n", alertConf.From) +
-		fmt.Sprintf("To: %s\r\n", alertConf.To) +
-		fmt.Sprintf("Subject: %s\r\n", alertConf.Subject) +
-		"\r\n" +
-		fmt.Sprintf("%s\r\n", message))
-	err := smtp.SendMail(fmt.Sprintf("%s:%d", smtpConf.Host, smtpConf.Port), auth, alertConf.From, to, msg)
-	if err != nil {
-		log.WithFields(log.Fields{"error": err}).Error("sending email")
-	}
-	return err
+// problem is the found problem of the nodes
+type problem struct {
+	// Key identifies the problem without the block height, e.g. the check and the minority nodes
+	Key  string
+	Text string
 }
 
-func monitor(conf *config.Config) {
-	maxBlockIDs, err := query.MaxBlockIDs(conf.NodesList)
-	if err != nil {
-		sendEmail(&conf.Smtp, &conf.AlertMessage, "problem getting node max block id :"+err.Error())
-		return
+type monitor struct {
+	conf    *config.Config
+	senders []alert.Sender
+	// lastAlert are the keys of the problems of the last alert, the same problems are not sent again
+	lastAlert string
+}
+
+func (m *monitor) alert(message string) {
+	subject := m.conf.AlertMessage.Subject
+	if len(message) == 0 {
+		subject += ": resolved"
+		message = "nodes are synchronized"
 	}
-	blockInfos, err := query.BlockInfo(conf.NodesList, minElement(maxBlockIDs))
-	if err != nil {
-		sendEmail(&conf.Smtp, &conf.AlertMessage, "problem getting node block info :"+err.Error())
-		return
+	alert.SendAll(m.senders, subject, message, func(sender alert.Sender, err error) {
+		result := "ok"
+		if err != nil {
+			result = "error"
+		}
+		alertsTotal.With(sender.Name(), result).Inc()
+	})
+}
+
+// setDiverged updates the metrics of the check
+func (m *monitor) setDiverged(check string, nodes []string, div *divergence) {
+	minority := map[string]bool{}
+	if div != nil {
+		for _, node := range div.minorityNodes() {
+			minority[node] = true
+		}
+		checkDiverged.With(check).Set(1)
+	} else {
+		checkDiverged.With(check).Set(0)
 	}
-	hash2Node := map[string][]string{}
-	for node, blockInfo := range blockInfos {
-		rollbacksHash := string(blockInfo.RollbacksHash)
-		if _, ok := hash2Node[rollbacksHash]; !ok {
-			hash2Node[rollbacksHash] = []string{}
+	for _, node := range nodes {
+		if minority[node] {
+			nodeDiverged.With(node, check).Set(1)
+		} else {
+			nodeDiverged.With(node, check).Set(0)
 		}
-		hash2Node[rollbacksHash] = append(hash2Node[rollbacksHash], node)
 	}
-	if len(hash2Node) > 1 {
-		hash2NodeStrResults := []string{}
-		for k, v := range hash2Node {
-			hash2NodeStrResults = append(hash2NodeStrResults, fmt.Sprintf("%x: %s", k, v))
+}
+
+// compare compares the values of the nodes and returns the divergence
+func (m *monitor) compare(check, title string, hashes map[string]string) []problem {
+	nodes := make([]string, 0, len(hashes))
+	for node := range hashes {
+		nodes = append(nodes, node)
+	}
+	div := compareHashes(title, hashes)
+	m.setDiverged(check, nodes, div)
+	if div == nil {
+		return nil
+	}
+	return []problem{{Key: check + " " + strings.Join(div.minorityNodes(), ","), Text: div.String()}}
+}
+
+func nodeErrors(check, title string, errs map[string]error) []problem {
+	var problems []problem
+	for node, err := range errs {
+		problems = append(problems, problem{Key: check + " error " + node,
+			Text: fmt.Sprintf("%s of node %s: %v", title, node, err)})
+	}
+	return problems
+}
+
+// check compares the block hashes and the content of tables at the common height.
+// It returns the list of found problems
+func (m *monitor) check() []problem {
+	checksTotal.With().Inc()
+	maxBlockIDs, errs := query.MaxBlockIDs(m.conf.NodesList)
+	problems := nodeErrors("max_block_id", "problem getting max block id", errs)
+	var (
+		nodes []string
+		ids   []int64
+	)
+	for _, node := range m.conf.NodesList {
+		if id, ok := maxBlockIDs[node]; ok {
+			nodeUp.With(node).Set(1)
+			nodeMaxBlockID.With(node).Set(float64(id))
+			nodes = append(nodes, node)
+			ids = append(ids, id)
+		} else {
+			nodeUp.With(node).Set(0)
+		}
+	}
+	if len(nodes) < 2 {
+		return append(problems, problem{Key: "nodes", Text: "not enough nodes to compare"})
+	}
+	blockID := minElement(ids)
+	commonBlockID.With().Set(float64(blockID))
+
+	blockInfos, errs := query.BlockInfo(nodes, blockID)
+	problems = append(problems, nodeErrors("block", fmt.Sprintf("problem getting block %d", blockID), errs)...)
+	hashes := map[string]string{}
+	rollbacksHashes := map[string]string{}
+	for node, blockInfo := range blockInfos {
+		hashes[node] = fmt.Sprintf("%x", blockInfo.Hash)
+		rollbacksHashes[node] = fmt.Sprintf("%x", blockInfo.RollbacksHash)
+	}
+	problems = append(problems, m.compare("block_hash", fmt.Sprintf("hash of block %d", blockID), hashes)...)
+	problems = append(problems, m.compare("rollbacks_hash", fmt.Sprintf("rollbacks hash of block %d", blockID),
+		rollbacksHashes)...)
+
+	for _, table := range m.conf.Tables.Names {
+		tableHashes, errs := query.TableHashes(nodes, m.conf.Tables.Tokens, table, m.conf.Tables.Ecosystem, blockID)
+		problems = append(problems, nodeErrors("table:"+table, fmt.Sprintf("problem getting hash of table %s", table),
+			errs)...)
+		hashes := map[string]string{}
+		for node, tableHash := range tableHashes {
+			hashes[node] = tableHash.Hash
 		}
-		sendEmail(&conf.Smtp, &conf.AlertMessage, fmt.Sprintf("nodes unsynced. Rollback hashes are: %s", strings.Join(hash2NodeStrResults, ",")))
+		problems = append(problems, m.compare("table:"+table,
+			fmt.Sprintf("content of table %d_%s at block %d", m.conf.Tables.Ecosystem, table, blockID), hashes)...)
 	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Key < problems[j].Key })
+	return problems
+}
+
+// alertKey returns the key of the problems, it doesn't depend on the block height
+// so the persistent divergence is not sent with every new block
+func alertKey(problems []problem) string {
+	keys := make([]string, len(problems))
+	for i, p := range problems {
+		keys[i] = p.Key
+	}
+	return strings.Join(keys, "\n")
+}
+
+// run checks the nodes and sends the alert if the problems have changed since the last alert
+func (m *monitor) run() {
+	problems := m.check()
+	key := alertKey(problems)
+	if key == m.lastAlert {
+		return
+	}
+	texts := make([]string, len(problems))
+	for i, p := range problems {
+		texts[i] = p.Text
+	}
+	m.alert(strings.Join(texts, "\n"))
+	m.lastAlert = key
 }
 
 func main() {
@@ -128,12 +286,25 @@ func main() {
 		log.WithFields(log.Fields{"error": err}).Fatal("reading config")
 	}
 	flagsOverrideConfig(conf)
+	m := &monitor{conf: conf, senders: alert.Senders(conf)}
+	if len(m.senders) == 0 {
+		log.Warn("alert channels are not configured")
+	}
 	if conf.Daemon.DaemonMode {
+		if len(conf.MetricsAddr) > 0 {
+			go func() {
+				mux := http.NewServeMux()
+				mux.Handle("/metrics", registry.Handler())
+				if err := http.ListenAndServe(conf.MetricsAddr, mux); err != nil {
+					log.WithFields(log.Fields{"error": err}).Fatal("serving metrics")
+				}
+			}()
+		}
 		ticker := time.NewTicker(time.Second * time.Duration(conf.Daemon.QueryingPeriod))
 		for _ = range ticker.C {
-			monitor(conf)
+			m.run()
 		}
 	} else {
-		monitor(conf)
+		m.run()
 	}
 }
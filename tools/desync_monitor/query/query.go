@@ -2,6 +2,7 @@ package query
 
 import (
 	"fmt"
+	"net/url"
 	"sync"
 
 	"github.com/GenesisKernel/go-genesis/packages/api"
@@ -9,63 +10,79 @@ import (
 
 const maxBlockIDEndpoint = "/api/v2/maxblockid"
 const blockInfoEndpoint = "/api/v2/block/%d"
+const tableHashEndpoint = "/api/v2/tablehash/%s?ecosystem=%d&block=%d"
 
 type MaxBlockID struct {
 	MaxBlockID int64 `json:"max_block_id"`
 }
 
-func MaxBlockIDs(nodesList []string) ([]int64, error) {
+// queryNodes calls request for each node concurrently and returns the results and the errors by nodes
+func queryNodes(nodesList []string, request func(url string) (interface{}, error)) (map[string]interface{}, map[string]error) {
 	wg := sync.WaitGroup{}
 	workResults := ConcurrentMap{m: map[string]interface{}{}}
 	for _, nodeUrl := range nodesList {
 		wg.Add(1)
 		go func(url string) {
 			defer wg.Done()
-			maxBlockID := &MaxBlockID{}
-			if err := sendGetRequest(url+maxBlockIDEndpoint, maxBlockID); err != nil {
+			result, err := request(url)
+			if err != nil {
 				workResults.Set(url, err)
 				return
 			}
-			workResults.Set(url, maxBlockID.MaxBlockID)
+			workResults.Set(url, result)
 		}(nodeUrl)
 	}
 	wg.Wait()
-	maxBlockIds := []int64{}
-	for _, result := range workResults.m {
-		switch res := result.(type) {
-		case int64:
-			maxBlockIds = append(maxBlockIds, res)
-		case error:
-			return nil, res
+	results := map[string]interface{}{}
+	errs := map[string]error{}
+	for nodeUrl, result := range workResults.m {
+		if err, ok := result.(error); ok {
+			errs[nodeUrl] = err
+		} else {
+			results[nodeUrl] = result
 		}
 	}
-	return maxBlockIds, nil
+	return results, errs
 }
 
-func BlockInfo(nodesList []string, blockID int64) (map[string]*api.GetBlockInfoResult, error) {
-	wg := sync.WaitGroup{}
-	workResults := ConcurrentMap{m: map[string]interface{}{}}
-	for _, nodeUrl := range nodesList {
-		wg.Add(1)
-		go func(url string) {
-			defer wg.Done()
-			blockInfo := &api.GetBlockInfoResult{}
-			if err := sendGetRequest(url+fmt.Sprintf(blockInfoEndpoint, blockID), blockInfo); err != nil {
-				workResults.Set(url, err)
-				return
-			}
-			workResults.Set(url, blockInfo)
-		}(nodeUrl)
+func MaxBlockIDs(nodesList []string) (map[string]int64, map[string]error) {
+	results, errs := queryNodes(nodesList, func(url string) (interface{}, error) {
+		maxBlockID := &MaxBlockID{}
+		err := sendGetRequest(url+maxBlockIDEndpoint, ``, maxBlockID)
+		return maxBlockID.MaxBlockID, err
+	})
+	maxBlockIds := map[string]int64{}
+	for nodeUrl, result := range results {
+		maxBlockIds[nodeUrl] = result.(int64)
 	}
-	wg.Wait()
-	result := map[string]*api.GetBlockInfoResult{}
-	for nodeUrl, blockInfoOrError := range workResults.m {
-		switch res := blockInfoOrError.(type) {
-		case error:
-			return nil, res
-		case *api.GetBlockInfoResult:
-			result[nodeUrl] = res
-		}
+	return maxBlockIds, errs
+}
+
+func BlockInfo(nodesList []string, blockID int64) (map[string]*api.GetBlockInfoResult, map[string]error) {
+	results, errs := queryNodes(nodesList, func(url string) (interface{}, error) {
+		blockInfo := &api.GetBlockInfoResult{}
+		err := sendGetRequest(url+fmt.Sprintf(blockInfoEndpoint, blockID), ``, blockInfo)
+		return blockInfo, err
+	})
+	blockInfos := map[string]*api.GetBlockInfoResult{}
+	for nodeUrl, result := range results {
+		blockInfos[nodeUrl] = result.(*api.GetBlockInfoResult)
+	}
+	return blockInfos, errs
+}
+
+// TableHashes returns the hashes of the content of the ecosystem table as of the block.
+// The requests are authorized by the service tokens of the nodes
+func TableHashes(nodesList []string, tokens map[string]string, table string, ecosystem, blockID int64) (map[string]*api.TableHashResult, map[string]error) {
+	results, errs := queryNodes(nodesList, func(nodeUrl string) (interface{}, error) {
+		tableHash := &api.TableHashResult{}
+		err := sendGetRequest(nodeUrl+fmt.Sprintf(tableHashEndpoint, url.PathEscape(table), ecosystem, blockID),
+			tokens[nodeUrl], tableHash)
+		return tableHash, err
+	})
+	tableHashes := map[string]*api.TableHashResult{}
+	for nodeUrl, result := range results {
+		tableHashes[nodeUrl] = result.(*api.TableHashResult)
 	}
-	return result, nil
+	return tableHashes, errs
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"io/ioutil"
 
@@ -29,8 +30,22 @@ func (c ConcurrentMap) Get(key string) (bool, interface{}) {
 	return ok, res
 }
 
-func sendGetRequest(url string, v interface{}) error {
-	resp, err := http.Get(url)
+// requestTimeout limits the requests to the hung nodes
+const requestTimeout = time.Minute
+
+var client = &http.Client{Timeout: requestTimeout}
+
+// sendGetRequest requests the url with the bearer token if it is not empty
+func sendGetRequest(url, token string, v interface{}) error {
+	req, err := http.NewRequest(`GET`, url, nil)
+	if err != nil {
+		log.WithFields(log.Fields{"url": url, "error": err}).Error("creating request")
+		return err
+	}
+	if len(token) > 0 {
+		req.Header.Set(`Authorization`, `Bearer `+token)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		log.WithFields(log.Fields{"url": url, "error": err}).Error("get requesting url")
 		return err
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/client"
+)
+
+// defaultConditions are the conditions of the new contracts, pages, menus and blocks
+const defaultConditions = `ContractConditions("MainCondition")`
+
+// defaultMenu is the menu of the new pages
+const defaultMenu = `default_menu`
+
+// sourceTables are the tables of sources by the type of source
+var sourceTables = map[string]string{
+	`contract`: `contracts`,
+	`page`:     `pages`,
+	`menu`:     `menu`,
+	`block`:    `blocks`,
+}
+
+func runCommand(c *client.Client, name string) error {
+	switch name {
+	case `login`:
+		if err := c.Login(); err != nil {
+			return err
+		}
+		return printJSON(c.Account())
+	case `call`:
+		args := opts.CallCommand.Args
+		params, err := contractParams(args.Params)
+		if err != nil {
+			return err
+		}
+		return callContract(c, args.Contract, params, !opts.CallCommand.NoWait)
+	case `pull`:
+		return pull(c, opts.PullCommand.Args)
+	case `push`:
+		return push(c, opts.PushCommand.Args)
+	case `row`:
+		var columns []string
+		if len(opts.RowCommand.Columns) > 0 {
+			columns = strings.Split(opts.RowCommand.Columns, `,`)
+		}
+		row, err := c.RowAsOf(opts.RowCommand.Args.Table, opts.RowCommand.Args.ID, opts.RowCommand.Block, columns...)
+		if err != nil {
+			return err
+		}
+		return printJSON(row)
+	case `list`:
+		cmd := opts.ListCommand
+		params := client.ListParams{Limit: cmd.Limit, Offset: cmd.Offset, Order: splitNames(cmd.Order),
+			Cursor: cmd.Cursor, Count: cmd.Count, Block: cmd.Block}
+		if len(cmd.Columns) > 0 {
+			params.Columns = strings.Split(cmd.Columns, `,`)
+		}
+		if len(cmd.Where) > 0 {
+			if err := json.Unmarshal([]byte(cmd.Where), &params.Where); err != nil {
+				return fmt.Errorf(`where must be JSON object: %s`, err)
+			}
+		}
+		list, err := c.List(opts.ListCommand.Args.Table, params)
+		if err != nil {
+			return err
+		}
+		return printJSON(list)
+	case `table`:
+		table, err := c.Table(opts.TableCommand.Args.Table)
+		if err != nil {
+			return err
+		}
+		return printJSON(table)
+	case `token-create`:
+		cmd := opts.TokenCreateCommand
+		scope := client.TokenScope{Access: cmd.Access, Contracts: splitNames(cmd.Contracts),
+			Tables: splitNames(cmd.Tables)}
+		token, err := c.CreateAPIToken(cmd.Args.Name, scope, time.Duration(cmd.Expire)*time.Second)
+		if err != nil {
+			return err
+		}
+		return printJSON(token)
+	case `tokens`:
+		tokens, err := c.APITokens()
+		if err != nil {
+			return err
+		}
+		return printJSON(tokens)
+	case `token-revoke`:
+		return c.RevokeAPIToken(opts.TokenRevokeCommand.Args.ID)
+	case `tables`:
+		tables, err := c.Tables(opts.TablesCommand.Limit, opts.TablesCommand.Offset)
+		if err != nil {
+			return err
+		}
+		return printJSON(tables)
+	case `block`:
+		id := opts.BlockCommand.Args.ID
+		if id == 0 {
+			var err error
+			if id, err = c.MaxBlockID(); err != nil {
+				return err
+			}
+		}
+		var block map[string]interface{}
+		if err := c.Get(`block/`+strconv.FormatInt(id, 10), nil, &block); err != nil {
+			return err
+		}
+		block[`id`] = id
+		return printJSON(block)
+	case `tx`:
+		status, err := c.TxStatus(opts.TxCommand.Args.Hash)
+		if err != nil {
+			return err
+		}
+		return printJSON(status)
+	case `watch`:
+		status, err := c.WaitTx(opts.WatchCommand.Args.Hash, timeout())
+		if status != nil {
+			printJSON(status)
+		}
+		return err
+	case `export`:
+		return export(c)
+	case `import`:
+		data, err := ioutil.ReadFile(opts.ImportCommand.Args.File)
+		if err != nil {
+			return err
+		}
+		return callContract(c, `Import`, url.Values{`Data`: {string(data)}}, true)
+	}
+	return fmt.Errorf(`unknown command %s`, name)
+}
+
+// contractParams converts name=value list to the parameters, @file value is read from the file
+func contractParams(list []string) (url.Values, error) {
+	params := url.Values{}
+	for _, item := range list {
+		pair := strings.SplitN(item, `=`, 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf(`wrong parameter %s, name=value is expected`, item)
+		}
+		value := pair[1]
+		if strings.HasPrefix(value, `@`) {
+			data, err := ioutil.ReadFile(value[1:])
+			if err != nil {
+				return nil, err
+			}
+			value = string(data)
+		}
+		params.Set(pair[0], value)
+	}
+	return params, nil
+}
+
+func callContract(c *client.Client, name string, params url.Values, wait bool) error {
+	if !wait {
+		result, err := c.SendContract(name, params)
+		if result != nil {
+			printJSON(result)
+		}
+		return err
+	}
+	status, err := c.CallContract(name, params, timeout())
+	if status != nil {
+		printJSON(status)
+	}
+	return err
+}
+
+// findSource returns the row of the source with the specified name or nil if it doesn't exist
+func findSource(c *client.Client, kind, name string) (map[string]string, error) {
+	table, ok := sourceTables[kind]
+	if !ok {
+		return nil, fmt.Errorf(`unknown type %s`, kind)
+	}
+	if kind == `contract` {
+		var info struct {
+			TableID string `json:"tableid"`
+		}
+		if err := c.Get(`contract/`+name, nil, &info); err != nil {
+			if apiErr, ok := err.(*client.Error); ok && apiErr.Code == `E_CONTRACT` {
+				return nil, nil
+			}
+			return nil, err
+		}
+		id, _ := strconv.ParseInt(info.TableID, 10, 64)
+		return c.Row(table, id)
+	}
+	var found map[string]string
+	err := listAll(c, table, nil, func(row map[string]string) bool {
+		if row[`name`] == name {
+			found = row
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// listAll calls handle for all rows of the table until it returns false
+func listAll(c *client.Client, table string, columns []string, handle func(map[string]string) bool) error {
+	const limit = 100
+	params := client.ListParams{Limit: limit, Columns: columns}
+	for {
+		list, err := c.List(table, params)
+		if err != nil {
+			return err
+		}
+		for _, row := range list.List {
+			if !handle(row) {
+				return nil
+			}
+		}
+		params.Offset += limit
+		if len(list.List) < limit || params.Offset >= list.Count {
+			return nil
+		}
+	}
+}
+
+func pull(c *client.Client, args SourceArgs) error {
+	row, err := findSource(c, args.Type, args.Name)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return fmt.Errorf(`%s %s has not been found`, args.Type, args.Name)
+	}
+	if len(args.File) == 0 {
+		fmt.Println(row[`value`])
+		return nil
+	}
+	return ioutil.WriteFile(args.File, []byte(row[`value`]), 0644)
+}
+
+func push(c *client.Client, args SourceArgs) error {
+	if len(args.File) == 0 {
+		return fmt.Errorf(`file is not specified`)
+	}
+	value, err := ioutil.ReadFile(args.File)
+	if err != nil {
+		return err
+	}
+	row, err := findSource(c, args.Type, args.Name)
+	if err != nil {
+		return err
+	}
+	cmd := opts.PushCommand
+	params := url.Values{`Value`: {string(value)}, `Conditions`: {cmd.Conditions}}
+	prefix := `New`
+	if row != nil {
+		prefix = `Edit`
+		params.Set(`Id`, row[`id`])
+		if len(cmd.Conditions) == 0 {
+			params.Set(`Conditions`, row[`conditions`])
+		}
+	} else {
+		if args.Type != `contract` {
+			params.Set(`Name`, args.Name)
+		}
+		if len(cmd.Conditions) == 0 {
+			params.Set(`Conditions`, defaultConditions)
+		}
+	}
+	switch args.Type {
+	case `page`:
+		params.Set(`Menu`, cmd.Menu)
+		if len(cmd.Menu) == 0 {
+			params.Set(`Menu`, defaultMenu)
+			if row != nil {
+				params.Set(`Menu`, row[`menu`])
+			}
+		}
+	case `menu`:
+		params.Set(`Title`, cmd.Title)
+		if row != nil && len(cmd.Title) == 0 {
+			params.Set(`Title`, row[`title`])
+		}
+	}
+	return callContract(c, prefix+strings.Title(args.Type), params, true)
+}
+
+// splitNames splits the comma separated list
+func splitNames(value string) (names []string) {
+	for _, name := range strings.Split(value, `,`) {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			names = append(names, name)
+		}
+	}
+	return
+}
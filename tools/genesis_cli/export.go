@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/client"
+)
+
+// exportSection describes the table which is exported as the list of parameters of the contract
+type exportSection struct {
+	table   string
+	columns []string // the columns of the table
+	params  []string // the parameters of the contract in the same order
+}
+
+// exportSections are the sections of the Import contract
+var exportSections = map[string]exportSection{
+	`pages`:      {`pages`, []string{`name`, `value`, `menu`, `conditions`}, []string{`Name`, `Value`, `Menu`, `Conditions`}},
+	`blocks`:     {`blocks`, []string{`name`, `value`, `conditions`}, []string{`Name`, `Value`, `Conditions`}},
+	`menus`:      {`menu`, []string{`name`, `value`, `title`, `conditions`}, []string{`Name`, `Value`, `Title`, `Conditions`}},
+	`parameters`: {`parameters`, []string{`name`, `value`, `conditions`}, []string{`Name`, `Value`, `Conditions`}},
+	`languages`:  {`languages`, []string{`name`, `res`}, []string{`Name`, `Trans`}},
+	`contracts`:  {`contracts`, []string{`value`, `conditions`}, []string{`Value`, `Conditions`}},
+}
+
+type exportColumn struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Conditions string `json:"conditions"`
+	Index      string `json:"index"`
+}
+
+type exportData struct {
+	Table   string     `json:"Table"`
+	Columns []string   `json:"Columns"`
+	Data    [][]string `json:"Data"`
+}
+
+func export(c *client.Client) error {
+	cmd := opts.ExportCommand
+	out := make(map[string]interface{})
+	for _, name := range strings.Split(cmd.Sections, `,`) {
+		name = strings.TrimSpace(name)
+		section, ok := exportSections[name]
+		if !ok {
+			return fmt.Errorf(`unknown section %s`, name)
+		}
+		list := make([]map[string]string, 0)
+		err := listAll(c, section.table, section.columns, func(row map[string]string) bool {
+			item := make(map[string]string)
+			for i, col := range section.columns {
+				item[section.params[i]] = row[col]
+			}
+			list = append(list, item)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		out[name] = list
+	}
+
+	if len(cmd.Tables) > 0 {
+		tables := make([]map[string]string, 0)
+		data := make([]exportData, 0)
+		for _, name := range strings.Split(cmd.Tables, `,`) {
+			table, err := exportTable(c, strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			tables = append(tables, table)
+			if cmd.Data {
+				rows, err := exportRows(c, strings.TrimSpace(name))
+				if err != nil {
+					return err
+				}
+				data = append(data, rows)
+			}
+		}
+		out[`tables`] = tables
+		if cmd.Data {
+			out[`data`] = data
+		}
+	}
+
+	result, err := json.MarshalIndent(out, ``, `  `)
+	if err != nil {
+		return err
+	}
+	if len(cmd.Args.File) == 0 {
+		fmt.Println(string(result))
+		return nil
+	}
+	return ioutil.WriteFile(cmd.Args.File, result, 0644)
+}
+
+// exportTable returns the parameters of NewTable contract for the table
+func exportTable(c *client.Client, name string) (map[string]string, error) {
+	table, err := c.Table(name)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]exportColumn, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		columns = append(columns, exportColumn{Name: col.Name, Type: col.Type, Conditions: col.Perm, Index: `0`})
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	permissions := map[string]string{`insert`: table.Insert, `update`: table.Update, `new_column`: table.NewColumn}
+	if len(table.Read) > 0 {
+		permissions[`read`] = table.Read
+	}
+	if len(table.Filter) > 0 {
+		permissions[`filter`] = table.Filter
+	}
+	colsJSON, err := json.Marshal(columns)
+	if err != nil {
+		return nil, err
+	}
+	permJSON, err := json.Marshal(permissions)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{`Name`: name, `Columns`: string(colsJSON), `Permissions`: string(permJSON)}, nil
+}
+
+// exportRows returns the rows of the table for ImportData
+func exportRows(c *client.Client, name string) (exportData, error) {
+	table, err := c.Table(name)
+	if err != nil {
+		return exportData{}, err
+	}
+	data := exportData{Table: name, Data: make([][]string, 0)}
+	for _, col := range table.Columns {
+		data.Columns = append(data.Columns, col.Name)
+	}
+	sort.Strings(data.Columns)
+	err = listAll(c, name, data.Columns, func(row map[string]string) bool {
+		values := make([]string, len(data.Columns))
+		for i, col := range data.Columns {
+			values[i] = row[col]
+		}
+		data.Data = append(data.Data, values)
+		return true
+	})
+	return data, err
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	flags "github.com/jessevdk/go-flags"
+
+	"github.com/GenesisKernel/go-genesis/packages/client"
+)
+
+type NodeOpt struct {
+	Node      string `long:"node" description:"node address" default:"http://127.0.0.1:7079"`
+	KeyPath   string `long:"key" description:"path to the file with private key in hex" default:"PrivateKey"`
+	Ecosystem int64  `long:"ecosystem" description:"ecosystem id" default:"1"`
+	VDE       bool   `long:"vde" description:"send requests to the virtual dedicated ecosystem"`
+	Timeout   int64  `long:"timeout" description:"timeout of waiting for transactions in seconds" default:"30"`
+	APIToken  string `long:"token" description:"scoped service token, it is used instead of the private key"`
+}
+
+type SourceArgs struct {
+	Type string `positional-arg-name:"type" description:"contract, page, menu or block" required:"true"`
+	Name string `positional-arg-name:"name" required:"true"`
+	File string `positional-arg-name:"file"`
+}
+
+var opts struct {
+	NodeOpt
+
+	LoginCommand struct{} `command:"login" description:"log in and show the account"`
+
+	CallCommand struct {
+		NoWait bool `long:"no-wait" description:"do not wait for the transaction to be added to the block"`
+		Args   struct {
+			Contract string   `positional-arg-name:"contract" required:"true"`
+			Params   []string `positional-arg-name:"name=value" description:"contract parameters, @file reads the value from the file"`
+		} `positional-args:"true"`
+	} `command:"call" description:"call the contract"`
+
+	PullCommand struct {
+		Args SourceArgs `positional-args:"true"`
+	} `command:"pull" description:"save the source of contract, page, menu or block to the file or stdout"`
+
+	PushCommand struct {
+		Conditions string     `long:"conditions" description:"conditions of the new source or new conditions of the existing one"`
+		Menu       string     `long:"menu" description:"menu of the page, default_menu for the new pages"`
+		Title      string     `long:"title" description:"title of the menu"`
+		Args       SourceArgs `positional-args:"true"`
+	} `command:"push" description:"create or update the contract, page, menu or block from the file"`
+
+	RowCommand struct {
+		Columns string `long:"columns" description:"comma separated list of columns"`
+		Block   int64  `long:"block" description:"show the row as of the block"`
+		Args    struct {
+			Table string `positional-arg-name:"table" required:"true"`
+			ID    int64  `positional-arg-name:"id" required:"true"`
+		} `positional-args:"true"`
+	} `command:"row" description:"show the row of the table"`
+
+	ListCommand struct {
+		Columns string `long:"columns" description:"comma separated list of columns"`
+		Limit   int64  `long:"limit" default:"25"`
+		Offset  int64  `long:"offset"`
+		Where   string `long:"where" description:"filter in JSON, e.g. {\"amount\": {\"gt\": 10}}"`
+		Order   string `long:"order" description:"comma separated list of sorted columns, -column is descending"`
+		Cursor  string `long:"cursor" description:"cursor of the next page"`
+		Count   string `long:"count" description:"exact, estimate or none"`
+		Block   int64  `long:"block" description:"show the rows as of the block"`
+		Args    struct {
+			Table string `positional-arg-name:"table" required:"true"`
+		} `positional-args:"true"`
+	} `command:"list" description:"show the rows of the table"`
+
+	TableCommand struct {
+		Args struct {
+			Table string `positional-arg-name:"table" required:"true"`
+		} `positional-args:"true"`
+	} `command:"table" description:"show the columns and permissions of the table"`
+
+	TablesCommand struct {
+		Limit  int64 `long:"limit" default:"100"`
+		Offset int64 `long:"offset"`
+	} `command:"tables" description:"show the tables of the ecosystem"`
+
+	BlockCommand struct {
+		Args struct {
+			ID int64 `positional-arg-name:"id" description:"block id, the last block if it is omitted"`
+		} `positional-args:"true"`
+	} `command:"block" description:"show the block"`
+
+	TxCommand struct {
+		Args struct {
+			Hash string `positional-arg-name:"hash" required:"true"`
+		} `positional-args:"true"`
+	} `command:"tx" description:"show the status of the transaction"`
+
+	WatchCommand struct {
+		Args struct {
+			Hash string `positional-arg-name:"hash" required:"true"`
+		} `positional-args:"true"`
+	} `command:"watch" description:"wait until the transaction is added to the block"`
+
+	ExportCommand struct {
+		Sections string `long:"sections" description:"comma separated list of sections" default:"pages,blocks,menus,languages,contracts"`
+		Tables   string `long:"tables" description:"comma separated list of tables to export"`
+		Data     bool   `long:"data" description:"export the rows of the tables"`
+		Args     struct {
+			File string `positional-arg-name:"file" description:"output file, stdout if it is omitted"`
+		} `positional-args:"true"`
+	} `command:"export" description:"export the ecosystem in the format of Import contract"`
+
+	TokenCreateCommand struct {
+		Access    string `long:"access" description:"read or contract" default:"read"`
+		Contracts string `long:"contracts" description:"comma separated list of allowed contracts"`
+		Tables    string `long:"tables" description:"comma separated list of allowed tables"`
+		Expire    int64  `long:"expire" description:"lifetime of the token in seconds, 0 is unlimited"`
+		Args      struct {
+			Name string `positional-arg-name:"name" required:"true"`
+		} `positional-args:"true"`
+	} `command:"token-create" description:"create the scoped service token"`
+
+	TokensCommand struct{} `command:"tokens" description:"show the service tokens of the account"`
+
+	TokenRevokeCommand struct {
+		Args struct {
+			ID int64 `positional-arg-name:"id" required:"true"`
+		} `positional-args:"true"`
+	} `command:"token-revoke" description:"revoke the service token"`
+
+	ImportCommand struct {
+		Args struct {
+			File string `positional-arg-name:"file" required:"true"`
+		} `positional-args:"true"`
+	} `command:"import" description:"import the file by Import contract"`
+}
+
+func newClient() (*client.Client, error) {
+	if len(opts.APIToken) > 0 {
+		c := client.NewWithToken(opts.Node, opts.APIToken)
+		c.VDE = opts.VDE
+		return c, nil
+	}
+	key, err := ioutil.ReadFile(opts.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+	privateKey := strings.TrimSpace(string(key))
+	if len(privateKey) > 64 {
+		privateKey = privateKey[:64]
+	}
+	c := client.New(opts.Node, &client.KeySigner{PrivateKey: privateKey})
+	c.Ecosystem = opts.Ecosystem
+	c.VDE = opts.VDE
+	return c, nil
+}
+
+func timeout() time.Duration {
+	return time.Duration(opts.Timeout) * time.Second
+}
+
+func printJSON(v interface{}) error {
+	out, err := json.MarshalIndent(v, ``, `  `)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func main() {
+	p := flags.NewParser(&opts, flags.Default)
+	if _, err := p.Parse(); err != nil {
+		os.Exit(1)
+	}
+
+	c, err := newClient()
+	if err == nil {
+		err = runCommand(c, p.Active.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while %s: %s\n", p.Active.Name, err.Error())
+		os.Exit(1)
+	}
+}